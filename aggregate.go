@@ -0,0 +1,300 @@
+package logflux
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// MetricAggregationOptions configures client-side metric aggregation (see
+// Options.MetricAggregation).
+type MetricAggregationOptions struct {
+	// Interval between flushes (default: 10s).
+	Interval time.Duration
+	// MaxSeries bounds the distinct name and attribute combinations held
+	// per interval (default: 1000). Values of further series are folded
+	// into one overflow series per metric name, marked with the attribute
+	// metric.overflow=true.
+	MaxSeries int
+}
+
+const (
+	defaultAggregationInterval = 10 * time.Second
+	defaultMaxSeries           = 1000
+	overflowAttribute          = "metric.overflow"
+)
+
+// metricAggregator accumulates metrics per series (name, kind, unit and
+// attribute set) and sends one payload per series every interval: counters
+// are summed, distributions and histograms are summarized with a quantile
+// sketch, histograms are also counted in buckets, and gauges, like any other
+// kind, keep the last value.
+type metricAggregator struct {
+	hub       *Hub
+	interval  time.Duration
+	maxSeries int
+	stop      chan struct{}
+	done      chan struct{}
+	stopOnce  sync.Once
+
+	mu       sync.Mutex
+	series   map[string]*metricSeries
+	overflow int // values folded into overflow series this interval
+}
+
+type metricSeries struct {
+	name, kind, unit string
+	attrs            Attributes
+	value            float64
+	sketch           *sketch            // distributions and histograms
+	hist             *payload.Histogram // histograms only
+}
+
+func newMetricAggregator(h *Hub, opts MetricAggregationOptions) *metricAggregator {
+	a := &metricAggregator{
+		hub:       h,
+		interval:  opts.Interval,
+		maxSeries: opts.MaxSeries,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+		series:    make(map[string]*metricSeries),
+	}
+	if a.interval <= 0 {
+		a.interval = defaultAggregationInterval
+	}
+	if a.maxSeries <= 0 {
+		a.maxSeries = defaultMaxSeries
+	}
+	return a
+}
+
+// start runs the flush loop until close.
+func (a *metricAggregator) start() {
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-a.stop:
+				return
+			case <-ticker.C:
+				a.flush()
+			}
+		}
+	}()
+}
+
+// close stops the loop and sends what is pending. It is safe on nil.
+func (a *metricAggregator) close() {
+	if a == nil {
+		return
+	}
+	a.stopOnce.Do(func() { close(a.stop) })
+	<-a.done
+	a.flush()
+}
+
+// record adds a value to its series.
+func (a *metricAggregator) record(name, kind, unit string, value float64, attrs Attributes) {
+	key := seriesKey(name, kind, unit, attrs)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := a.series[key]
+	if s == nil {
+		if len(a.series) >= a.maxSeries {
+			a.overflow++
+			attrs = Attributes{overflowAttribute: true}
+			key = seriesKey(name, kind, unit, attrs)
+			s = a.series[key]
+		}
+		if s == nil {
+			s = &metricSeries{name: name, kind: kind, unit: unit, attrs: attrs}
+			switch kind {
+			case "distribution":
+				s.sketch = newSketch()
+			case "histogram":
+				s.sketch = newSketch()
+				s.hist = payload.NewHistogramBuckets(a.hub.histogramBuckets(name))
+			}
+			a.series[key] = s
+		}
+	}
+	switch {
+	case s.sketch != nil:
+		if s.sketch.add(value) && s.hist != nil {
+			s.hist.Observe(value)
+		}
+	case kind == "counter":
+		s.value += value
+	default:
+		s.value = value
+	}
+}
+
+// flush sends every series and starts a new interval.
+func (a *metricAggregator) flush() {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	series, overflow := a.series, a.overflow
+	a.series, a.overflow = make(map[string]*metricSeries, len(series)), 0
+	a.mu.Unlock()
+
+	if overflow > 0 {
+		a.hub.logger().Warn("metric series limit reached; values folded into overflow series",
+			"limit", a.maxSeries, "values", overflow)
+	}
+	for _, s := range series {
+		p := payload.NewGauge("", s.name, s.value, s.unit)
+		p.Kind = s.kind
+		if s.sketch != nil {
+			if s.sketch.count == 0 {
+				continue // only NaN or infinite values
+			}
+			p.Summary = s.sketch.summary()
+			p.Value = p.Summary.Sum / float64(p.Summary.Count)
+			p.Histogram = s.hist
+		}
+		if len(s.attrs) > 0 {
+			p.SetAttrs(s.attrs)
+		}
+		if err := a.hub.sendMetric(p); err != nil {
+			a.hub.logger().Warn("sending aggregated metric failed", "metric", s.name, "error", err)
+		}
+	}
+}
+
+// seriesKey identifies a series. Attribute values are formatted with their
+// type so that 1 and "1" are different series.
+func seriesKey(name, kind, unit string, attrs Attributes) string {
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte(0)
+	b.WriteString(kind)
+	b.WriteByte(0)
+	b.WriteString(unit)
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\x00%s=%#v", k, attrs[k])
+	}
+	return b.String()
+}
+
+// sketchAccuracy is the relative error of sketch quantiles.
+const sketchAccuracy = 0.01
+
+var (
+	sketchGamma    = (1 + sketchAccuracy) / (1 - sketchAccuracy)
+	sketchLogGamma = math.Log(sketchGamma)
+)
+
+// sketch is a relative-error quantile sketch in the style of DDSketch:
+// values fall into logarithmic buckets, so every quantile is within
+// sketchAccuracy of a true value, with memory that grows only with the
+// logarithm of the value range.
+type sketch struct {
+	count    uint64
+	sum      float64
+	min, max float64
+	zeros    uint64
+	pos, neg map[int]uint64 // bucket index of |v| -> count
+}
+
+func newSketch() *sketch {
+	return &sketch{min: math.Inf(1), max: math.Inf(-1), pos: make(map[int]uint64), neg: make(map[int]uint64)}
+}
+
+// add records v and reports whether it was finite; NaN and infinite values
+// are dropped.
+func (s *sketch) add(v float64) bool {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return false
+	}
+	s.count++
+	s.sum += v
+	s.min = math.Min(s.min, v)
+	s.max = math.Max(s.max, v)
+	switch {
+	case v > 0:
+		s.pos[sketchIndex(v)]++
+	case v < 0:
+		s.neg[sketchIndex(-v)]++
+	default:
+		s.zeros++
+	}
+	return true
+}
+
+func sketchIndex(v float64) int { return int(math.Ceil(math.Log(v) / sketchLogGamma)) }
+
+// sketchValue is the representative value of bucket i.
+func sketchValue(i int) float64 { return 2 * math.Pow(sketchGamma, float64(i)) / (sketchGamma + 1) }
+
+// quantile returns an estimate of quantile q in [0, 1].
+func (s *sketch) quantile(q float64) float64 {
+	if s.count == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(s.count-1))
+	var seen uint64
+	result := s.max
+	found := false
+	visit := func(n uint64, v float64) {
+		if found {
+			return
+		}
+		seen += n
+		if seen > rank {
+			result, found = v, true
+		}
+	}
+	for _, i := range sortedKeys(s.neg, true) {
+		visit(s.neg[i], -sketchValue(i))
+	}
+	visit(s.zeros, 0)
+	for _, i := range sortedKeys(s.pos, false) {
+		visit(s.pos[i], sketchValue(i))
+	}
+	return math.Max(s.min, math.Min(s.max, result))
+}
+
+// sortedKeys returns the keys of m in ascending order, or descending if
+// desc (the order of increasing value for negative buckets).
+func sortedKeys(m map[int]uint64, desc bool) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	if desc {
+		sort.Sort(sort.Reverse(sort.IntSlice(keys)))
+	} else {
+		sort.Ints(keys)
+	}
+	return keys
+}
+
+func (s *sketch) summary() *payload.Summary {
+	return &payload.Summary{
+		Count: s.count,
+		Sum:   s.sum,
+		Min:   s.min,
+		Max:   s.max,
+		Quantiles: map[string]float64{
+			"p50": s.quantile(0.5),
+			"p90": s.quantile(0.9),
+			"p95": s.quantile(0.95),
+			"p99": s.quantile(0.99),
+		},
+	}
+}
@@ -0,0 +1,137 @@
+package logflux
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// metricRecorder collects metrics passed to BeforeSendMetric.
+type metricRecorder struct {
+	mu      sync.Mutex
+	metrics []*payload.Metric
+}
+
+func (r *metricRecorder) hook(m *payload.Metric) *payload.Metric {
+	r.mu.Lock()
+	r.metrics = append(r.metrics, m)
+	r.mu.Unlock()
+	return m
+}
+
+func (r *metricRecorder) byName() map[string][]*payload.Metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string][]*payload.Metric)
+	for _, m := range r.metrics {
+		out[m.Name] = append(out[m.Name], m)
+	}
+	return out
+}
+
+func TestHub_MetricAggregation(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.MetricAggregation = &MetricAggregationOptions{Interval: time.Hour}
+	rec := &metricRecorder{}
+	opts.BeforeSendMetric = rec.hook
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	for i := 0; i < 100; i++ {
+		_ = hub.Counter("requests", 1, Fields{"route": "/a"})
+	}
+	_ = hub.Counter("requests", 5, Fields{"route": "/b"})
+	_ = hub.Gauge("queue.depth", 3, nil)
+	_ = hub.Gauge("queue.depth", 7, nil)
+	for i := 1; i <= 100; i++ {
+		_ = hub.Metric("latency", float64(i), "distribution", nil)
+	}
+	if len(rec.byName()) != 0 {
+		t.Fatal("metrics were sent before the flush")
+	}
+
+	if err := hub.Flush(time.Second); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	got := rec.byName()
+	if len(got["requests"]) != 2 {
+		t.Fatalf("requests series = %d, want 2", len(got["requests"]))
+	}
+	for _, m := range got["requests"] {
+		want := map[string]float64{"/a": 100, "/b": 5}[m.Attributes["route"].(string)]
+		if m.Value != want || m.Kind != "counter" {
+			t.Errorf("requests %v = %v, want %v", m.Attributes, m.Value, want)
+		}
+	}
+	if g := got["queue.depth"]; len(g) != 1 || g[0].Value != 7 {
+		t.Errorf("gauge = %+v", g)
+	}
+	d := got["latency"]
+	if len(d) != 1 || d[0].Summary == nil {
+		t.Fatalf("distribution = %+v", d)
+	}
+	s := d[0].Summary
+	if s.Count != 100 || s.Sum != 5050 || s.Min != 1 || s.Max != 100 || d[0].Value != 50.5 {
+		t.Errorf("summary = %+v, value %v", s, d[0].Value)
+	}
+	if p99 := s.Quantiles["p99"]; math.Abs(p99-99)/99 > 0.02 {
+		t.Errorf("p99 = %v", p99)
+	}
+
+	// The next interval starts empty.
+	_ = hub.Flush(time.Second)
+	if n := len(rec.byName()["requests"]); n != 2 {
+		t.Errorf("empty interval sent %d more series", n-2)
+	}
+}
+
+func TestMetricAggregator_Overflow(t *testing.T) {
+	a := newMetricAggregator(&Hub{}, MetricAggregationOptions{MaxSeries: 2})
+	for _, id := range []string{"a", "b", "c", "d"} {
+		a.record("hits", "counter", "", 1, Attributes{"id": id})
+	}
+	if len(a.series) != 3 || a.overflow != 2 {
+		t.Fatalf("series = %d, overflow = %d", len(a.series), a.overflow)
+	}
+	s := a.series[seriesKey("hits", "counter", "", Attributes{overflowAttribute: true})]
+	if s == nil || s.value != 2 {
+		t.Errorf("overflow series = %+v", s)
+	}
+}
+
+func TestSketch_RelativeAccuracy(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	s := newSketch()
+	values := make([]float64, 10000)
+	for i := range values {
+		values[i] = math.Exp(r.NormFloat64() * 3) // spans many orders of magnitude
+		s.add(values[i])
+	}
+	sort.Float64s(values)
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		want := values[int(q*float64(len(values)-1))]
+		if got := s.quantile(q); math.Abs(got-want)/want > sketchAccuracy*1.01 {
+			t.Errorf("q%v = %v, want %v ±1%%", q, got, want)
+		}
+	}
+
+	n := newSketch()
+	for _, v := range []float64{-10, -1, 0, 1, 10} {
+		n.add(v)
+	}
+	if got := n.quantile(0); got != -10 {
+		t.Errorf("min quantile = %v", got)
+	}
+	if got := n.quantile(0.5); got != 0 {
+		t.Errorf("median = %v", got)
+	}
+}
@@ -0,0 +1,336 @@
+package logflux
+
+import (
+	"math"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// MetricSample is one series read from another metrics system. Counters and
+// histograms are cumulative, as those systems report them; the bridge turns
+// them into increases since its previous collection. A "gaugehistogram" is
+// the current distribution, which can go down, and is sent as-is.
+type MetricSample struct {
+	Name   string
+	Kind   string // "counter", "gauge", "histogram", "gaugehistogram" or "summary"
+	Unit   string
+	Labels map[string]string
+
+	// Value is the running total of a counter or the value of a gauge.
+	Value float64
+
+	// Count and Sum are the running totals of a histogram or summary.
+	Count uint64
+	Sum   float64
+
+	// Bounds are the ascending finite upper bounds of a histogram and
+	// Cumulative the number of values at or below each bound; values above
+	// the last bound are counted only in Count.
+	Bounds     []float64
+	Cumulative []uint64
+
+	// Quantiles of a summary, keyed by quantile in [0, 1].
+	Quantiles map[float64]float64
+}
+
+// MetricSource gathers the current samples of another metrics system. See
+// ExpvarSource, and the prometheus module for prometheus.Gatherer.
+type MetricSource interface {
+	Gather() ([]MetricSample, error)
+}
+
+// MetricSourceFunc adapts a function to MetricSource.
+type MetricSourceFunc func() ([]MetricSample, error)
+
+// Gather calls f.
+func (f MetricSourceFunc) Gather() ([]MetricSample, error) { return f() }
+
+// BridgeOptions configures a metric bridge (see StartBridge).
+type BridgeOptions struct {
+	// Interval between collections (default: 30s).
+	Interval time.Duration
+	// Allow limits the metrics sent to names matching these path.Match
+	// patterns, e.g. "http_*". Empty sends all.
+	Allow []string
+	// Deny drops metrics whose names match these patterns, after Allow.
+	Deny []string
+	// Prefix is prepended to every metric name, e.g. "prom.".
+	Prefix string
+	// Attributes renames labels to attribute keys; other labels keep their
+	// names.
+	Attributes map[string]string
+	// DropLabels lists labels that are not sent.
+	DropLabels []string
+}
+
+const defaultBridgeInterval = 30 * time.Second
+
+// Bridge periodically gathers samples from a MetricSource and sends them as
+// metrics through its hub. Counters and histograms carry the increase since
+// the previous collection; the first collection reports the running totals.
+// A total that goes down is taken as a reset of the source, and the new
+// total is reported as the increase. Bridged metrics are not sampled or
+// aggregated, but pass through BeforeSendMetric and the scrubber.
+type Bridge struct {
+	hub      *Hub
+	src      MetricSource
+	opts     BridgeOptions
+	drop     map[string]bool
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	// selfTelemetry marks the metrics as the SDK's own statistics (see
+	// startSelfTelemetry).
+	selfTelemetry bool
+
+	mu    sync.Mutex          // serializes collections
+	prev  map[string]float64  // last counter total per series
+	hists map[string]histPrev // last histogram and summary totals per series
+}
+
+type histPrev struct {
+	count   uint64
+	sum     float64
+	buckets []uint64 // per-bucket counts, including the overflow bucket
+}
+
+// StartBridge starts a bridge from src into the default hub.
+func StartBridge(src MetricSource, opts BridgeOptions) *Bridge {
+	return defaultHub.StartBridge(src, opts)
+}
+
+// StartBridge starts a bridge from src into h. Stop it before closing h.
+func (h *Hub) StartBridge(src MetricSource, opts BridgeOptions) *Bridge {
+	b := newBridge(h, src, opts)
+	go b.run()
+	return b
+}
+
+func newBridge(h *Hub, src MetricSource, opts BridgeOptions) *Bridge {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultBridgeInterval
+	}
+	b := &Bridge{
+		hub:   h,
+		src:   src,
+		opts:  opts,
+		drop:  make(map[string]bool, len(opts.DropLabels)),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+		prev:  make(map[string]float64),
+		hists: make(map[string]histPrev),
+	}
+	for _, l := range opts.DropLabels {
+		b.drop[l] = true
+	}
+	return b
+}
+
+func (b *Bridge) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			if err := b.Collect(); err != nil {
+				b.hub.logger().Warn("metric bridge collection failed", "error", err)
+			}
+		}
+	}
+}
+
+// Stop ends the collection loop and waits for it to exit. Further calls do
+// nothing.
+func (b *Bridge) Stop() {
+	b.stopOnce.Do(func() { close(b.stop) })
+	<-b.done
+}
+
+// close is Stop for bridges owned by a hub. It is safe on nil.
+func (b *Bridge) close() {
+	if b != nil {
+		b.Stop()
+	}
+}
+
+// Collect gathers and sends the source's samples now. Send failures are
+// logged to the diagnostic logger; only a failing Gather is returned.
+func (b *Bridge) Collect() error {
+	samples, err := b.src.Gather()
+	if err != nil {
+		return err
+	}
+	for _, m := range b.convert(samples) {
+		if err := b.hub.sendMetricEntry(m, b.selfTelemetry); err != nil {
+			b.hub.logger().Warn("sending bridged metric failed", "metric", m.Name, "error", err)
+		}
+	}
+	return nil
+}
+
+// convert filters samples and turns them into payloads, updating the
+// running totals.
+func (b *Bridge) convert(samples []MetricSample) []*payload.Metric {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]*payload.Metric, 0, len(samples))
+	for _, s := range samples {
+		if !b.allowed(s.Name) {
+			continue
+		}
+		attrs := b.attributes(s.Labels)
+		key := seriesKey(s.Name, s.Kind, s.Unit, attrs)
+		name := b.opts.Prefix + s.Name
+		var p *payload.Metric
+		switch s.Kind {
+		case "counter":
+			p = payload.NewCounter("", name, b.counterDelta(key, s.Value))
+			p.Unit = s.Unit
+		case "histogram", "gaugehistogram", "summary":
+			p = b.distribution(key, name, s)
+		default:
+			p = payload.NewGauge("", name, s.Value, s.Unit)
+		}
+		if p == nil {
+			continue
+		}
+		if len(attrs) > 0 {
+			p.SetAttrs(attrs)
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// allowed reports whether name passes the Allow and Deny patterns.
+func (b *Bridge) allowed(name string) bool {
+	match := func(patterns []string) bool {
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if len(b.opts.Allow) > 0 && !match(b.opts.Allow) {
+		return false
+	}
+	return !match(b.opts.Deny)
+}
+
+// attributes maps labels to attributes, dropping and renaming as
+// configured.
+func (b *Bridge) attributes(labels map[string]string) Attributes {
+	if len(labels) == 0 {
+		return nil
+	}
+	attrs := make(Attributes, len(labels))
+	for k, v := range labels {
+		if b.drop[k] {
+			continue
+		}
+		if renamed, ok := b.opts.Attributes[k]; ok {
+			k = renamed
+		}
+		attrs[k] = v
+	}
+	return attrs
+}
+
+// counterDelta returns the increase of a counter since the last collection.
+func (b *Bridge) counterDelta(key string, total float64) float64 {
+	prev, seen := b.prev[key]
+	b.prev[key] = total
+	if !seen || total < prev {
+		return total
+	}
+	return total - prev
+}
+
+// distribution converts a histogram, gauge histogram or summary sample.
+// Histograms carry per-bucket counts; min, max and quantiles are estimated
+// from the buckets. Summaries carry the source's quantiles over its own
+// window. It returns nil if nothing was recorded since the last collection,
+// or if a gauge histogram is empty.
+func (b *Bridge) distribution(key, name string, s MetricSample) *payload.Metric {
+	histogram := s.Kind == "histogram" || s.Kind == "gaugehistogram"
+	var buckets []uint64
+	if histogram {
+		buckets = make([]uint64, len(s.Bounds)+1)
+		var below uint64
+		for i, c := range s.Cumulative {
+			if i < len(s.Bounds) && c >= below {
+				buckets[i] = c - below
+				below = c
+			}
+		}
+		if s.Count >= below {
+			buckets[len(s.Bounds)] = s.Count - below
+		}
+	}
+
+	count, sum := s.Count, s.Sum
+	if s.Kind != "gaugehistogram" {
+		prev, seen := b.hists[key]
+		b.hists[key] = histPrev{count: s.Count, sum: s.Sum, buckets: append([]uint64(nil), buckets...)}
+		if seen && s.Count >= prev.count && len(prev.buckets) == len(buckets) {
+			count, sum = s.Count-prev.count, s.Sum-prev.sum
+			for i := range buckets {
+				if buckets[i] >= prev.buckets[i] {
+					buckets[i] -= prev.buckets[i]
+				} else {
+					buckets[i] = 0
+				}
+			}
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+
+	p := payload.NewGauge("", name, sum/float64(count), s.Unit)
+	p.Kind = s.Kind
+	summary := &payload.Summary{Count: count, Sum: sum, Quantiles: make(map[string]float64)}
+	if histogram {
+		p.Kind = "histogram"
+		bounds := append(append([]float64{math.Inf(-1)}, s.Bounds...), math.Inf(1))
+		summary.Min, _ = histQuantile(buckets, bounds, 0)
+		summary.Max, _ = histQuantile(buckets, bounds, 1)
+		for label, q := range map[string]float64{"p50": 0.5, "p90": 0.9, "p95": 0.95, "p99": 0.99} {
+			summary.Quantiles[label], _ = histQuantile(buckets, bounds, q)
+		}
+		p.Histogram = &payload.Histogram{Bounds: s.Bounds, Counts: buckets}
+	} else {
+		qs := make([]float64, 0, len(s.Quantiles))
+		for q := range s.Quantiles {
+			qs = append(qs, q)
+		}
+		sort.Float64s(qs)
+		for _, q := range qs {
+			v := s.Quantiles[q]
+			if math.IsNaN(v) {
+				continue
+			}
+			summary.Quantiles[payload.QuantileKey(q)] = v
+			switch q {
+			case 0:
+				summary.Min = v
+			case 1:
+				summary.Max = v
+			}
+		}
+	}
+	if len(summary.Quantiles) == 0 {
+		summary.Quantiles = nil
+	}
+	p.Summary = summary
+	return p
+}
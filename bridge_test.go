@@ -0,0 +1,121 @@
+package logflux
+
+import (
+	"expvar"
+	"reflect"
+	"testing"
+)
+
+func TestBridge_Convert(t *testing.T) {
+	b := newBridge(&Hub{}, nil, BridgeOptions{
+		Deny:       []string{"go_*"},
+		Prefix:     "prom.",
+		Attributes: map[string]string{"code": "http.status"},
+		DropLabels: []string{"instance"},
+	})
+	labels := map[string]string{"code": "200", "instance": "a"}
+	samples := func(total float64, cum []uint64, count uint64, sum float64) []MetricSample {
+		return []MetricSample{
+			{Name: "http_requests_total", Kind: "counter", Value: total, Labels: labels},
+			{Name: "go_goroutines", Kind: "gauge", Value: 5},
+			{Name: "temp", Kind: "gauge", Value: 21.5},
+			{Name: "latency", Kind: "histogram", Bounds: []float64{1, 10}, Cumulative: cum, Count: count, Sum: sum},
+		}
+	}
+	byName := func(ms []MetricSample) map[string]float64 {
+		out := make(map[string]float64)
+		for _, m := range b.convert(ms) {
+			out[m.Name] = m.Value
+		}
+		return out
+	}
+
+	first := b.convert(samples(10, []uint64{2, 3}, 4, 20))
+	if len(first) != 3 {
+		t.Fatalf("got %d metrics, want 3 (go_* denied)", len(first))
+	}
+	c := first[0]
+	if c.Name != "prom.http_requests_total" || c.Value != 10 || c.Kind != "counter" {
+		t.Errorf("counter = %+v", c)
+	}
+	if want := (Attributes{"http.status": "200"}); !reflect.DeepEqual(c.Attributes, want) {
+		t.Errorf("attributes = %v, want %v", c.Attributes, want)
+	}
+	h := first[2]
+	if !reflect.DeepEqual(h.Histogram.Counts, []uint64{2, 1, 1}) || h.Summary.Count != 4 || h.Value != 5 {
+		t.Errorf("histogram = %+v %+v", h.Histogram, h.Summary)
+	}
+
+	second := b.convert(samples(25, []uint64{2, 5}, 7, 38))
+	if second[0].Value != 15 {
+		t.Errorf("counter delta = %v, want 15", second[0].Value)
+	}
+	if h := second[2]; !reflect.DeepEqual(h.Histogram.Counts, []uint64{0, 2, 1}) || h.Summary.Sum != 18 {
+		t.Errorf("histogram delta = %+v %+v", h.Histogram, h.Summary)
+	}
+
+	// A reset reports the new total; an unchanged histogram is not sent.
+	got := byName(samples(3, []uint64{2, 5}, 7, 38))
+	if got["prom.http_requests_total"] != 3 {
+		t.Errorf("after reset = %v, want 3", got["prom.http_requests_total"])
+	}
+	if _, ok := got["prom.latency"]; ok {
+		t.Error("empty histogram interval was sent")
+	}
+}
+
+func TestBridge_Summary(t *testing.T) {
+	b := newBridge(&Hub{}, nil, BridgeOptions{})
+	m := b.convert([]MetricSample{{
+		Name: "rpc", Kind: "summary", Count: 10, Sum: 50,
+		Quantiles: map[float64]float64{0.5: 4, 0.99: 9, 0.999: 9.5},
+	}})[0]
+	want := map[string]float64{"p50": 4, "p99": 9, "p99.9": 9.5}
+	if m.Kind != "summary" || m.Value != 5 || !reflect.DeepEqual(m.Summary.Quantiles, want) {
+		t.Errorf("summary = %+v %+v", m, m.Summary)
+	}
+}
+
+func TestBridge_GaugeHistogram(t *testing.T) {
+	b := newBridge(&Hub{}, nil, BridgeOptions{})
+	sample := func(cum []uint64, count uint64, sum float64) []MetricSample {
+		return []MetricSample{{Name: "queue_depth", Kind: "gaugehistogram", Bounds: []float64{1, 10}, Cumulative: cum, Count: count, Sum: sum}}
+	}
+	first := b.convert(sample([]uint64{2, 5}, 6, 40))[0]
+	if first.Kind != "histogram" || !reflect.DeepEqual(first.Histogram.Counts, []uint64{2, 3, 1}) || first.Summary.Count != 6 {
+		t.Errorf("first = %+v %+v", first.Histogram, first.Summary)
+	}
+	// A smaller distribution is the current state, not a reset.
+	second := b.convert(sample([]uint64{1, 2}, 2, 6))[0]
+	if !reflect.DeepEqual(second.Histogram.Counts, []uint64{1, 1, 0}) || second.Summary.Count != 2 || second.Summary.Sum != 6 {
+		t.Errorf("second = %+v %+v", second.Histogram, second.Summary)
+	}
+}
+
+func TestExpvarSource(t *testing.T) {
+	expvar.NewInt("bridge_test.requests").Set(7)
+	m := expvar.NewMap("bridge_test.pool")
+	m.Add("idle", 2)
+	m.Set("name", new(expvar.String))
+
+	samples, err := ExpvarSource("bridge_test.requests").Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	got := make(map[string]MetricSample)
+	for _, s := range samples {
+		got[s.Name] = s
+	}
+	if s := got["bridge_test.requests"]; s.Kind != "counter" || s.Value != 7 {
+		t.Errorf("requests = %+v", s)
+	}
+	if s := got["bridge_test.pool.idle"]; s.Kind != "gauge" || s.Value != 2 {
+		t.Errorf("pool.idle = %+v", s)
+	}
+	if _, ok := got["bridge_test.pool.name"]; ok {
+		t.Error("string value was bridged")
+	}
+	if _, ok := got["memstats.HeapAlloc"]; !ok {
+		t.Error("memstats were not flattened")
+	}
+}
@@ -0,0 +1,129 @@
+package logflux
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/config"
+)
+
+// OptionsFromConfig maps a loaded config.Config to Options. This is the only
+// place file/env configuration is translated, so every option that can be
+// expressed as text is reachable from a config file or LOGFLUX_* variable.
+func OptionsFromConfig(cfg *config.Config) Options {
+	seconds := func(n int) time.Duration { return time.Duration(n) * time.Second }
+	// Levels were checked by cfg.Validate when the config was loaded.
+	minLevel, _ := cfg.MinLogLevel()
+	levels, _ := cfg.LoggerLevels()
+	scrub, _ := cfg.ScrubOptions()
+	buckets, _ := cfg.HistogramBounds()
+	var runtimeMetrics *RuntimeMetricsOptions
+	if cfg.RuntimeMetrics || cfg.RuntimeMetricsInterval > 0 || len(cfg.RuntimeMetricsAllow) > 0 {
+		runtimeMetrics = &RuntimeMetricsOptions{
+			Interval: seconds(cfg.RuntimeMetricsInterval),
+			Allow:    cfg.RuntimeMetricsAllow,
+		}
+	}
+	var selfTelemetry *SelfTelemetryOptions
+	if cfg.SelfTelemetry || cfg.SelfTelemetryInterval > 0 {
+		selfTelemetry = &SelfTelemetryOptions{Interval: seconds(cfg.SelfTelemetryInterval)}
+	}
+	var aggregation *MetricAggregationOptions
+	if cfg.MetricAggregation || cfg.MetricAggregationInterval > 0 || cfg.MaxMetricSeries > 0 {
+		aggregation = &MetricAggregationOptions{
+			Interval:  seconds(cfg.MetricAggregationInterval),
+			MaxSeries: cfg.MaxMetricSeries,
+		}
+	}
+	return Options{
+		APIKey:            cfg.APIKey,
+		Node:              cfg.Node,
+		Source:            cfg.Source,
+		Environment:       cfg.Environment,
+		Release:           cfg.Release,
+		LogGroup:          cfg.LogGroup,
+		CustomEndpointURL: cfg.CustomEndpointURL,
+
+		FallbackEndpointURLs:     cfg.FallbackEndpointURLs,
+		DiscoveryRefreshInterval: seconds(cfg.DiscoveryRefreshInterval),
+		DiscoveryCache:           cfg.DiscoveryCache,
+		DiscoveryCacheDir:        cfg.DiscoveryCacheDir,
+		DiscoveryCacheTTL:        seconds(cfg.DiscoveryCacheTTL),
+		FailoverCooldown:         seconds(cfg.FailoverCooldown),
+
+		QueueSize:     cfg.QueueSize,
+		FlushInterval: seconds(cfg.FlushInterval),
+		BatchSize:     cfg.BatchSize,
+		WorkerCount:   cfg.WorkerCount,
+
+		MaxRetries:    cfg.MaxRetries,
+		InitialDelay:  time.Duration(cfg.InitialDelay) * time.Millisecond,
+		MaxDelay:      seconds(cfg.MaxDelay),
+		BackoffFactor: cfg.BackoffFactor,
+
+		HTTPTimeout:       seconds(cfg.HTTPTimeout),
+		Transport:         client.TransportOptionsFromConfig(cfg),
+		Failsafe:          cfg.FailsafeMode,
+		EnableCompression: cfg.EnableCompression,
+		Debug:             cfg.Debug,
+
+		MaxBreadcrumbs: cfg.MaxBreadcrumbs,
+		SampleRate:     cfg.SampleRate,
+		MinLevel:       minLevel,
+		Levels:         levels,
+		Scrub:          scrub,
+
+		DuplicateErrorLimit:  cfg.DuplicateErrorLimit,
+		DuplicateErrorWindow: seconds(cfg.DuplicateErrorWindow),
+
+		MaxStackFrames:    cfg.MaxStackFrames,
+		StackContextLines: cfg.StackContextLines,
+		InAppPrefixes:     cfg.InAppPrefixes,
+
+		RepanicOnRecover:    cfg.RepanicOnRecover,
+		RecoverFlushTimeout: seconds(cfg.RecoverFlushTimeout),
+
+		RuntimeMetrics:    runtimeMetrics,
+		MetricAggregation: aggregation,
+		SelfTelemetry:     selfTelemetry,
+		HistogramBuckets:  buckets,
+	}
+}
+
+// InitFromConfigFile initializes LogFlux from a YAML, JSON or TOML file.
+// Precedence is file < LOGFLUX_* env vars < code: every non-zero field in
+// overrides replaces the loaded value, so hooks and other code-only options
+// are passed here.
+//
+// Zero fields in overrides are taken as unset, so code cannot switch off a
+// bool (such as Debug or a Transport flag) or clear a value that the file
+// or env enabled. To do that, load the file with config.LoadConfigFile,
+// adjust OptionsFromConfig's result and pass it to Init.
+func InitFromConfigFile(path string, overrides Options) error {
+	cfg, err := config.LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	return Init(mergeOptions(OptionsFromConfig(cfg), overrides))
+}
+
+// mergeOptions returns base with every non-zero field of override applied.
+// Nested structs such as Transport are merged field by field.
+func mergeOptions(base, override Options) Options {
+	mergeFields(reflect.ValueOf(&base).Elem(), reflect.ValueOf(override))
+	return base
+}
+
+func mergeFields(dst, src reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		f := src.Field(i)
+		switch {
+		case f.IsZero():
+		case f.Kind() == reflect.Struct:
+			mergeFields(dst.Field(i), f)
+		default:
+			dst.Field(i).Set(f)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package logflux
+
+import (
+	"testing"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/config"
+)
+
+func TestOptionsFromConfig_Units(t *testing.T) {
+	opts := OptionsFromConfig(&config.Config{
+		APIKey:        "eu-lf_testkey123",
+		FlushInterval: 7,
+		InitialDelay:  250,
+		MaxDelay:      9,
+		HTTPTimeout:   11,
+		ProxyURL:      "http://proxy:3128",
+		SampleRate:    0.5,
+
+		DiscoveryCacheTTL: 60,
+		FailoverCooldown:  45,
+		MaxConnsPerHost:   4,
+		IdleConnTimeout:   15,
+		DisableHTTP2:      true,
+
+		DuplicateErrorWindow:   30,
+		RuntimeMetricsInterval: 15,
+
+		MetricAggregationInterval: 5,
+		SelfTelemetryInterval:     20,
+	})
+	if opts.FlushInterval != 7*time.Second || opts.InitialDelay != 250*time.Millisecond ||
+		opts.MaxDelay != 9*time.Second || opts.HTTPTimeout != 11*time.Second ||
+		opts.DuplicateErrorWindow != 30*time.Second {
+		t.Fatalf("unexpected durations: %+v", opts)
+	}
+	if opts.Transport.ProxyURL != "http://proxy:3128" || opts.SampleRate != 0.5 {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+	if opts.DiscoveryCacheTTL != time.Minute || opts.FailoverCooldown != 45*time.Second {
+		t.Fatalf("discovery cache ttl = %v, failover cooldown = %v", opts.DiscoveryCacheTTL, opts.FailoverCooldown)
+	}
+	if opts.Transport.MaxConnsPerHost != 4 || opts.Transport.IdleConnTimeout != 15*time.Second || !opts.Transport.DisableHTTP2 {
+		t.Fatalf("transport = %+v", opts.Transport)
+	}
+	if opts.RuntimeMetrics == nil || opts.RuntimeMetrics.Interval != 15*time.Second {
+		t.Fatalf("runtime metrics = %+v", opts.RuntimeMetrics)
+	}
+	if opts.MetricAggregation == nil || opts.MetricAggregation.Interval != 5*time.Second {
+		t.Fatalf("metric aggregation = %+v", opts.MetricAggregation)
+	}
+	if opts.SelfTelemetry == nil || opts.SelfTelemetry.Interval != 20*time.Second {
+		t.Fatalf("self telemetry = %+v", opts.SelfTelemetry)
+	}
+}
+
+func TestMergeOptions_CodeWins(t *testing.T) {
+	base := Options{
+		APIKey:    "eu-lf_file",
+		Source:    "file",
+		QueueSize: 10,
+		Transport: client.TransportOptions{ProxyURL: "http://proxy:3128", CAFile: "ca.pem"},
+	}
+	merged := mergeOptions(base, Options{
+		Source:    "code",
+		Transport: client.TransportOptions{CAFile: "other.pem"},
+	})
+	if merged.Source != "code" || merged.APIKey != "eu-lf_file" || merged.QueueSize != 10 {
+		t.Fatalf("unexpected merge: %+v", merged)
+	}
+	if merged.Transport.ProxyURL != "http://proxy:3128" || merged.Transport.CAFile != "other.pem" {
+		t.Fatalf("nested Transport not merged field by field: %+v", merged.Transport)
+	}
+}
@@ -0,0 +1,41 @@
+package logflux
+
+import "context"
+
+// contextKey keys the SDK's values in a context.Context.
+type contextKey int
+
+const (
+	spanKey contextKey = iota
+	scopeKey
+)
+
+// ContextWithSpan returns a copy of ctx carrying span. TracingMiddleware
+// stores the request span this way; Recover marks it as errored.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey, span)
+}
+
+// SpanFromContext returns the span stored by ContextWithSpan, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey).(*Span)
+	return span
+}
+
+// ContextWithScope returns a copy of ctx carrying scope. Errors recovered
+// by Recover are sent through it, with its attributes and breadcrumbs.
+func ContextWithScope(ctx context.Context, scope *Scope) context.Context {
+	return context.WithValue(ctx, scopeKey, scope)
+}
+
+// ScopeFromContext returns the scope stored by ContextWithScope, or nil.
+func ScopeFromContext(ctx context.Context) *Scope {
+	if ctx == nil {
+		return nil
+	}
+	scope, _ := ctx.Value(scopeKey).(*Scope)
+	return scope
+}
@@ -0,0 +1,63 @@
+package logflux
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// NewErrorf formats an error like fmt.Errorf (including %w wrapping) and
+// records the stack where it was created. CaptureError reports that stack
+// instead of the one at the capture site, which is usually a generic
+// handler far from the failure.
+//
+// Unlike Errorf, which sends a formatted error log, NewErrorf only
+// constructs an error.
+func NewErrorf(format string, args ...any) error {
+	return withStack(fmt.Errorf(format, args...), payload.CallerPCs(1))
+}
+
+// WrapError annotates err with message ("message: err") and records the
+// stack where it was wrapped. It returns nil if err is nil; an empty message
+// only adds the stack. errors.Is and errors.As see through the wrapper.
+func WrapError(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if message != "" {
+		msg = message + ": " + msg
+	}
+	return &stackError{msg: msg, cause: err, stack: payload.CallerPCs(1)}
+}
+
+// withStack attaches pcs to err, preserving what it wraps.
+func withStack(err error, pcs []uintptr) error {
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		return &stackErrors{msg: err.Error(), causes: multi.Unwrap(), stack: pcs}
+	}
+	return &stackError{msg: err.Error(), cause: errors.Unwrap(err), stack: pcs}
+}
+
+// stackError is an error with at most one cause and a creation stack.
+type stackError struct {
+	msg   string
+	cause error
+	stack []uintptr
+}
+
+func (e *stackError) Error() string         { return e.msg }
+func (e *stackError) Unwrap() error         { return e.cause }
+func (e *stackError) StackTrace() []uintptr { return e.stack }
+
+// stackErrors is a stackError wrapping several errors (multiple %w verbs).
+type stackErrors struct {
+	msg    string
+	causes []error
+	stack  []uintptr
+}
+
+func (e *stackErrors) Error() string         { return e.msg }
+func (e *stackErrors) Unwrap() []error       { return e.causes }
+func (e *stackErrors) StackTrace() []uintptr { return e.stack }
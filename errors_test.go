@@ -0,0 +1,45 @@
+package logflux
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+func openConfig() error { return NewErrorf("open config: %w", io.ErrUnexpectedEOF) }
+
+func TestNewErrorf_RecordsCreationStack(t *testing.T) {
+	err := openConfig()
+	if err.Error() != "open config: unexpected EOF" || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("err = %v", err)
+	}
+	p := payload.NewErrorPayload("", err)
+	if !strings.HasSuffix(p.StackTrace[0].Function, ".openConfig") {
+		t.Errorf("first frame = %s, want openConfig", p.StackTrace[0].Function)
+	}
+	if len(p.ErrorChain) != 2 || p.ErrorChain[1].Message != "unexpected EOF" {
+		t.Errorf("chain = %#v", p.ErrorChain)
+	}
+
+	multi := NewErrorf("both: %w, %w", io.EOF, io.ErrClosedPipe)
+	if !errors.Is(multi, io.EOF) || !errors.Is(multi, io.ErrClosedPipe) {
+		t.Error("multiple %w causes should stay reachable")
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	if WrapError(nil, "x") != nil {
+		t.Error("WrapError(nil) should be nil")
+	}
+	err := WrapError(io.EOF, "read header")
+	if err.Error() != "read header: EOF" || !errors.Is(err, io.EOF) {
+		t.Fatalf("err = %v", err)
+	}
+	p := payload.NewErrorPayload("", err)
+	if !strings.HasSuffix(p.StackTrace[0].Function, ".TestWrapError") {
+		t.Errorf("first frame = %s, want TestWrapError", p.StackTrace[0].Function)
+	}
+}
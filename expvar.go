@@ -0,0 +1,54 @@
+package logflux
+
+import (
+	"encoding/json"
+	"expvar"
+	"path"
+	"sort"
+)
+
+// ExpvarSource returns a MetricSource reading the variables published with
+// expvar. Numbers become gauges, or counters if their name matches one of
+// the counters patterns (see path.Match); maps and JSON objects, such as
+// memstats, are flattened into dotted names like "memstats.HeapAlloc".
+// Strings, booleans and arrays are skipped.
+func ExpvarSource(counters ...string) MetricSource {
+	return MetricSourceFunc(func() ([]MetricSample, error) {
+		var samples []MetricSample
+		expvar.Do(func(kv expvar.KeyValue) {
+			var v any
+			if json.Unmarshal([]byte(kv.Value.String()), &v) != nil {
+				return
+			}
+			flattenExpvar(kv.Key, v, func(name string, value float64) {
+				kind := "gauge"
+				for _, p := range counters {
+					if ok, _ := path.Match(p, name); ok {
+						kind = "counter"
+						break
+					}
+				}
+				samples = append(samples, MetricSample{Name: name, Kind: kind, Value: value})
+			})
+		})
+		return samples, nil
+	})
+}
+
+// flattenExpvar calls emit for every number in v, naming nested values by
+// their dotted path from name.
+func flattenExpvar(name string, v any, emit func(string, float64)) {
+	switch v := v.(type) {
+	case float64:
+		emit(name, v)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			flattenExpvar(name+"."+k, v[k], emit)
+		}
+	}
+}
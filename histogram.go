@@ -0,0 +1,112 @@
+package logflux
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultHistogramBuckets are the bucket upper bounds used for histograms
+// without configured buckets. They suit durations in milliseconds, as
+// recorded by Timer, from 5ms to 10s.
+var DefaultHistogramBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// ExponentialBuckets returns count bucket bounds, the first being start and
+// each following one factor times the previous. It returns nil unless
+// start > 0, factor > 1 and count > 0.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	if start <= 0 || factor <= 1 || count <= 0 {
+		return nil
+	}
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start
+		start *= factor
+	}
+	return bounds
+}
+
+// LinearBuckets returns count bucket bounds, the first being start and each
+// following one width above the previous. It returns nil unless width > 0
+// and count > 0.
+func LinearBuckets(start, width float64, count int) []float64 {
+	if width <= 0 || count <= 0 {
+		return nil
+	}
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start + float64(i)*width
+	}
+	return bounds
+}
+
+// checkBuckets reports whether bounds are finite and strictly ascending, as
+// payload.Histogram.Observe requires.
+func checkBuckets(bounds []float64) error {
+	for i, b := range bounds {
+		if math.IsNaN(b) || math.IsInf(b, 0) {
+			return fmt.Errorf("bound %g is not finite", b)
+		}
+		if i > 0 && b <= bounds[i-1] {
+			return fmt.Errorf("bounds must be ascending (%g after %g)", b, bounds[i-1])
+		}
+	}
+	return nil
+}
+
+// checkHistogramOptions validates Options.HistogramBuckets and
+// HistogramBucketsByName.
+func checkHistogramOptions(opts Options) error {
+	if err := checkBuckets(opts.HistogramBuckets); err != nil {
+		return fmt.Errorf("logflux: HistogramBuckets: %w", err)
+	}
+	for name, bounds := range opts.HistogramBucketsByName {
+		if err := checkBuckets(bounds); err != nil {
+			return fmt.Errorf("logflux: HistogramBucketsByName[%q]: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Histogram records value in a histogram metric (type 2, kind=histogram).
+// Without MetricAggregation each call sends one entry holding that value;
+// with it, values are counted per series and sent once per interval.
+func (h *Hub) Histogram(name string, value float64, attrs Fields) error {
+	return h.Metric(name, value, "histogram", attrs)
+}
+
+// Timer starts timing an operation; ObserveDuration records the elapsed
+// milliseconds in the histogram name.
+func (h *Hub) Timer(name string) *Stopwatch {
+	return &Stopwatch{hub: h, name: name, start: time.Now()}
+}
+
+// histogramBuckets returns the bucket bounds configured for name.
+func (h *Hub) histogramBuckets(name string) []float64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if bounds, ok := h.bucketsFor[name]; ok && len(bounds) > 0 {
+		return bounds
+	}
+	if len(h.buckets) > 0 {
+		return h.buckets
+	}
+	return DefaultHistogramBuckets
+}
+
+// Stopwatch times one operation for a histogram; see Timer.
+type Stopwatch struct {
+	hub   *Hub
+	name  string
+	attrs Attributes
+	start time.Time
+}
+
+// ObserveDuration records the time since the Stopwatch was started, in
+// milliseconds with unit "ms", and returns it. It may be called more than
+// once, each call recording the time elapsed so far.
+func (s *Stopwatch) ObserveDuration() time.Duration {
+	d := time.Since(s.start)
+	_ = s.hub.metric(s.name, float64(d)/float64(time.Millisecond), "histogram", "ms", mergeAttrs(s.attrs, nil))
+	return d
+}
@@ -0,0 +1,122 @@
+package logflux
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuckets(t *testing.T) {
+	if got := ExponentialBuckets(1, 2, 4); !reflect.DeepEqual(got, []float64{1, 2, 4, 8}) {
+		t.Errorf("ExponentialBuckets = %v", got)
+	}
+	if got := LinearBuckets(10, 5, 3); !reflect.DeepEqual(got, []float64{10, 15, 20}) {
+		t.Errorf("LinearBuckets = %v", got)
+	}
+	if ExponentialBuckets(0, 2, 4) != nil || ExponentialBuckets(1, 1, 4) != nil || LinearBuckets(0, 0, 3) != nil {
+		t.Error("invalid arguments should return nil")
+	}
+}
+
+func TestHub_Histogram(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.HistogramBucketsByName = map[string][]float64{"size": {1, 10}}
+	rec := &metricRecorder{}
+	opts.BeforeSendMetric = rec.hook
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	_ = hub.Histogram("size", 5, Fields{"route": "/a"})
+	sw := hub.Timer("op.duration")
+	time.Sleep(2 * time.Millisecond)
+	d := sw.ObserveDuration()
+
+	got := rec.byName()
+	s := got["size"]
+	if len(s) != 1 || s[0].Kind != "histogram" || s[0].Histogram == nil {
+		t.Fatalf("size = %+v", s)
+	}
+	if h := s[0].Histogram; !reflect.DeepEqual(h.Bounds, []float64{1, 10}) || !reflect.DeepEqual(h.Counts, []uint64{0, 1, 0}) {
+		t.Errorf("histogram = %+v", h)
+	}
+	if s[0].Summary == nil || s[0].Summary.Count != 1 || s[0].Attributes["route"] != "/a" {
+		t.Errorf("size = %+v", s[0])
+	}
+
+	op := got["op.duration"]
+	if len(op) != 1 || op[0].Unit != "ms" || op[0].Value != float64(d)/float64(time.Millisecond) {
+		t.Fatalf("op.duration = %+v (observed %v)", op, d)
+	}
+	if !reflect.DeepEqual(op[0].Histogram.Bounds, DefaultHistogramBuckets) {
+		t.Errorf("bounds = %v, want defaults", op[0].Histogram.Bounds)
+	}
+}
+
+func TestHub_HistogramBucketsValidatedAndCopied(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.HistogramBuckets = []float64{10, 5}
+	if _, err := NewHub(opts); err == nil {
+		t.Fatal("NewHub accepted unsorted bounds")
+	}
+
+	bounds := []float64{1, 10}
+	opts.HistogramBuckets = bounds
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+	bounds[0] = 100
+	if got := hub.histogramBuckets("x"); !reflect.DeepEqual(got, []float64{1, 10}) {
+		t.Errorf("bounds = %v, want a copy of the configured ones", got)
+	}
+
+	opts.HistogramBuckets = nil
+	opts.HistogramBucketsByName = map[string][]float64{"size": {1, math.Inf(1)}}
+	if err := hub.Reconfigure(opts); err == nil {
+		t.Fatal("Reconfigure accepted an infinite bound")
+	}
+	if got := hub.histogramBuckets("size"); !reflect.DeepEqual(got, []float64{1, 10}) {
+		t.Errorf("rejected Reconfigure changed bounds to %v", got)
+	}
+}
+
+func TestHub_HistogramAggregated(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.MetricAggregation = &MetricAggregationOptions{Interval: time.Hour}
+	opts.HistogramBuckets = LinearBuckets(10, 10, 3)
+	rec := &metricRecorder{}
+	opts.BeforeSendMetric = rec.hook
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	log := hub.With(Fields{"table": "users"})
+	for _, v := range []float64{5, 15, 15, 25, 100} {
+		_ = log.Histogram("query.rows", v, nil)
+	}
+	_ = hub.Flush(time.Second)
+
+	m := rec.byName()["query.rows"]
+	if len(m) != 1 {
+		t.Fatalf("query.rows = %+v", m)
+	}
+	if h := m[0].Histogram; h == nil || !reflect.DeepEqual(h.Counts, []uint64{1, 2, 1, 1}) {
+		t.Errorf("histogram = %+v", h)
+	}
+	if s := m[0].Summary; s == nil || s.Count != 5 || s.Sum != 160 || s.Min != 5 || s.Max != 100 {
+		t.Errorf("summary = %+v", s)
+	}
+	if m[0].Value != 32 || m[0].Attributes["table"] != "users" {
+		t.Errorf("metric = %+v", m[0])
+	}
+}
@@ -0,0 +1,951 @@
+package logflux
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/diag"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/discovery"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// Hub owns a client together with the sampler, BeforeSend hooks, breadcrumbs
+// and payload context (source, environment, release) used when sending
+// through it. The package-level functions use a default hub configured by
+// Init; libraries and multi-tenant services create their own with NewHub so
+// that each has its own API key and settings.
+//
+// Usage:
+//
+//	tenantA, err := logflux.NewHub(logflux.Options{APIKey: keyA, Source: "billing"})
+//	if err != nil { ... }
+//	defer tenantA.Close()
+//	tenantA.Info("invoice created")
+type Hub struct {
+	mu          sync.RWMutex
+	client      *client.ResilientClient
+	sampler     *payload.Sampler
+	hooks       sendHooks
+	scrubber    *payload.Scrubber
+	throttle    *errorThrottle
+	redacted    atomic.Uint64
+	repanic     bool                  // re-panic after Recover captures a panic
+	panicFlush  time.Duration         // flush bound after a recovered panic
+	stack       *payload.StackOptions // stack capture; nil uses the process-wide settings
+	runtime     *runtimeCollector
+	aggregator  *metricAggregator
+	selfStats   *Bridge              // self-telemetry
+	buckets     []float64            // default histogram bounds
+	bucketsFor  map[string][]float64 // per-name histogram bounds; replaced, never mutated
+	minLevel    int
+	levels      map[string]int // per-logger minimum levels; replaced, never mutated
+	breadcrumbs *payload.BreadcrumbRing
+	context     *payload.GlobalContext
+	log         diag.Logger
+}
+
+// errNotInitialized is returned by operations that need a connected client.
+var errNotInitialized = errors.New("logflux: not initialized")
+
+// defaultHub backs the package-level API. It shares the process-wide payload
+// context so payload.ApplyContext stays consistent with Init.
+var defaultHub = &Hub{context: payload.DefaultContext()}
+
+// CurrentHub returns the hub used by the package-level functions.
+func CurrentHub() *Hub {
+	return defaultHub
+}
+
+// NewHub creates an independent hub with its own client and settings.
+func NewHub(opts Options) (*Hub, error) {
+	h := &Hub{context: payload.NewContext()}
+	if err := h.init(opts); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// NewHubWithConfig creates an independent hub from a ResilientClientConfig.
+func NewHubWithConfig(config client.ResilientClientConfig) (*Hub, error) {
+	h := &Hub{context: payload.NewContext()}
+	if err := h.initWithConfig(config); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// init configures the hub from opts and connects its client. The new client
+// is connected before anything is replaced, so on error the hub keeps its
+// previous client and settings. On success the previous client is drained
+// and closed.
+func (h *Hub) init(opts Options) error {
+	if err := checkHistogramOptions(opts); err != nil {
+		return err
+	}
+	cfg := clientConfig(opts)
+	c, err := client.NewResilientClientWithHandshake(cfg)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	prev := h.client
+	h.client = c
+	h.log = diag.OrNop(cfg.Logger)
+
+	source := opts.Source
+	if source == "" {
+		source = opts.Node
+	}
+	h.context.Configure(source, opts.Environment, opts.Release)
+
+	maxCrumbs := opts.MaxBreadcrumbs
+	if maxCrumbs <= 0 {
+		maxCrumbs = 100
+	}
+	h.breadcrumbs = payload.NewBreadcrumbRing(maxCrumbs)
+	prevThrottle := h.applyOptions(opts)
+	h.repanic = opts.RepanicOnRecover
+	h.panicFlush = opts.RecoverFlushTimeout
+	h.stack = nil
+	if opts.MaxStackFrames != 0 || opts.StackContextLines != 0 || len(opts.InAppPrefixes) > 0 {
+		h.stack = &payload.StackOptions{
+			MaxFrames:     opts.MaxStackFrames,
+			ContextLines:  opts.StackContextLines,
+			InAppPrefixes: append([]string(nil), opts.InAppPrefixes...),
+		}
+	}
+	prevRuntime := h.runtime
+	h.runtime = nil
+	if opts.RuntimeMetrics != nil {
+		h.runtime = newRuntimeCollector(h, *opts.RuntimeMetrics)
+		h.runtime.start()
+	}
+	prevAggregator := h.aggregator
+	h.aggregator = nil
+	if opts.MetricAggregation != nil {
+		h.aggregator = newMetricAggregator(h, *opts.MetricAggregation)
+		h.aggregator.start()
+	}
+	prevSelfStats := h.selfStats
+	h.selfStats = nil
+	if opts.SelfTelemetry != nil {
+		h.selfStats = startSelfTelemetry(h, *opts.SelfTelemetry)
+	}
+	h.mu.Unlock()
+	prevRuntime.close()
+	prevAggregator.close()
+	prevSelfStats.close()
+	prevThrottle.flush()
+
+	h.closePrevious(prev)
+	return nil
+}
+
+// applyOptions sets the settings that Reconfigure can change. h.mu must be
+// held. The replaced error throttle is returned so the caller can flush its
+// held-back repeats once h.mu is released.
+func (h *Hub) applyOptions(opts Options) (prevThrottle *errorThrottle) {
+	// SampleRate <= 0 means "send all" (default).
+	// Only values in (0.0, 1.0] are treated as an explicit sample rate.
+	rate := opts.SampleRate
+	if rate <= 0 {
+		rate = 1.0
+	}
+	h.sampler = payload.NewSampler(rate)
+	h.minLevel = opts.MinLevel
+	h.levels = nil
+	if len(opts.Levels) > 0 {
+		h.levels = make(map[string]int, len(opts.Levels))
+		for name, level := range opts.Levels {
+			h.levels[name] = level
+		}
+	}
+
+	h.scrubber = nil
+	if opts.Scrub != nil {
+		h.scrubber = payload.NewScrubber(*opts.Scrub)
+	}
+
+	prevThrottle = h.throttle
+	h.throttle = newErrorThrottle(opts.DuplicateErrorLimit, opts.DuplicateErrorWindow, h.sendCollapsed)
+
+	// Copied, so payloads never share a slice the caller may change.
+	h.buckets = append([]float64(nil), opts.HistogramBuckets...)
+	h.bucketsFor = nil
+	if len(opts.HistogramBucketsByName) > 0 {
+		h.bucketsFor = make(map[string][]float64, len(opts.HistogramBucketsByName))
+		for name, bounds := range opts.HistogramBucketsByName {
+			h.bucketsFor[name] = append([]float64(nil), bounds...)
+		}
+	}
+
+	h.hooks = sendHooks{
+		Log:       opts.BeforeSendLog,
+		Error:     opts.BeforeSendError,
+		Metric:    opts.BeforeSendMetric,
+		Event:     opts.BeforeSendEvent,
+		Audit:     opts.BeforeSendAudit,
+		Trace:     opts.BeforeSendTrace,
+		Telemetry: opts.BeforeSendTelemetry,
+	}
+	return prevThrottle
+}
+
+// closePrevious drains and closes a client replaced by re-initialization.
+// Entries still queued on it are sent before it shuts down.
+func (h *Hub) closePrevious(prev *client.ResilientClient) {
+	if prev == nil {
+		return
+	}
+	if err := prev.Close(); err != nil {
+		h.logger().Warn("closing previous client failed", "error", err)
+	}
+}
+
+// Reconfigure changes the sample rate, BeforeSend hooks, scrubbing, error
+// throttling, minimum level and batch size of a running hub without a new
+// handshake. These fields are taken from opts exactly as Init would apply
+// them (zero values select the defaults and nil hooks are removed); all
+// other fields are ignored. Queued entries are kept.
+func (h *Hub) Reconfigure(opts Options) error {
+	if err := checkHistogramOptions(opts); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	c := h.client
+	if c == nil {
+		h.mu.Unlock()
+		return errNotInitialized
+	}
+	prevThrottle := h.applyOptions(opts)
+	h.mu.Unlock()
+	prevThrottle.flush()
+
+	c.Reconfigure(client.ClientSettings{
+		BatchSize:  opts.BatchSize,
+		BeforeSend: opts.BeforeSend,
+	})
+	return nil
+}
+
+// initWithConfig configures the hub from a client config with default
+// sampling and breadcrumbs.
+func (h *Hub) initWithConfig(config client.ResilientClientConfig) error {
+	c, err := client.NewResilientClientWithHandshake(config)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	prev := h.client
+	h.client = c
+	h.log = diag.OrNop(config.Logger)
+	h.context.Configure(config.Node, config.Environment, "")
+	h.breadcrumbs = payload.NewBreadcrumbRing(100)
+	h.sampler = payload.NewSampler(1.0)
+	h.minLevel = 0
+	h.levels = nil
+	h.hooks = sendHooks{}
+	h.scrubber = nil
+	prevThrottle := h.throttle
+	h.throttle = nil
+	h.buckets, h.bucketsFor = nil, nil
+	h.repanic = false
+	h.panicFlush = 0
+	h.stack = nil
+	prevRuntime := h.runtime
+	h.runtime = nil
+	prevAggregator := h.aggregator
+	h.aggregator = nil
+	prevSelfStats := h.selfStats
+	h.selfStats = nil
+	h.mu.Unlock()
+	prevRuntime.close()
+	prevAggregator.close()
+	prevSelfStats.close()
+	prevThrottle.flush()
+
+	h.closePrevious(prev)
+	return nil
+}
+
+// clientConfig maps Options to the resilient client configuration.
+func clientConfig(opts Options) client.ResilientClientConfig {
+	cfg := client.DefaultResilientClientConfig()
+	cfg.APIKey = opts.APIKey
+	cfg.Node = opts.Node
+	cfg.Environment = opts.Environment
+	cfg.LogGroup = opts.LogGroup
+	cfg.CustomEndpointURL = opts.CustomEndpointURL
+	cfg.FallbackEndpointURLs = opts.FallbackEndpointURLs
+	cfg.BeforeSend = opts.BeforeSend
+	cfg.HTTPClient = opts.HTTPClient
+	cfg.TransportOptions = opts.Transport
+	if opts.Logger != nil {
+		cfg.Logger = opts.Logger
+	} else if opts.Debug {
+		cfg.Logger = diag.NewStderr()
+	}
+
+	if opts.QueueSize > 0 {
+		cfg.QueueSize = opts.QueueSize
+	}
+	if opts.FlushInterval > 0 {
+		cfg.FlushInterval = opts.FlushInterval
+	}
+	if opts.BatchSize > 0 {
+		cfg.BatchSize = opts.BatchSize
+	}
+	if opts.WorkerCount > 0 {
+		cfg.WorkerCount = opts.WorkerCount
+	}
+	if opts.HTTPTimeout > 0 {
+		cfg.HTTPTimeout = opts.HTTPTimeout
+	}
+	if opts.DiscoveryRefreshInterval > 0 {
+		cfg.DiscoveryRefreshInterval = opts.DiscoveryRefreshInterval
+	}
+	if opts.FailoverCooldown > 0 {
+		cfg.FailoverCooldown = opts.FailoverCooldown
+	}
+	if opts.DiscoveryCache || opts.DiscoveryCacheDir != "" {
+		// A missing cache directory only costs start-up latency, so it is not fatal.
+		if dc, err := discovery.NewFileCache(opts.DiscoveryCacheDir, opts.DiscoveryCacheTTL, 0); err == nil {
+			cfg.DiscoveryCache = dc
+		}
+	}
+	if opts.MaxRetries > 0 {
+		cfg.RetryConfig.MaxRetries = opts.MaxRetries
+	}
+	if opts.InitialDelay > 0 {
+		cfg.RetryConfig.InitialDelay = opts.InitialDelay
+	}
+	if opts.MaxDelay > 0 {
+		cfg.RetryConfig.MaxDelay = opts.MaxDelay
+	}
+	if opts.BackoffFactor > 0 {
+		cfg.RetryConfig.BackoffFactor = opts.BackoffFactor
+	}
+
+	cfg.FailsafeMode = opts.Failsafe
+	cfg.EnableCompression = opts.EnableCompression
+	return cfg
+}
+
+// --- State accessors ---
+
+// Client returns the hub's underlying client, or nil before initialization.
+func (h *Hub) Client() *client.ResilientClient {
+	h.mu.RLock()
+	c := h.client
+	h.mu.RUnlock()
+	return c
+}
+
+// logger returns the hub's diagnostic logger.
+func (h *Hub) logger() diag.Logger {
+	h.mu.RLock()
+	l := h.log
+	h.mu.RUnlock()
+	return diag.OrNop(l)
+}
+
+// enabled reports whether a log at level from the named logger passes the
+// hub's minimum level. A dotted name such as "db.pool" falls back to the
+// override for "db", then to MinLevel. It does not allocate, so callers check
+// it before building a payload.
+func (h *Hub) enabled(logger string, level int) bool {
+	h.mu.RLock()
+	min := h.minLevel
+	for name := logger; name != "" && h.levels != nil; {
+		if l, ok := h.levels[name]; ok {
+			min = l
+			break
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	h.mu.RUnlock()
+	return min == 0 || level <= min
+}
+
+// SetLevel sets the minimum level for the named logger (see NamedLogger) and
+// its dotted descendants at runtime. A level of 0 removes the override so the
+// logger follows MinLevel again. The change lasts until the next Init or
+// Reconfigure, which replace all overrides with Options.Levels.
+func (h *Hub) SetLevel(name string, level int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	levels := make(map[string]int, len(h.levels)+1)
+	for n, l := range h.levels {
+		levels[n] = l
+	}
+	if level == 0 {
+		delete(levels, name)
+	} else {
+		levels[name] = level
+	}
+	h.levels = levels
+}
+
+// getSampler returns the hub's sampler under a read lock.
+func (h *Hub) getSampler() *payload.Sampler {
+	h.mu.RLock()
+	s := h.sampler
+	h.mu.RUnlock()
+	return s
+}
+
+// stackOptions returns the hub's stack capture settings under a read lock.
+func (h *Hub) stackOptions() *payload.StackOptions {
+	h.mu.RLock()
+	s := h.stack
+	h.mu.RUnlock()
+	return s
+}
+
+// getBreadcrumbs returns the hub's breadcrumb ring under a read lock.
+func (h *Hub) getBreadcrumbs() *payload.BreadcrumbRing {
+	h.mu.RLock()
+	b := h.breadcrumbs
+	h.mu.RUnlock()
+	return b
+}
+
+// getHooks returns the hub's hooks under a read lock.
+func (h *Hub) getHooks() sendHooks {
+	h.mu.RLock()
+	hk := h.hooks
+	h.mu.RUnlock()
+	return hk
+}
+
+// scrub runs the hub's scrubber, if any, over p. It is called after the
+// BeforeSend hooks, just before p is marshalled.
+func (h *Hub) scrub(p any) {
+	h.mu.RLock()
+	s := h.scrubber
+	h.mu.RUnlock()
+	if n := s.Scrub(p); n > 0 {
+		h.redacted.Add(uint64(n))
+	}
+}
+
+// RedactedCount returns how many values the hub's scrubber has redacted.
+func (h *Hub) RedactedCount() uint64 {
+	return h.redacted.Load()
+}
+
+// sample reports whether an entry should be sent under the hub's sample rate.
+func (h *Hub) sample() bool {
+	s := h.getSampler()
+	return s == nil || s.ShouldSample()
+}
+
+// --- Breadcrumbs ---
+
+// AddBreadcrumb adds a breadcrumb to the hub's trail.
+func (h *Hub) AddBreadcrumb(category, message string, data Fields) {
+	b := h.getBreadcrumbs()
+	if b == nil {
+		return
+	}
+	b.Add(payload.Breadcrumb{
+		Category: category,
+		Message:  message,
+		Data:     data,
+	})
+}
+
+// AddBreadcrumbWithLevel adds a breadcrumb with a severity level.
+func (h *Hub) AddBreadcrumbWithLevel(category, message, level string, data Fields) {
+	b := h.getBreadcrumbs()
+	if b == nil {
+		return
+	}
+	b.Add(payload.Breadcrumb{
+		Category: category,
+		Message:  message,
+		Level:    level,
+		Data:     data,
+	})
+}
+
+// ClearBreadcrumbs removes all breadcrumbs.
+func (h *Hub) ClearBreadcrumbs() {
+	if b := h.getBreadcrumbs(); b != nil {
+		b.Clear()
+	}
+}
+
+// --- Log convenience (type 1) ---
+
+func (h *Hub) Debug(message string) error     { return h.Log(models.LogLevelDebug, message, nil) }
+func (h *Hub) Info(message string) error      { return h.Log(models.LogLevelInfo, message, nil) }
+func (h *Hub) Notice(message string) error    { return h.Log(models.LogLevelNotice, message, nil) }
+func (h *Hub) Warn(message string) error      { return h.Log(models.LogLevelWarning, message, nil) }
+func (h *Hub) Warning(message string) error   { return h.Log(models.LogLevelWarning, message, nil) }
+func (h *Hub) Error(message string) error     { return h.Log(models.LogLevelError, message, nil) }
+func (h *Hub) Critical(message string) error  { return h.Log(models.LogLevelCritical, message, nil) }
+func (h *Hub) Alert(message string) error     { return h.Log(models.LogLevelAlert, message, nil) }
+func (h *Hub) Emergency(message string) error { return h.Log(models.LogLevelEmergency, message, nil) }
+
+// Fatal sends a critical log, waits up to client.FatalFlushTimeout for
+// queued entries to be sent and exits with status 1 through the function
+// set with SetExitFunc. It returns only if that function does.
+func (h *Hub) Fatal(message string) error {
+	err := h.Log(models.LogLevelCritical, message, nil)
+	h.exitFatal()
+	return err
+}
+
+// exitFatal flushes the hub with a bounded wait and exits with status 1.
+func (h *Hub) exitFatal() {
+	if err := h.Flush(client.FatalFlushTimeout); err != nil {
+		h.logger().Warn("flush before exit failed", "error", err)
+	}
+	client.Exit(1)
+}
+
+// Debugf sends a formatted debug log.
+func (h *Hub) Debugf(format string, args ...interface{}) error {
+	return h.logf("", models.LogLevelDebug, format, args)
+}
+
+// Infof sends a formatted info log.
+func (h *Hub) Infof(format string, args ...interface{}) error {
+	return h.logf("", models.LogLevelInfo, format, args)
+}
+
+// Warnf sends a formatted warning log.
+func (h *Hub) Warnf(format string, args ...interface{}) error {
+	return h.logf("", models.LogLevelWarning, format, args)
+}
+
+// Errorf sends a formatted error log.
+func (h *Hub) Errorf(format string, args ...interface{}) error {
+	return h.logf("", models.LogLevelError, format, args)
+}
+
+// logf formats and sends a log only if the level is enabled, so filtered
+// calls skip the formatting.
+func (h *Hub) logf(logger string, level int, format string, args []interface{}) error {
+	if !h.enabled(logger, level) {
+		return nil
+	}
+	return h.emit(logger, level, fmt.Sprintf(format, args...), nil)
+}
+
+// Log sends a log entry (type 1) with the given level and attributes.
+func (h *Hub) Log(level int, message string, attrs Fields) error {
+	if !h.enabled("", level) {
+		return nil
+	}
+	return h.emit("", level, message, payload.AttributesFromStrings(attrs))
+}
+
+// LogAttrs sends a log entry (type 1) with typed attributes.
+func (h *Hub) LogAttrs(level int, message string, attrs Attributes) error {
+	return h.emit("", level, message, attrs)
+}
+
+// emit sends a log entry on behalf of the named logger ("" for the root).
+// The level check comes first so filtered calls allocate nothing.
+func (h *Hub) emit(logger string, level int, message string, attrs Attributes) error {
+	return h.emitAt(time.Time{}, logger, level, message, attrs)
+}
+
+// emitAt is emit with an explicit timestamp; the zero time means now.
+func (h *Hub) emitAt(ts time.Time, logger string, level int, message string, attrs Attributes) error {
+	if !h.enabled(logger, level) {
+		return nil
+	}
+	c := h.Client()
+	if c == nil {
+		return nil
+	}
+	if !h.sample() {
+		return nil
+	}
+	p := payload.NewLog("", message, level)
+	p.Logger = logger
+	if !ts.IsZero() {
+		p.SetTimestamp(ts)
+	}
+	h.context.Apply(p)
+	if attrs != nil {
+		p.SetAttrs(attrs)
+	}
+	if hook := h.getHooks().Log; hook != nil {
+		p = hook(p)
+		if p == nil {
+			return nil
+		}
+	}
+
+	if b := h.getBreadcrumbs(); b != nil && level <= models.LogLevelInfo {
+		addLogBreadcrumb(b, level, message)
+	}
+
+	h.scrub(p)
+	data, err := payload.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return c.SendLogWithEntryType(string(data), level, models.EntryTypeLog)
+}
+
+// SendLogWithTimestampAndLevel sends a log with an explicit timestamp.
+// Together with SendLogWithAttributes it lets a hub back the loggers in
+// pkg/adapters, which then send structured fields as typed attributes.
+func (h *Hub) SendLogWithTimestampAndLevel(message string, timestamp time.Time, level int) error {
+	return h.emitAt(timestamp, "", level, message, nil)
+}
+
+// SendLogWithAttributes sends a log with an explicit timestamp and typed
+// attributes.
+func (h *Hub) SendLogWithAttributes(message string, timestamp time.Time, level int, attrs Attributes) error {
+	return h.emitAt(timestamp, "", level, message, attrs)
+}
+
+// --- CaptureError (type 1 with stack trace + breadcrumbs) ---
+
+// CaptureError captures a Go error with automatic stack trace and breadcrumbs.
+func (h *Hub) CaptureError(err error) error {
+	return h.CaptureErrorWithAttrs(err, nil)
+}
+
+// CaptureErrorWithAttrs captures a Go error with stack trace, breadcrumbs, and attributes.
+func (h *Hub) CaptureErrorWithAttrs(err error, attrs Fields) error {
+	return h.captureError("", err, payload.AttributesFromStrings(attrs))
+}
+
+// captureError captures err on behalf of the named logger ("" for the root).
+func (h *Hub) captureError(logger string, err error, attrs Attributes) error {
+	c := h.Client()
+	if c == nil || err == nil {
+		return nil
+	}
+	if !h.sample() {
+		return nil
+	}
+
+	p := h.stackOptions().NewErrorPayload("", err)
+	p.Logger = logger
+	h.context.Apply(p)
+	if attrs != nil {
+		p.SetAttrs(attrs)
+	}
+	return h.sendError(c, p)
+}
+
+// CaptureErrorWithFingerprint captures err grouped under fingerprint
+// instead of the computed one, e.g. to merge errors from several call sites.
+// An empty fingerprint keeps the computed value.
+func (h *Hub) CaptureErrorWithFingerprint(err error, fingerprint string, attrs Fields) error {
+	c := h.Client()
+	if c == nil || err == nil {
+		return nil
+	}
+	if !h.sample() {
+		return nil
+	}
+
+	p := h.stackOptions().NewErrorPayload("", err)
+	if fingerprint != "" {
+		p.Fingerprint = fingerprint
+	}
+	h.context.Apply(p)
+	if attrs != nil {
+		p.SetAttributes(attrs)
+	}
+	return h.sendError(c, p)
+}
+
+// CaptureErrorWithMessage captures with a custom message (error goes into attributes).
+func (h *Hub) CaptureErrorWithMessage(err error, message string, attrs Fields) error {
+	c := h.Client()
+	if c == nil || err == nil {
+		return nil
+	}
+	if !h.sample() {
+		return nil
+	}
+
+	p := h.stackOptions().NewErrorPayloadWithMessage("", err, message)
+	h.context.Apply(p)
+	if attrs != nil {
+		for k, v := range attrs {
+			if p.Attributes == nil {
+				p.Attributes = make(payload.Attributes)
+			}
+			p.Attributes[k] = v
+		}
+	}
+	return h.sendError(c, p)
+}
+
+// sendError attaches breadcrumbs and sends p unless its fingerprint is
+// being throttled, in which case it is folded into a collapsed payload.
+func (h *Hub) sendError(c *client.ResilientClient, p *payload.ErrorPayload) error {
+	if b := h.getBreadcrumbs(); b != nil {
+		p.WithBreadcrumbs(b)
+	}
+	if !h.allowError(p) {
+		return nil
+	}
+	return h.deliverError(c, p)
+}
+
+// allowError reports whether p may be sent now under the error throttle.
+func (h *Hub) allowError(p *payload.ErrorPayload) bool {
+	h.mu.RLock()
+	t := h.throttle
+	h.mu.RUnlock()
+	return t.allow(p)
+}
+
+// sendCollapsed sends a payload standing for repeats held back by the
+// error throttle.
+func (h *Hub) sendCollapsed(p *payload.ErrorPayload) {
+	c := h.Client()
+	if c == nil {
+		return
+	}
+	if err := h.deliverError(c, p); err != nil {
+		h.logger().Warn("sending collapsed error failed", "fingerprint", p.Fingerprint, "error", err)
+	}
+}
+
+// deliverError runs the error hook and sends p.
+func (h *Hub) deliverError(c *client.ResilientClient, p *payload.ErrorPayload) error {
+	if hook := h.getHooks().Error; hook != nil {
+		p = hook(p)
+		if p == nil {
+			return nil
+		}
+	}
+
+	h.scrub(p)
+	data, err := payload.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return c.SendLogWithEntryType(string(data), models.LogLevelError, models.EntryTypeLog)
+}
+
+// --- Metric convenience (type 2) ---
+
+// Metric sends a metric entry (type 2).
+func (h *Hub) Metric(name string, value float64, kind string, attrs Fields) error {
+	return h.metric(name, value, kind, "", payload.AttributesFromStrings(attrs))
+}
+
+func (h *Hub) metric(name string, value float64, kind, unit string, attrs Attributes) error {
+	if h.Client() == nil {
+		return nil
+	}
+	h.mu.RLock()
+	agg := h.aggregator
+	h.mu.RUnlock()
+	if agg != nil {
+		agg.record(name, kind, unit, value, attrs)
+		return nil
+	}
+	if !h.sample() {
+		return nil
+	}
+	var p *payload.Metric
+	if kind == "histogram" {
+		p = payload.NewHistogram("", name, value, unit, h.histogramBuckets(name))
+	} else {
+		p = payload.NewGauge("", name, value, unit)
+		p.Kind = kind
+	}
+	if attrs != nil {
+		p.SetAttrs(attrs)
+	}
+	return h.sendMetric(p)
+}
+
+// sendMetric applies the global context, the metric hook and the scrubber
+// to p and sends it. It does not sample: callers that should be sampled
+// check first.
+func (h *Hub) sendMetric(p *payload.Metric) error {
+	return h.sendMetricEntry(p, false)
+}
+
+// sendMetricEntry is sendMetric; selfTelemetry sends p as the SDK's own
+// statistics, which the client counts apart (see ClientStats).
+func (h *Hub) sendMetricEntry(p *payload.Metric, selfTelemetry bool) error {
+	c := h.Client()
+	if c == nil {
+		return nil
+	}
+	h.context.Apply(p)
+	if hook := h.getHooks().Metric; hook != nil {
+		p = hook(p)
+		if p == nil {
+			return nil
+		}
+	}
+	h.scrub(p)
+	data, err := payload.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if selfTelemetry {
+		return c.SendSelfTelemetry(string(data), models.LogLevelInfo, models.EntryTypeMetric)
+	}
+	return c.SendLogWithEntryType(string(data), models.LogLevelInfo, models.EntryTypeMetric)
+}
+
+// Counter sends a counter metric (type 2, kind=counter).
+func (h *Hub) Counter(name string, value float64, attrs Fields) error {
+	return h.Metric(name, value, "counter", attrs)
+}
+
+// Gauge sends a gauge metric (type 2, kind=gauge).
+func (h *Hub) Gauge(name string, value float64, attrs Fields) error {
+	return h.Metric(name, value, "gauge", attrs)
+}
+
+// --- Event convenience (type 4) ---
+
+// Event sends an event entry (type 4).
+func (h *Hub) Event(event string, attrs Fields) error {
+	return h.EventAttrs(event, payload.AttributesFromStrings(attrs))
+}
+
+// EventAttrs sends an event entry (type 4) with typed attributes.
+func (h *Hub) EventAttrs(event string, attrs Attributes) error {
+	c := h.Client()
+	if c == nil {
+		return nil
+	}
+	if !h.sample() {
+		return nil
+	}
+	p := payload.NewEvent("", event)
+	h.context.Apply(p)
+	if attrs != nil {
+		p.SetAttrs(attrs)
+	}
+	if hook := h.getHooks().Event; hook != nil {
+		p = hook(p)
+		if p == nil {
+			return nil
+		}
+	}
+
+	if b := h.getBreadcrumbs(); b != nil {
+		b.Add(payload.Breadcrumb{
+			Category: "event",
+			Message:  event,
+			Data:     attrs.Strings(),
+		})
+	}
+
+	h.scrub(p)
+	data, err := payload.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return c.SendLogWithEntryType(string(data), models.LogLevelInfo, models.EntryTypeEvent)
+}
+
+// --- Audit convenience (type 5) ---
+
+// Audit sends an audit entry (type 5, Object Lock).
+func (h *Hub) Audit(action, actor, resource, resourceID string, attrs Fields) error {
+	c := h.Client()
+	if c == nil {
+		return nil
+	}
+	// Audit entries are never sampled — compliance requirement
+	p := payload.NewAudit("", action, actor, resource, resourceID)
+	h.context.Apply(p)
+	if attrs != nil {
+		p.SetAttributes(attrs)
+	}
+	if hook := h.getHooks().Audit; hook != nil {
+		p = hook(p)
+		if p == nil {
+			return nil
+		}
+	}
+	h.scrub(p)
+	data, err := payload.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return c.SendLogWithEntryType(string(data), models.LogLevelNotice, models.EntryTypeAudit)
+}
+
+// --- Lifecycle ---
+
+// Close flushes pending entries, including collapsed repeats held by the
+// error throttle, and shuts down the hub's client. Later calls on the hub
+// are no-ops, as before Init.
+func (h *Hub) Close() error {
+	c := h.Client()
+	if c == nil {
+		return nil
+	}
+	h.mu.Lock()
+	rc, agg, self := h.runtime, h.aggregator, h.selfStats
+	h.runtime, h.aggregator, h.selfStats = nil, nil, nil
+	h.mu.Unlock()
+	rc.close()
+	self.close()
+	agg.close()
+	h.flushThrottle()
+
+	h.mu.Lock()
+	if h.client == c {
+		h.client = nil
+	}
+	h.mu.Unlock()
+	return c.Close()
+}
+
+// Flush sends aggregated metrics and collapsed repeats held by the error
+// throttle, then waits up to timeout for queued entries to be sent.
+func (h *Hub) Flush(timeout time.Duration) error {
+	c := h.Client()
+	if c == nil {
+		return nil
+	}
+	h.mu.RLock()
+	agg := h.aggregator
+	h.mu.RUnlock()
+	agg.flush()
+	h.flushThrottle()
+	return c.Flush(timeout)
+}
+
+func (h *Hub) flushThrottle() {
+	h.mu.RLock()
+	t := h.throttle
+	h.mu.RUnlock()
+	t.flush()
+}
+
+// Stats returns the hub client's runtime statistics.
+func (h *Hub) Stats() client.ClientStats {
+	c := h.Client()
+	if c == nil {
+		return client.ClientStats{}
+	}
+	return c.GetStats()
+}
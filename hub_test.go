@@ -0,0 +1,368 @@
+package logflux
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// testIngestor is a mock LogFlux endpoint that completes handshakes and
+// counts ingest requests.
+type testIngestor struct {
+	*httptest.Server
+	ingests atomic.Int32
+}
+
+func newTestIngestor(t *testing.T) *testIngestor {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	s := &testIngestor{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/handshake/init", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"public_key": string(pemBytes)})
+	})
+	mux.HandleFunc("/v1/handshake/complete", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"key_id": "test-key"})
+	})
+	mux.HandleFunc("/v1/ingest", func(w http.ResponseWriter, r *http.Request) {
+		s.ingests.Add(1)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func testOptions(srv *testIngestor) Options {
+	return Options{
+		APIKey:            "eu-lf_testkey123",
+		Node:              "test-node",
+		CustomEndpointURL: srv.URL,
+		FlushInterval:     10 * time.Millisecond,
+		Failsafe:          true,
+	}
+}
+
+func TestHub_IsolatedClients(t *testing.T) {
+	srvA, srvB := newTestIngestor(t), newTestIngestor(t)
+
+	optsA := testOptions(srvA)
+	optsA.Source = "tenant-a"
+	hubA, err := NewHub(optsA)
+	if err != nil {
+		t.Fatalf("NewHub A: %v", err)
+	}
+	hubB, err := NewHub(testOptions(srvB))
+	if err != nil {
+		t.Fatalf("NewHub B: %v", err)
+	}
+
+	_ = hubA.Info("a1")
+	_ = hubA.Event("a2", nil)
+	_ = hubB.Info("b1")
+	defer hubA.Close()
+	defer hubB.Close()
+	if err := hubA.Flush(2 * time.Second); err != nil {
+		t.Fatalf("Flush A: %v", err)
+	}
+	if err := hubB.Flush(2 * time.Second); err != nil {
+		t.Fatalf("Flush B: %v", err)
+	}
+
+	if got := hubA.Stats().EntriesSent; got != 2 {
+		t.Errorf("hub A sent %d entries, want 2", got)
+	}
+	if got := hubB.Stats().EntriesSent; got != 1 {
+		t.Errorf("hub B sent %d entries, want 1", got)
+	}
+	if srvA.ingests.Load() == 0 || srvB.ingests.Load() == 0 {
+		t.Errorf("expected traffic on both endpoints, got A=%d B=%d", srvA.ingests.Load(), srvB.ingests.Load())
+	}
+	if hubA.context.Source() != "tenant-a" || hubB.context.Source() != "test-node" {
+		t.Errorf("hub contexts not isolated: A=%q B=%q", hubA.context.Source(), hubB.context.Source())
+	}
+	if CurrentHub().context.Source() == "tenant-a" {
+		t.Error("NewHub must not change the default hub's context")
+	}
+}
+
+func TestHub_HooksAndBreadcrumbsArePerHub(t *testing.T) {
+	srv := newTestIngestor(t)
+	var dropped atomic.Int32
+	opts := testOptions(srv)
+	opts.BeforeSendEvent = func(e *payload.Event) *payload.Event {
+		dropped.Add(1)
+		return nil
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	_ = hub.Event("signup", nil)
+	if dropped.Load() != 1 {
+		t.Errorf("hub hook called %d times, want 1", dropped.Load())
+	}
+
+	hub.AddBreadcrumb("nav", "/checkout", nil)
+	if hub.getBreadcrumbs().Size() != 1 {
+		t.Errorf("expected 1 breadcrumb on hub, got %d", hub.getBreadcrumbs().Size())
+	}
+	if b := CurrentHub().getBreadcrumbs(); b != nil && b.Size() != 0 {
+		t.Errorf("default hub should not see other hubs' breadcrumbs")
+	}
+}
+
+func TestHub_SpansSendThroughOwningHub(t *testing.T) {
+	srv := newTestIngestor(t)
+	hub, err := NewHub(testOptions(srv))
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	span := hub.StartSpan("job", "nightly")
+	child := span.StartChild("db.query", "SELECT 1")
+	_ = child.End()
+	_ = span.End()
+	defer hub.Close()
+	_ = hub.Flush(2 * time.Second)
+	if got := hub.Stats().EntriesSent; got != 2 {
+		t.Errorf("hub sent %d spans, want 2", got)
+	}
+}
+
+func TestHub_CloseDetachesClient(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.Failsafe = false
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	if err := hub.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if hub.Client() != nil {
+		t.Fatal("Close left the client attached")
+	}
+	if err := hub.CaptureError(errors.New("late")); err != nil {
+		t.Errorf("CaptureError after Close returned %v", err)
+	}
+	if err := hub.Flush(time.Millisecond); err != nil {
+		t.Errorf("Flush after Close returned %v", err)
+	}
+	if err := hub.Close(); err != nil {
+		t.Errorf("second Close returned %v", err)
+	}
+}
+
+func TestHub_UninitializedIsNoop(t *testing.T) {
+	h := &Hub{}
+	if err := h.Info("nothing"); err != nil {
+		t.Errorf("uninitialized hub returned %v", err)
+	}
+	if err := h.Flush(time.Millisecond); err != nil {
+		t.Errorf("Flush: %v", err)
+	}
+}
+
+func TestHub_TypedAttributes(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	var got []*payload.Log
+	opts.BeforeSendLog = func(l *payload.Log) *payload.Log {
+		got = append(got, l)
+		return l
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	_ = hub.LogAttrs(LogLevelInfo, "typed", NewAttributes("status", 200, "ok", true))
+	// The hub satisfies the adapters' typed-attribute interface.
+	_ = hub.SendLogWithAttributes("adapter", time.Unix(0, 0), LogLevelWarning, Attributes{"ms": 1.5})
+
+	if len(got) != 2 {
+		t.Fatalf("sent %d logs, want 2", len(got))
+	}
+	if got[0].Attributes["status"] != int64(200) || got[0].Attributes["ok"] != true {
+		t.Errorf("typed attributes = %#v", got[0].Attributes)
+	}
+	if got[1].Attributes["ms"] != 1.5 || got[1].Ts != "1970-01-01T00:00:00Z" {
+		t.Errorf("adapter entry = %#v %s", got[1].Attributes, got[1].Ts)
+	}
+}
+
+func TestEventOf(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	var got []*payload.Event
+	opts.BeforeSendEvent = func(e *payload.Event) *payload.Event {
+		got = append(got, e)
+		return e
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+	prev := defaultHub
+	defaultHub = hub
+	defer func() { defaultHub = prev }()
+
+	type signup struct {
+		UserID string `logflux:"user_id"`
+		Token  string `logflux:"token,redact"`
+		Plan   struct {
+			Name  string `logflux:"name"`
+			Seats int    `logflux:"seats"`
+		} `logflux:"plan"`
+	}
+	s := signup{UserID: "usr_1", Token: "secret"}
+	s.Plan.Name, s.Plan.Seats = "team", 5
+
+	if err := EventOf("signup", s); err != nil {
+		t.Fatalf("EventOf: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("sent %d events, want 1", len(got))
+	}
+	want := Attributes{"user_id": "usr_1", "token": payload.RedactedValue, "plan.name": "team", "plan.seats": int64(5)}
+	for k, v := range want {
+		if got[0].Attributes[k] != v {
+			t.Errorf("%s = %#v, want %#v", k, got[0].Attributes[k], v)
+		}
+	}
+}
+
+func TestHub_Scrub(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.Scrub = &ScrubOptions{DenyKeys: []string{"ssn"}}
+	var sent []string
+	opts.BeforeSend = func(e *models.LogEntry) *models.LogEntry {
+		sent = append(sent, e.Message)
+		return e
+	}
+	opts.BeforeSendLog = func(l *payload.Log) *payload.Log {
+		// Hooks run first and still see the raw values.
+		if l.Attributes["ssn"] != "123-45-6789" {
+			t.Errorf("hook saw %#v", l.Attributes)
+		}
+		return l
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	attrs := Attributes{"ssn": "123-45-6789", "user": "bob"}
+	_ = hub.LogAttrs(LogLevelInfo, "signup bob@example.com", attrs)
+	hub.AddBreadcrumb("auth", "token Bearer abc.def", nil)
+	_ = hub.CaptureError(errors.New("lookup failed for bob@example.com"))
+
+	if len(sent) != 2 {
+		t.Fatalf("sent %d entries, want 2", len(sent))
+	}
+	for _, s := range sent {
+		if strings.Contains(s, "bob@example.com") || strings.Contains(s, "123-45-6789") || strings.Contains(s, "abc.def") {
+			t.Errorf("entry not scrubbed: %s", s)
+		}
+	}
+	if attrs["ssn"] != "123-45-6789" {
+		t.Error("caller's attributes were modified")
+	}
+	// Log: message + ssn; error: message + both breadcrumbs (log and auth).
+	if got := hub.RedactedCount(); got != 5 {
+		t.Errorf("RedactedCount = %d, want 5", got)
+	}
+
+	if err := hub.Reconfigure(Options{BeforeSend: opts.BeforeSend}); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	_ = hub.Info("plain bob@example.com")
+	if !strings.Contains(sent[len(sent)-1], "bob@example.com") {
+		t.Error("Reconfigure without Scrub should disable scrubbing")
+	}
+}
+
+func TestHub_FatalFlushesAndExits(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.FlushInterval = time.Hour // only the flush in Fatal sends
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	exited := -1
+	var sentAtExit int32
+	prev := SetExitFunc(func(code int) {
+		exited = code
+		sentAtExit = srv.ingests.Load()
+	})
+	defer SetExitFunc(prev)
+
+	_ = hub.Fatal("cannot continue")
+	if exited != 1 {
+		t.Fatalf("exit code = %d, want 1", exited)
+	}
+	if sentAtExit == 0 {
+		t.Error("fatal log was not sent before exit")
+	}
+}
+
+func TestHub_StackOptionsArePerHub(t *testing.T) {
+	srv := newTestIngestor(t)
+	frames := func(opts Options) int {
+		var n atomic.Int32
+		opts.BeforeSendError = func(p *payload.ErrorPayload) *payload.ErrorPayload {
+			n.Store(int32(len(p.StackTrace)))
+			return nil
+		}
+		hub, err := NewHub(opts)
+		if err != nil {
+			t.Fatalf("NewHub: %v", err)
+		}
+		defer hub.Close()
+		_ = hub.CaptureError(errors.New("boom"))
+		return int(n.Load())
+	}
+
+	short := testOptions(srv)
+	short.MaxStackFrames = 1
+	if got := frames(short); got != 1 {
+		t.Errorf("hub with MaxStackFrames 1 sent %d frames", got)
+	}
+	if got := frames(testOptions(srv)); got <= 1 {
+		t.Errorf("default hub sent %d frames; stack options leaked between hubs", got)
+	}
+	if p := payload.NewErrorPayload("", errors.New("boom")); len(p.StackTrace) <= 1 {
+		t.Error("NewHub must not change the process-wide stack options")
+	}
+}
@@ -1,56 +1,17 @@
 package logflux
 
 import (
-	"fmt"
-	"sync"
+	"net/http"
 	"time"
 
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/config"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/diag"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/retry"
 )
 
-var (
-	globalMu     sync.RWMutex
-	globalClient *client.ResilientClient
-	breadcrumbs  *payload.BreadcrumbRing
-	hooks        sendHooks
-	sampler      *payload.Sampler
-)
-
-// getClient returns the global client under a read lock.
-func getClient() *client.ResilientClient {
-	globalMu.RLock()
-	c := globalClient
-	globalMu.RUnlock()
-	return c
-}
-
-// getSampler returns the global sampler under a read lock.
-func getSampler() *payload.Sampler {
-	globalMu.RLock()
-	s := sampler
-	globalMu.RUnlock()
-	return s
-}
-
-// getBreadcrumbs returns the global breadcrumb ring under a read lock.
-func getBreadcrumbs() *payload.BreadcrumbRing {
-	globalMu.RLock()
-	b := breadcrumbs
-	globalMu.RUnlock()
-	return b
-}
-
-// getHooks returns the global hooks under a read lock.
-func getHooks() sendHooks {
-	globalMu.RLock()
-	h := hooks
-	globalMu.RUnlock()
-	return h
-}
-
 // Typed BeforeSend callbacks. Return nil to drop the entry.
 type sendHooks struct {
 	Log       func(*payload.Log) *payload.Log
@@ -62,9 +23,43 @@ type sendHooks struct {
 	Telemetry func(*payload.Telemetry) *payload.Telemetry
 }
 
-// Fields is a convenience alias for attributes.
+// Fields is a convenience alias for string-only attributes.
 type Fields = map[string]string
 
+// Attributes holds typed attribute values (string, int64, float64, bool and
+// string/number slices). Build them with NewAttributes or Attributes.Set,
+// which convert other Go types and flatten nested maps to dotted keys.
+type Attributes = payload.Attributes
+
+// NewAttributes returns Attributes from alternating keys and values:
+// logflux.NewAttributes("status", 200, "cached", true).
+func NewAttributes(kv ...any) Attributes { return payload.NewAttributes(kv...) }
+
+// Attrs converts a struct (or pointer to struct) to Attributes using its
+// `logflux:"name,omitempty,redact"` field tags. Nested structs are flattened
+// to dotted keys; see payload.StructAttributes for the full rules.
+//
+//	logflux.LogAttrs(logflux.LogLevelInfo, "signup", logflux.Attrs(user))
+func Attrs(v any) Attributes { return payload.StructAttributes(v) }
+
+// Fingerprinter is implemented by errors that choose their own grouping
+// fingerprint; see payload.Fingerprinter.
+type Fingerprinter = payload.Fingerprinter
+
+// ScrubOptions configures the built-in PII and secret scrubber; see
+// payload.ScrubOptions.
+type ScrubOptions = payload.ScrubOptions
+
+// ScrubRule is a custom scrubbing pattern; see payload.ScrubRule.
+type ScrubRule = payload.ScrubRule
+
+// Scrub actions.
+const (
+	ScrubMask   = payload.ScrubMask
+	ScrubHash   = payload.ScrubHash
+	ScrubRemove = payload.ScrubRemove
+)
+
 // Options configures the LogFlux SDK.
 type Options struct {
 	APIKey            string
@@ -75,6 +70,13 @@ type Options struct {
 	LogGroup          string
 	CustomEndpointURL string
 
+	FallbackEndpointURLs     []string      // Extra ingest base URLs used when the primary is down
+	DiscoveryRefreshInterval time.Duration // Background re-discovery interval (default: 1h)
+	DiscoveryCache           bool          // Cache discovery results on disk for fast start-up
+	DiscoveryCacheDir        string        // Cache directory (default: user cache dir + /logflux)
+	DiscoveryCacheTTL        time.Duration // Fresh lifetime of a cached result (default: 24h)
+	FailoverCooldown         time.Duration // How long a failed endpoint is skipped (default: 30s)
+
 	QueueSize     int
 	FlushInterval time.Duration
 	BatchSize     int
@@ -86,12 +88,64 @@ type Options struct {
 	BackoffFactor float64
 
 	HTTPTimeout       time.Duration
+	HTTPClient        *http.Client            // Use this client for all SDK traffic (overrides Transport)
+	Transport         client.TransportOptions // Proxy, custom CA, mTLS and connection pool tuning
 	Failsafe          bool
 	EnableCompression bool
-	Debug             bool
+	Debug             bool        // Write SDK diagnostics to stderr
+	Logger            diag.Logger // Diagnostic sink, e.g. an *slog.Logger (implies Debug)
 
 	MaxBreadcrumbs int     // Ring buffer size (default: 100)
 	SampleRate     float64 // 0.0-1.0, probability of sending an entry (default: 1.0 = send all)
+	MinLevel       int     // Most verbose level sent, e.g. models.LogLevelInfo (default: 0 = all)
+
+	// Per-logger minimum levels keyed by NamedLogger name, overriding MinLevel
+	// for that logger and its dotted descendants.
+	Levels map[string]int
+
+	// Scrub enables the built-in scrubber, which redacts emails, card
+	// numbers, tokens, cloud and API keys and denylisted attribute keys
+	// from every entry after the BeforeSend hooks run. nil disables it;
+	// &ScrubOptions{} uses the default rules.
+	Scrub *ScrubOptions
+
+	// DuplicateErrorLimit is how many captured errors with the same
+	// fingerprint are sent per DuplicateErrorWindow (default: 1 minute).
+	// Further repeats are collapsed into one payload whose occurrences field
+	// counts them, sent when the window ends. 0 disables throttling.
+	DuplicateErrorLimit  int
+	DuplicateErrorWindow time.Duration
+
+	// Stack trace capture for this hub. When all are zero, the hub uses the
+	// process-wide settings (see payload.SetStackOptions).
+	MaxStackFrames    int      // Frames kept per stack (default: 20)
+	StackContextLines int      // Source lines around in-app frames, if readable (default: 0)
+	InAppPrefixes     []string // Package prefixes treated as application code besides the main module
+
+	// Panic recovery (Recover, Go and TracingMiddleware).
+	RepanicOnRecover    bool          // Resume the panic after it is captured and flushed
+	RecoverFlushTimeout time.Duration // Flush bound after a recovered panic (default: 2s)
+
+	// RuntimeMetrics enables the collector that sends Go runtime and
+	// process metrics (go.* and process.*) every interval. nil disables it.
+	RuntimeMetrics *RuntimeMetricsOptions
+
+	// MetricAggregation aggregates Metric, Counter, Gauge and Histogram
+	// calls in the client and sends one entry per series every interval,
+	// instead of one per call. Aggregated metrics are not sampled. nil
+	// disables it.
+	MetricAggregation *MetricAggregationOptions
+
+	// SelfTelemetry sends the SDK's own statistics (see Stats) as
+	// logflux.sdk.* metrics every interval. nil disables it.
+	SelfTelemetry *SelfTelemetryOptions
+
+	// Histogram bucket upper bounds, finite and strictly ascending; Init
+	// and Reconfigure reject others. HistogramBucketsByName overrides
+	// HistogramBuckets for the named metrics; both default to
+	// DefaultHistogramBuckets.
+	HistogramBuckets       []float64
+	HistogramBucketsByName map[string][]float64
 
 	// Global BeforeSend — runs on all entry types at the transport level.
 	BeforeSend client.BeforeSendFunc
@@ -106,86 +160,20 @@ type Options struct {
 	BeforeSendTelemetry func(*payload.Telemetry) *payload.Telemetry
 }
 
-// Init initializes LogFlux with the given options.
+// Init initializes LogFlux with the given options. It configures the default
+// hub used by the package-level functions. Calling Init again replaces the
+// client: the previous one is drained and closed once the new one is
+// connected. If the new client cannot be created, the previous one stays in
+// use and the error is returned.
 func Init(opts Options) error {
-	globalMu.Lock()
-	defer globalMu.Unlock()
-
-	// Configure global payload context
-	source := opts.Source
-	if source == "" {
-		source = opts.Node
-	}
-	payload.Configure(source, opts.Environment, opts.Release)
-
-	// Initialize breadcrumb ring buffer
-	maxCrumbs := opts.MaxBreadcrumbs
-	if maxCrumbs <= 0 {
-		maxCrumbs = 100
-	}
-	breadcrumbs = payload.NewBreadcrumbRing(maxCrumbs)
-
-	// Initialize sampler. SampleRate <= 0 means "send all" (default).
-	// Only values in (0.0, 1.0] are treated as an explicit sample rate.
-	rate := opts.SampleRate
-	if rate <= 0 {
-		rate = 1.0
-	}
-	sampler = payload.NewSampler(rate)
-
-	cfg := client.DefaultResilientClientConfig()
-	cfg.APIKey = opts.APIKey
-	cfg.Node = opts.Node
-	cfg.Environment = opts.Environment
-	cfg.LogGroup = opts.LogGroup
-	cfg.CustomEndpointURL = opts.CustomEndpointURL
-	cfg.BeforeSend = opts.BeforeSend
-
-	if opts.QueueSize > 0 {
-		cfg.QueueSize = opts.QueueSize
-	}
-	if opts.FlushInterval > 0 {
-		cfg.FlushInterval = opts.FlushInterval
-	}
-	if opts.BatchSize > 0 {
-		cfg.BatchSize = opts.BatchSize
-	}
-	if opts.WorkerCount > 0 {
-		cfg.WorkerCount = opts.WorkerCount
-	}
-	if opts.HTTPTimeout > 0 {
-		cfg.HTTPTimeout = opts.HTTPTimeout
-	}
-	if opts.MaxRetries > 0 {
-		cfg.RetryConfig.MaxRetries = opts.MaxRetries
-	}
-	if opts.InitialDelay > 0 {
-		cfg.RetryConfig.InitialDelay = opts.InitialDelay
-	}
-	if opts.MaxDelay > 0 {
-		cfg.RetryConfig.MaxDelay = opts.MaxDelay
-	}
-	if opts.BackoffFactor > 0 {
-		cfg.RetryConfig.BackoffFactor = opts.BackoffFactor
-	}
-
-	cfg.FailsafeMode = opts.Failsafe
-	cfg.EnableCompression = opts.EnableCompression
-
-	// Store typed hooks
-	hooks = sendHooks{
-		Log:       opts.BeforeSendLog,
-		Error:     opts.BeforeSendError,
-		Metric:    opts.BeforeSendMetric,
-		Event:     opts.BeforeSendEvent,
-		Audit:     opts.BeforeSendAudit,
-		Trace:     opts.BeforeSendTrace,
-		Telemetry: opts.BeforeSendTelemetry,
-	}
+	return defaultHub.init(opts)
+}
 
-	var err error
-	globalClient, err = client.NewResilientClientWithHandshake(cfg)
-	return err
+// Reconfigure changes the sample rate, BeforeSend hooks, scrubbing, error
+// throttling, minimum level and batch size of the default hub at runtime,
+// without a new handshake. See Hub.Reconfigure.
+func Reconfigure(opts Options) error {
+	return defaultHub.Reconfigure(opts)
 }
 
 // InitSimple initializes LogFlux with just an API key and node name.
@@ -199,65 +187,35 @@ func InitSimple(apiKey, node string) error {
 	})
 }
 
-// InitFromEnv initializes LogFlux from environment variables.
+// InitFromEnv initializes LogFlux from LOGFLUX_* environment variables.
+// A non-empty node overrides LOGFLUX_NODE.
 func InitFromEnv(node string) error {
-	globalMu.Lock()
-	defer globalMu.Unlock()
-	breadcrumbs = payload.NewBreadcrumbRing(100)
-	sampler = payload.NewSampler(1.0)
-	var err error
-	globalClient, err = client.NewResilientClientFromEnvWithHandshake(node)
-	return err
+	cfg, err := config.LoadConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	return Init(mergeOptions(OptionsFromConfig(cfg), Options{Node: node}))
 }
 
 // InitWithConfig initializes LogFlux with a ResilientClientConfig directly.
 func InitWithConfig(config client.ResilientClientConfig) error {
-	globalMu.Lock()
-	defer globalMu.Unlock()
-	payload.Configure(config.Node, config.Environment, "")
-	breadcrumbs = payload.NewBreadcrumbRing(100)
-	sampler = payload.NewSampler(1.0)
-	var err error
-	globalClient, err = client.NewResilientClientWithHandshake(config)
-	return err
+	return defaultHub.initWithConfig(config)
 }
 
 // --- Breadcrumbs ---
 
 // AddBreadcrumb adds a breadcrumb to the trail.
 func AddBreadcrumb(category, message string, data Fields) {
-	b := getBreadcrumbs()
-	if b == nil {
-		return
-	}
-	b.Add(payload.Breadcrumb{
-		Category: category,
-		Message:  message,
-		Data:     data,
-	})
+	defaultHub.AddBreadcrumb(category, message, data)
 }
 
 // AddBreadcrumbWithLevel adds a breadcrumb with a severity level.
 func AddBreadcrumbWithLevel(category, message, level string, data Fields) {
-	b := getBreadcrumbs()
-	if b == nil {
-		return
-	}
-	b.Add(payload.Breadcrumb{
-		Category: category,
-		Message:  message,
-		Level:    level,
-		Data:     data,
-	})
+	defaultHub.AddBreadcrumbWithLevel(category, message, level, data)
 }
 
 // ClearBreadcrumbs removes all breadcrumbs.
-func ClearBreadcrumbs() {
-	b := getBreadcrumbs()
-	if b != nil {
-		b.Clear()
-	}
-}
+func ClearBreadcrumbs() { defaultHub.ClearBreadcrumbs() }
 
 // --- Log convenience (type 1) ---
 
@@ -269,279 +227,120 @@ func Warning(message string) error   { return Log(models.LogLevelWarning, messag
 func Critical(message string) error  { return Log(models.LogLevelCritical, message, nil) }
 func Alert(message string) error     { return Log(models.LogLevelAlert, message, nil) }
 func Emergency(message string) error { return Log(models.LogLevelEmergency, message, nil) }
-func Fatal(message string) error     { return Log(models.LogLevelCritical, message, nil) }
+func Fatal(message string) error     { return defaultHub.Fatal(message) }
+
+// SetExitFunc replaces the function Fatal calls to end the process
+// (default: os.Exit) and returns the previous one, e.g. to observe fatal
+// paths in tests. It also applies to the Fatal functions of pkg/logger and
+// pkg/adapters; nil restores os.Exit.
+func SetExitFunc(fn func(code int)) func(code int) { return client.SetExitFunc(fn) }
 
 // Debugf sends a formatted debug log.
-func Debugf(format string, args ...interface{}) error {
-	return Log(models.LogLevelDebug, fmt.Sprintf(format, args...), nil)
-}
+func Debugf(format string, args ...interface{}) error { return defaultHub.Debugf(format, args...) }
 
 // Infof sends a formatted info log.
-func Infof(format string, args ...interface{}) error {
-	return Log(models.LogLevelInfo, fmt.Sprintf(format, args...), nil)
-}
+func Infof(format string, args ...interface{}) error { return defaultHub.Infof(format, args...) }
 
 // Warnf sends a formatted warning log.
-func Warnf(format string, args ...interface{}) error {
-	return Log(models.LogLevelWarning, fmt.Sprintf(format, args...), nil)
-}
+func Warnf(format string, args ...interface{}) error { return defaultHub.Warnf(format, args...) }
 
 // Errorf sends a formatted error log.
-func Errorf(format string, args ...interface{}) error {
-	return Log(models.LogLevelError, fmt.Sprintf(format, args...), nil)
-}
+func Errorf(format string, args ...interface{}) error { return defaultHub.Errorf(format, args...) }
 
 // Log sends a log entry (type 1) with the given level and attributes.
 func Log(level int, message string, attrs Fields) error {
-	c := getClient()
-	if c == nil {
-		return nil
-	}
-	if s := getSampler(); s != nil && !s.ShouldSample() {
-		return nil
-	}
-	p := payload.NewLog("", message, level)
-	payload.ApplyContext(p)
-	if attrs != nil {
-		p.SetAttributes(attrs)
-	}
-	h := getHooks()
-	if h.Log != nil {
-		p = h.Log(p)
-		if p == nil {
-			return nil
-		}
-	}
-
-	if b := getBreadcrumbs(); b != nil && level <= models.LogLevelInfo {
-		addLogBreadcrumb(b, level, message)
-	}
+	return defaultHub.Log(level, message, attrs)
+}
 
-	data, err := payload.Marshal(p)
-	if err != nil {
-		return err
-	}
-	return c.SendLogWithEntryType(string(data), level, models.EntryTypeLog)
+// LogAttrs sends a log entry (type 1) with typed attributes.
+func LogAttrs(level int, message string, attrs Attributes) error {
+	return defaultHub.LogAttrs(level, message, attrs)
 }
 
 // Error sends an error-level log message.
-func Error(message string) error {
-	return Log(models.LogLevelError, message, nil)
-}
+func Error(message string) error { return defaultHub.Error(message) }
 
 // --- CaptureError (type 1 with stack trace + breadcrumbs) ---
 
 // CaptureError captures a Go error with automatic stack trace and breadcrumbs.
-func CaptureError(err error) error {
-	return CaptureErrorWithAttrs(err, nil)
-}
+func CaptureError(err error) error { return defaultHub.CaptureError(err) }
 
 // CaptureErrorWithAttrs captures a Go error with stack trace, breadcrumbs, and attributes.
 func CaptureErrorWithAttrs(err error, attrs Fields) error {
-	c := getClient()
-	if c == nil || err == nil {
-		return nil
-	}
-	if s := getSampler(); s != nil && !s.ShouldSample() {
-		return nil
-	}
-
-	p := payload.NewErrorPayload("", err)
-	payload.ApplyContext(p)
-	if attrs != nil {
-		p.SetAttributes(attrs)
-	}
-	if b := getBreadcrumbs(); b != nil {
-		p.WithBreadcrumbs(b)
-	}
-	h := getHooks()
-	if h.Error != nil {
-		p = h.Error(p)
-		if p == nil {
-			return nil
-		}
-	}
-
-	data, marshalErr := payload.Marshal(p)
-	if marshalErr != nil {
-		return marshalErr
-	}
-	return c.SendLogWithEntryType(string(data), models.LogLevelError, models.EntryTypeLog)
+	return defaultHub.CaptureErrorWithAttrs(err, attrs)
 }
 
 // CaptureErrorWithMessage captures with a custom message (error goes into attributes).
 func CaptureErrorWithMessage(err error, message string, attrs Fields) error {
-	c := getClient()
-	if c == nil || err == nil {
-		return nil
-	}
-	if s := getSampler(); s != nil && !s.ShouldSample() {
-		return nil
-	}
-
-	p := payload.NewErrorPayloadWithMessage("", err, message)
-	payload.ApplyContext(p)
-	if attrs != nil {
-		for k, v := range attrs {
-			if p.Attributes == nil {
-				p.Attributes = make(Fields)
-			}
-			p.Attributes[k] = v
-		}
-	}
-	if b := getBreadcrumbs(); b != nil {
-		p.WithBreadcrumbs(b)
-	}
-	h := getHooks()
-	if h.Error != nil {
-		p = h.Error(p)
-		if p == nil {
-			return nil
-		}
-	}
+	return defaultHub.CaptureErrorWithMessage(err, message, attrs)
+}
 
-	data, marshalErr := payload.Marshal(p)
-	if marshalErr != nil {
-		return marshalErr
-	}
-	return c.SendLogWithEntryType(string(data), models.LogLevelError, models.EntryTypeLog)
+// CaptureErrorWithFingerprint captures err grouped under fingerprint instead
+// of the computed one. Errors can also choose their own fingerprint by
+// implementing Fingerprinter.
+func CaptureErrorWithFingerprint(err error, fingerprint string, attrs Fields) error {
+	return defaultHub.CaptureErrorWithFingerprint(err, fingerprint, attrs)
 }
 
 // --- Metric convenience (type 2) ---
 
 // Metric sends a metric entry (type 2).
 func Metric(name string, value float64, kind string, attrs Fields) error {
-	c := getClient()
-	if c == nil {
-		return nil
-	}
-	if s := getSampler(); s != nil && !s.ShouldSample() {
-		return nil
-	}
-	p := payload.NewGauge("", name, value, "")
-	p.Kind = kind
-	payload.ApplyContext(p)
-	if attrs != nil {
-		p.SetAttributes(attrs)
-	}
-	h := getHooks()
-	if h.Metric != nil {
-		p = h.Metric(p)
-		if p == nil {
-			return nil
-		}
-	}
-	data, err := payload.Marshal(p)
-	if err != nil {
-		return err
-	}
-	return c.SendLogWithEntryType(string(data), models.LogLevelInfo, models.EntryTypeMetric)
+	return defaultHub.Metric(name, value, kind, attrs)
 }
 
 // Counter sends a counter metric (type 2, kind=counter).
 func Counter(name string, value float64, attrs Fields) error {
-	return Metric(name, value, "counter", attrs)
+	return defaultHub.Counter(name, value, attrs)
 }
 
 // Gauge sends a gauge metric (type 2, kind=gauge).
 func Gauge(name string, value float64, attrs Fields) error {
-	return Metric(name, value, "gauge", attrs)
+	return defaultHub.Gauge(name, value, attrs)
+}
+
+// Histogram records value in a histogram metric (type 2, kind=histogram)
+// with the configured buckets.
+func Histogram(name string, value float64, attrs Fields) error {
+	return defaultHub.Histogram(name, value, attrs)
 }
 
+// Timer starts timing an operation; ObserveDuration records the elapsed
+// milliseconds in the histogram name.
+//
+//	defer logflux.Timer("db.query.duration").ObserveDuration()
+func Timer(name string) *Stopwatch { return defaultHub.Timer(name) }
+
 // --- Event convenience (type 4) ---
 
 // Event sends an event entry (type 4).
-func Event(event string, attrs Fields) error {
-	c := getClient()
-	if c == nil {
-		return nil
-	}
-	if s := getSampler(); s != nil && !s.ShouldSample() {
-		return nil
-	}
-	p := payload.NewEvent("", event)
-	payload.ApplyContext(p)
-	if attrs != nil {
-		p.SetAttributes(attrs)
-	}
-	h := getHooks()
-	if h.Event != nil {
-		p = h.Event(p)
-		if p == nil {
-			return nil
-		}
-	}
+func Event(event string, attrs Fields) error { return defaultHub.Event(event, attrs) }
 
-	if b := getBreadcrumbs(); b != nil {
-		b.Add(payload.Breadcrumb{
-			Category: "event",
-			Message:  event,
-			Data:     attrs,
-		})
-	}
+// EventAttrs sends an event entry (type 4) with typed attributes.
+func EventAttrs(event string, attrs Attributes) error { return defaultHub.EventAttrs(event, attrs) }
 
-	data, err := payload.Marshal(p)
-	if err != nil {
-		return err
-	}
-	return c.SendLogWithEntryType(string(data), models.LogLevelInfo, models.EntryTypeEvent)
-}
+// EventOf sends an event entry (type 4) whose attributes are the fields of
+// v, encoded with Attrs. For a specific hub or logger use
+// EventAttrs(event, logflux.Attrs(v)).
+func EventOf[T any](event string, v T) error { return defaultHub.EventAttrs(event, Attrs(v)) }
 
 // --- Audit convenience (type 5) ---
 
 // Audit sends an audit entry (type 5, Object Lock).
 func Audit(action, actor, resource, resourceID string, attrs Fields) error {
-	c := getClient()
-	if c == nil {
-		return nil
-	}
-	// Audit entries are never sampled — compliance requirement
-	p := payload.NewAudit("", action, actor, resource, resourceID)
-	payload.ApplyContext(p)
-	if attrs != nil {
-		p.SetAttributes(attrs)
-	}
-	h := getHooks()
-	if h.Audit != nil {
-		p = h.Audit(p)
-		if p == nil {
-			return nil
-		}
-	}
-	data, err := payload.Marshal(p)
-	if err != nil {
-		return err
-	}
-	return c.SendLogWithEntryType(string(data), models.LogLevelNotice, models.EntryTypeAudit)
+	return defaultHub.Audit(action, actor, resource, resourceID, attrs)
 }
 
 // --- Lifecycle ---
 
-func Close() error {
-	c := getClient()
-	if c == nil {
-		return nil
-	}
-	return c.Close()
-}
-
-func Flush(timeout time.Duration) error {
-	c := getClient()
-	if c == nil {
-		return nil
-	}
-	return c.Flush(timeout)
-}
-
-func Stats() client.ClientStats {
-	c := getClient()
-	if c == nil {
-		return client.ClientStats{}
-	}
-	return c.GetStats()
-}
+func Close() error                      { return defaultHub.Close() }
+func Flush(timeout time.Duration) error { return defaultHub.Flush(timeout) }
+func Stats() client.ClientStats         { return defaultHub.Stats() }
+func GetStats() client.ClientStats      { return Stats() }
 
-func GetStats() client.ClientStats { return Stats() }
+// RedactedCount returns how many values the default hub's scrubber has
+// redacted (see Options.Scrub).
+func RedactedCount() uint64 { return defaultHub.RedactedCount() }
 
 // --- Helpers ---
 
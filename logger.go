@@ -0,0 +1,209 @@
+package logflux
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// Logger is a named logger with optional bound attributes. Its name is sent
+// in the payload's logger field and selects the per-logger level set with
+// Options.Levels, SetLevel or LOGFLUX_LEVELS. Bound attributes are added to
+// every log, error, event and metric it sends.
+//
+// A Logger is immutable: With returns a new Logger and never changes the
+// receiver, so loggers can be shared between goroutines and created per
+// request. They read their hub's settings on every call, so level changes
+// apply immediately.
+//
+// Usage:
+//
+//	var dbLog = logflux.NamedLogger("db")
+//
+//	dbLog.Debugf("query took %s", elapsed) // dropped unless db is at debug
+//
+//	reqLog := logflux.With(logflux.Fields{"request_id": id})
+//	reqLog.With(logflux.Fields{"user.id": uid}).Info("checkout started")
+//	reqLog.WithAttrs(logflux.NewAttributes("cart.items", 3)).Info("cart loaded")
+type Logger struct {
+	hub   *Hub
+	name  string
+	attrs Attributes // never mutated after construction
+}
+
+// NamedLogger returns a logger with the given name on the default hub.
+func NamedLogger(name string) *Logger { return defaultHub.NamedLogger(name) }
+
+// With returns a logger on the default hub with fields bound to every entry.
+func With(fields Fields) *Logger { return defaultHub.With(fields) }
+
+// WithAttrs returns a logger on the default hub with typed attributes bound
+// to every entry.
+func WithAttrs(attrs Attributes) *Logger { return defaultHub.WithAttrs(attrs) }
+
+// SetLevel sets the minimum level for a named logger on the default hub.
+// See Hub.SetLevel.
+func SetLevel(name string, level int) { defaultHub.SetLevel(name, level) }
+
+// NamedLogger returns a logger with the given name on this hub.
+func (h *Hub) NamedLogger(name string) *Logger {
+	return &Logger{hub: h, name: name}
+}
+
+// With returns a logger on this hub with fields bound to every entry.
+func (h *Hub) With(fields Fields) *Logger {
+	return h.WithAttrs(payload.AttributesFromStrings(fields))
+}
+
+// WithAttrs returns a logger on this hub with typed attributes bound to
+// every entry.
+func (h *Hub) WithAttrs(attrs Attributes) *Logger {
+	return &Logger{hub: h, attrs: mergeAttrs(nil, attrs)}
+}
+
+// With returns a child logger with the same name and fields added to the
+// bound attributes. Keys in fields replace bound keys of the same name.
+// The receiver is not modified.
+func (l *Logger) With(fields Fields) *Logger {
+	return l.WithAttrs(payload.AttributesFromStrings(fields))
+}
+
+// WithAttrs is With for typed attributes.
+func (l *Logger) WithAttrs(attrs Attributes) *Logger {
+	return &Logger{hub: l.hub, name: l.name, attrs: mergeAttrs(l.attrs, attrs)}
+}
+
+// Name returns the logger's name.
+func (l *Logger) Name() string { return l.name }
+
+// Enabled reports whether a log at level would be sent, so callers can skip
+// building expensive messages.
+func (l *Logger) Enabled(level int) bool { return l.hub.enabled(l.name, level) }
+
+func (l *Logger) Debug(message string) error    { return l.Log(models.LogLevelDebug, message, nil) }
+func (l *Logger) Info(message string) error     { return l.Log(models.LogLevelInfo, message, nil) }
+func (l *Logger) Notice(message string) error   { return l.Log(models.LogLevelNotice, message, nil) }
+func (l *Logger) Warn(message string) error     { return l.Log(models.LogLevelWarning, message, nil) }
+func (l *Logger) Warning(message string) error  { return l.Log(models.LogLevelWarning, message, nil) }
+func (l *Logger) Error(message string) error    { return l.Log(models.LogLevelError, message, nil) }
+func (l *Logger) Critical(message string) error { return l.Log(models.LogLevelCritical, message, nil) }
+func (l *Logger) Alert(message string) error    { return l.Log(models.LogLevelAlert, message, nil) }
+func (l *Logger) Emergency(message string) error {
+	return l.Log(models.LogLevelEmergency, message, nil)
+}
+
+// Fatal sends a critical log, flushes and exits with status 1; see Hub.Fatal.
+func (l *Logger) Fatal(message string) error {
+	err := l.Log(models.LogLevelCritical, message, nil)
+	l.hub.exitFatal()
+	return err
+}
+
+// Debugf sends a formatted debug log.
+func (l *Logger) Debugf(format string, args ...interface{}) error {
+	return l.logf(models.LogLevelDebug, format, args)
+}
+
+// Infof sends a formatted info log.
+func (l *Logger) Infof(format string, args ...interface{}) error {
+	return l.logf(models.LogLevelInfo, format, args)
+}
+
+// Warnf sends a formatted warning log.
+func (l *Logger) Warnf(format string, args ...interface{}) error {
+	return l.logf(models.LogLevelWarning, format, args)
+}
+
+// Errorf sends a formatted error log.
+func (l *Logger) Errorf(format string, args ...interface{}) error {
+	return l.logf(models.LogLevelError, format, args)
+}
+
+func (l *Logger) logf(level int, format string, args []interface{}) error {
+	if !l.Enabled(level) {
+		return nil
+	}
+	return l.hub.emit(l.name, level, fmt.Sprintf(format, args...), mergeAttrs(l.attrs, nil))
+}
+
+// Log sends a log entry (type 1) with the given level, merging attrs over
+// the bound attributes.
+func (l *Logger) Log(level int, message string, attrs Fields) error {
+	if !l.Enabled(level) {
+		return nil
+	}
+	return l.LogAttrs(level, message, payload.AttributesFromStrings(attrs))
+}
+
+// LogAttrs sends a log entry (type 1) with typed attributes merged over the
+// bound attributes.
+func (l *Logger) LogAttrs(level int, message string, attrs Attributes) error {
+	if !l.Enabled(level) {
+		return nil
+	}
+	return l.hub.emit(l.name, level, message, mergeAttrs(l.attrs, attrs))
+}
+
+// CaptureError captures err with stack trace and breadcrumbs, tagged with
+// the logger's name and bound attributes.
+func (l *Logger) CaptureError(err error) error {
+	return l.CaptureErrorWithAttrs(err, nil)
+}
+
+// CaptureErrorWithAttrs is CaptureError with extra attributes.
+func (l *Logger) CaptureErrorWithAttrs(err error, attrs Fields) error {
+	return l.hub.captureError(l.name, err, mergeAttrs(l.attrs, payload.AttributesFromStrings(attrs)))
+}
+
+// Event sends an event entry (type 4) with the bound attributes.
+func (l *Logger) Event(event string, attrs Fields) error {
+	return l.EventAttrs(event, payload.AttributesFromStrings(attrs))
+}
+
+// EventAttrs sends an event entry (type 4) with typed attributes merged over
+// the bound attributes.
+func (l *Logger) EventAttrs(event string, attrs Attributes) error {
+	return l.hub.EventAttrs(event, mergeAttrs(l.attrs, attrs))
+}
+
+// Metric sends a metric entry (type 2) with the bound attributes.
+func (l *Logger) Metric(name string, value float64, kind string, attrs Fields) error {
+	return l.hub.metric(name, value, kind, "", mergeAttrs(l.attrs, payload.AttributesFromStrings(attrs)))
+}
+
+// Counter sends a counter metric (type 2, kind=counter).
+func (l *Logger) Counter(name string, value float64, attrs Fields) error {
+	return l.Metric(name, value, "counter", attrs)
+}
+
+// Gauge sends a gauge metric (type 2, kind=gauge).
+func (l *Logger) Gauge(name string, value float64, attrs Fields) error {
+	return l.Metric(name, value, "gauge", attrs)
+}
+
+// Histogram records value in a histogram metric (type 2, kind=histogram)
+// with the bound attributes.
+func (l *Logger) Histogram(name string, value float64, attrs Fields) error {
+	return l.Metric(name, value, "histogram", attrs)
+}
+
+// Timer starts timing an operation; ObserveDuration records the elapsed
+// milliseconds in the histogram name with the bound attributes.
+func (l *Logger) Timer(name string) *Stopwatch {
+	return &Stopwatch{hub: l.hub, name: name, attrs: l.attrs, start: time.Now()}
+}
+
+// mergeAttrs returns a new map holding bound overlaid with attrs, or nil if
+// both are empty. Neither input is modified, and the result is never shared,
+// so BeforeSend hooks may change it freely.
+func mergeAttrs(bound, attrs Attributes) Attributes {
+	if len(bound) == 0 && len(attrs) == 0 {
+		return nil
+	}
+	merged := make(Attributes, len(bound)+len(attrs))
+	merged.Merge(bound)
+	merged.Merge(attrs)
+	return merged
+}
@@ -0,0 +1,144 @@
+package logflux
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+func TestHub_MinLevel(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.MinLevel = models.LogLevelInfo
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	_ = hub.Debug("dropped")
+	_ = hub.Debugf("dropped %d", 1)
+	_ = hub.Info("sent")
+	_ = hub.Errorf("sent %d", 2)
+	if got := hub.Stats().EntriesQueued; got != 2 {
+		t.Errorf("queued %d entries, want 2", got)
+	}
+}
+
+func TestLogger_LevelOverrides(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.MinLevel = models.LogLevelWarning
+	opts.Levels = map[string]int{"db": models.LogLevelDebug}
+	var loggers []string
+	opts.BeforeSendLog = func(l *payload.Log) *payload.Log {
+		loggers = append(loggers, l.Logger)
+		return l
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	db, pool, http := hub.NamedLogger("db"), hub.NamedLogger("db.pool"), hub.NamedLogger("http")
+	_ = db.Debug("sent")
+	_ = pool.Debugf("sent via %s", "db override")
+	_ = http.Info("dropped")
+	_ = http.Warn("sent")
+	want := []string{"db", "db.pool", "http"}
+	if len(loggers) != len(want) {
+		t.Fatalf("sent loggers = %v, want %v", loggers, want)
+	}
+	for i := range want {
+		if loggers[i] != want[i] {
+			t.Fatalf("sent loggers = %v, want %v", loggers, want)
+		}
+	}
+
+	// Runtime changes apply to existing loggers.
+	hub.SetLevel("http", models.LogLevelInfo)
+	hub.SetLevel("db", 0)
+	if !http.Enabled(models.LogLevelInfo) {
+		t.Error("http info should be enabled after SetLevel")
+	}
+	if db.Enabled(models.LogLevelDebug) || pool.Enabled(models.LogLevelInfo) {
+		t.Error("db should follow MinLevel after its override is removed")
+	}
+}
+
+func TestLogger_FilteredDoesNotAllocate(t *testing.T) {
+	hub := &Hub{context: payload.NewContext(), minLevel: models.LogLevelInfo}
+	hub.SetLevel("http", models.LogLevelWarning)
+	l := hub.NamedLogger("db.pool")
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = l.Debug("filtered")
+		_ = l.Debugf("filtered")
+		_ = hub.Debug("filtered")
+	})
+	if allocs != 0 {
+		t.Errorf("filtered logs allocated %v times per run, want 0", allocs)
+	}
+}
+
+func TestLogger_With(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	var logs []*payload.Log
+	var errs []*payload.ErrorPayload
+	var events []*payload.Event
+	var metrics []*payload.Metric
+	opts.BeforeSendLog = func(l *payload.Log) *payload.Log {
+		l.Attributes["mutated"] = "by hook"
+		logs = append(logs, l)
+		return l
+	}
+	opts.BeforeSendError = func(e *payload.ErrorPayload) *payload.ErrorPayload { errs = append(errs, e); return e }
+	opts.BeforeSendEvent = func(e *payload.Event) *payload.Event { events = append(events, e); return e }
+	opts.BeforeSendMetric = func(m *payload.Metric) *payload.Metric { metrics = append(metrics, m); return m }
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	fields := Fields{"request_id": "r1"}
+	parent := hub.NamedLogger("http").With(fields)
+	child := parent.With(Fields{"user.id": "u1", "request_id": "r2"})
+	fields["request_id"] = "changed after With"
+
+	_ = parent.Info("parent")
+	_ = child.Infof("child %d", 1)
+	_ = child.CaptureError(errors.New("boom"))
+	_ = child.Event("checkout", Fields{"step": "pay"})
+	_ = child.Counter("orders", 1, nil)
+
+	if len(logs) != 2 || len(errs) != 1 || len(events) != 1 || len(metrics) != 1 {
+		t.Fatalf("sent logs=%d errors=%d events=%d metrics=%d", len(logs), len(errs), len(events), len(metrics))
+	}
+	if got := logs[0].Attributes; got["request_id"] != "r1" || got["user.id"] != nil || logs[0].Logger != "http" {
+		t.Errorf("parent log = %q %v", logs[0].Logger, got)
+	}
+	if got := logs[1].Attributes; got["request_id"] != "r2" || got["user.id"] != "u1" || logs[1].Message != "child 1" {
+		t.Errorf("child log attributes = %v", got)
+	}
+	if errs[0].Logger != "http" || errs[0].Attributes["user.id"] != "u1" {
+		t.Errorf("error = %q %v", errs[0].Logger, errs[0].Attributes)
+	}
+	if got := events[0].Attributes; got["step"] != "pay" || got["request_id"] != "r2" {
+		t.Errorf("event attributes = %v", got)
+	}
+	if got := metrics[0].Attributes; got["user.id"] != "u1" {
+		t.Errorf("metric attributes = %v", got)
+	}
+
+	// Neither the caller's map nor the bound attributes were mutated.
+	if fields["mutated"] != "" || parent.attrs["mutated"] != nil || child.attrs["mutated"] != nil {
+		t.Error("hook mutation leaked into shared maps")
+	}
+	if parent.attrs["request_id"] != "r1" || len(parent.attrs) != 1 {
+		t.Errorf("parent attributes changed: %v", parent.attrs)
+	}
+}
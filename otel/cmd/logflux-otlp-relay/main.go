@@ -0,0 +1,82 @@
+// Command logflux-otlp-relay is a local OTLP/HTTP relay: it receives
+// traces, logs and metrics from OTLP senders on the host, encrypts them and
+// forwards them to LogFlux.
+//
+// It is configured like the SDK, from LOGFLUX_* environment variables or a
+// config file:
+//
+//	LOGFLUX_API_KEY=eu-lf_... logflux-otlp-relay -listen 127.0.0.1:4318
+//
+// Senders use the OTLP HTTP protocol with the endpoint
+// http://127.0.0.1:4318. SDK statistics are served on /stats.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	logflux "github.com/logflux-io/logflux-go-sdk/v3"
+	"github.com/logflux-io/logflux-go-sdk/v3/otel/otlpreceiver"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/config"
+)
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:4318", "address to receive OTLP/HTTP on")
+	configFile := flag.String("config", "", "YAML, JSON or TOML config file (default: LOGFLUX_* environment variables only)")
+	maxBody := flag.Int64("max-body-size", 0, "largest accepted request body in bytes after decompression (default: 16 MiB)")
+	drain := flag.Duration("shutdown-timeout", 10*time.Second, "how long to drain queued entries on shutdown")
+	flag.Parse()
+
+	if err := run(*listen, *configFile, *maxBody, *drain); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(listen, configFile string, maxBody int64, drain time.Duration) error {
+	var cfg *config.Config
+	var err error
+	if configFile != "" {
+		cfg, err = config.LoadConfigFile(configFile)
+	} else {
+		cfg, err = config.LoadConfigFromEnv()
+	}
+	if err != nil {
+		return err
+	}
+	hub, err := logflux.NewHub(logflux.OptionsFromConfig(cfg))
+	if err != nil {
+		return err
+	}
+	defer hub.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/v1/", otlpreceiver.NewHandler(hub.Client(), otlpreceiver.Options{MaxBodySize: maxBody}))
+	mux.Handle("/stats", hub.StatsHandler())
+	srv := &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	log.Printf("receiving OTLP/HTTP on %s", listen)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+	// Stop accepting requests, then send what they queued.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drain)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("stopping server: %v", err)
+	}
+	return hub.Flush(drain)
+}
@@ -0,0 +1,119 @@
+// Package convert holds the OpenTelemetry to LogFlux mappings shared by the
+// SDK exporters and the OTLP receiver.
+package convert
+
+import (
+	"encoding/base64"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// ServiceName is the resource attribute used as the payload source.
+const ServiceName = "service.name"
+
+// Scope attribute keys, as used by non-OTLP exporters.
+const (
+	ScopeName    = "otel.scope.name"
+	ScopeVersion = "otel.scope.version"
+)
+
+// Level maps an OpenTelemetry severity number (1-24) to a LogFlux level.
+// Unset severities map to info.
+func Level(severity int) int {
+	switch {
+	case severity <= 0:
+		return models.LogLevelInfo
+	case severity <= 8: // TRACE, DEBUG
+		return models.LogLevelDebug
+	case severity <= 12: // INFO
+		return models.LogLevelInfo
+	case severity <= 16: // WARN
+		return models.LogLevelWarning
+	case severity <= 20: // ERROR
+		return models.LogLevelError
+	default: // FATAL
+		return models.LogLevelCritical
+	}
+}
+
+// Operation derives a span operation such as "http.server" or "db" from
+// the span kind and semantic-convention attributes, falling back to the
+// kind.
+func Operation(kind string, attrs payload.Attributes) string {
+	has := func(keys ...string) bool {
+		for _, k := range keys {
+			if _, ok := attrs[k]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case has("http.request.method", "http.method"):
+		return "http." + kind
+	case has("db.system", "db.system.name"):
+		return "db"
+	case has("rpc.system"):
+		return "rpc." + kind
+	case has("messaging.system"):
+		return "messaging." + kind
+	}
+	return kind
+}
+
+// Value converts a decoded OpenTelemetry attribute value to an attribute
+// value: bytes become base64 strings, maps are kept for Attributes.Set to
+// flatten, and everything else goes through payload.AttributeValue.
+func Value(v any) any {
+	switch v := v.(type) {
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case map[string]any:
+		return v
+	}
+	return payload.AttributeValue(v)
+}
+
+// Source returns the service name in attrs, or "".
+func Source(attrs payload.Attributes) string {
+	if s, ok := attrs[ServiceName].(string); ok {
+		return s
+	}
+	return ""
+}
+
+// Merge returns a new map holding every layer, later layers winning. It
+// returns nil if all layers are empty.
+func Merge(layers ...payload.Attributes) payload.Attributes {
+	var out payload.Attributes
+	for _, l := range layers {
+		if len(l) == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(payload.Attributes, len(l))
+		}
+		out.Merge(l)
+	}
+	return out
+}
+
+// Timestamp formats t for a payload, or returns "" for the zero time.
+func Timestamp(t time.Time) string {
+	if t.IsZero() || t.UnixNano() == 0 {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// Send marshals p and queues it on c as an entry of entryType.
+func Send(c *client.ResilientClient, p any, level, entryType int) error {
+	data, err := payload.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return c.SendLogWithEntryType(string(data), level, entryType)
+}
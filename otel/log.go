@@ -0,0 +1,126 @@
+package otel
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/otel/internal/convert"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// LogExporter is an sdklog.Exporter that sends log records as log entries
+// (type 1).
+type LogExporter struct {
+	exporter
+}
+
+var _ sdklog.Exporter = (*LogExporter)(nil)
+
+// NewLogExporter returns a log exporter sending through c.
+func NewLogExporter(c *client.ResilientClient) *LogExporter {
+	return &LogExporter{exporter{client: c}}
+}
+
+// Export queues records on the client.
+func (e *LogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	if e.stopped.Load() {
+		return nil
+	}
+	var errs []error
+	for i := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p := logPayload(&records[i])
+		if err := convert.Send(e.client, p, p.Level, models.EntryTypeLog); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// logPayload maps a log record. The body becomes the message, the
+// instrumentation scope the logger name, and the severity text, if any, the
+// attribute otel.severity_text.
+func logPayload(r *sdklog.Record) *payload.Log {
+	res := recordResource(r)
+	var recAttrs payload.Attributes
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		if recAttrs == nil {
+			recAttrs = make(payload.Attributes, r.AttributesLen())
+		}
+		recAttrs.Set(kv.Key, logValue(kv.Value))
+		return true
+	})
+	if text := r.SeverityText(); text != "" {
+		if recAttrs == nil {
+			recAttrs = make(payload.Attributes, 1)
+		}
+		recAttrs["otel.severity_text"] = text
+	}
+
+	body := r.Body()
+	message := body.String()
+	if body.Kind() == log.KindString {
+		message = body.AsString()
+	}
+	p := payload.NewLog(convert.Source(res), message, convert.Level(int(r.Severity())))
+	p.Logger = r.InstrumentationScope().Name
+	if id := r.TraceID(); id.IsValid() {
+		p.TraceID = id.String()
+	}
+	if id := r.SpanID(); id.IsValid() {
+		p.SpanID = id.String()
+	}
+	ts := r.Timestamp()
+	if ts.IsZero() {
+		ts = r.ObservedTimestamp()
+	}
+	if !ts.IsZero() {
+		p.SetTimestamp(ts)
+	}
+	if attrs := convert.Merge(res, recAttrs); attrs != nil {
+		p.SetAttrs(attrs)
+	}
+	return p
+}
+
+func recordResource(r *sdklog.Record) payload.Attributes {
+	res := r.Resource()
+	return attributes(res.Attributes())
+}
+
+// logValue converts a log attribute value.
+func logValue(v log.Value) any {
+	switch v.Kind() {
+	case log.KindBool:
+		return v.AsBool()
+	case log.KindInt64:
+		return v.AsInt64()
+	case log.KindFloat64:
+		return v.AsFloat64()
+	case log.KindString:
+		return v.AsString()
+	case log.KindBytes:
+		return convert.Value(v.AsBytes())
+	case log.KindSlice:
+		items := v.AsSlice()
+		out := make([]any, len(items))
+		for i, item := range items {
+			out[i] = logValue(item)
+		}
+		return convert.Value(out)
+	case log.KindMap:
+		m := make(map[string]any)
+		for _, kv := range v.AsMap() {
+			m[kv.Key] = logValue(kv.Value)
+		}
+		return m
+	}
+	return nil
+}
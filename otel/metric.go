@@ -0,0 +1,198 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/otel/internal/convert"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// MetricExporter is an sdkmetric.Exporter that sends data points as metric
+// entries (type 2). It asks for delta temporality on counters and
+// histograms, so that they carry the increase since the previous export
+// like the SDK's own counters, and cumulative temporality on up-down
+// counters and gauges, which are sent as gauges.
+type MetricExporter struct {
+	exporter
+}
+
+var _ sdkmetric.Exporter = (*MetricExporter)(nil)
+
+// NewMetricExporter returns a metric exporter sending through c.
+func NewMetricExporter(c *client.ResilientClient) *MetricExporter {
+	return &MetricExporter{exporter{client: c}}
+}
+
+// Temporality implements sdkmetric.Exporter.
+func (e *MetricExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	switch kind {
+	case sdkmetric.InstrumentKindCounter, sdkmetric.InstrumentKindObservableCounter, sdkmetric.InstrumentKindHistogram:
+		return metricdata.DeltaTemporality
+	}
+	return metricdata.CumulativeTemporality
+}
+
+// Aggregation implements sdkmetric.Exporter with the SDK's defaults.
+func (e *MetricExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+// Export queues one metric entry per data point on the client.
+func (e *MetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	if e.stopped.Load() || rm == nil {
+		return nil
+	}
+	var errs []error
+	for _, m := range metricPayloads(rm) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := convert.Send(e.client, m, models.LogLevelInfo, models.EntryTypeMetric); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// metricPayloads maps every data point in rm. Monotonic delta sums become
+// counters and other sums gauges; histograms carry their buckets and a
+// summary whose Value is the mean.
+func metricPayloads(rm *metricdata.ResourceMetrics) []*payload.Metric {
+	res := resourceAttributes(rm.Resource)
+	source := convert.Source(res)
+	var out []*payload.Metric
+	for _, sm := range rm.ScopeMetrics {
+		base := convert.Merge(res, scopeAttributes(sm.Scope))
+		for _, m := range sm.Metrics {
+			pts := &points{source: source, name: m.Name, unit: m.Unit, base: base}
+			switch d := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				sumPoints(pts, d)
+			case metricdata.Sum[float64]:
+				sumPoints(pts, d)
+			case metricdata.Gauge[int64]:
+				gaugePoints(pts, d)
+			case metricdata.Gauge[float64]:
+				gaugePoints(pts, d)
+			case metricdata.Histogram[int64]:
+				histogramPoints(pts, d)
+			case metricdata.Histogram[float64]:
+				histogramPoints(pts, d)
+			case metricdata.ExponentialHistogram[int64]:
+				exponentialPoints(pts, d)
+			case metricdata.ExponentialHistogram[float64]:
+				exponentialPoints(pts, d)
+			case metricdata.Summary:
+				summaryPoints(pts, d)
+			}
+			out = append(out, pts.out...)
+		}
+	}
+	return out
+}
+
+// points collects the payloads of one metric.
+type points struct {
+	source, name, unit string
+	base               payload.Attributes
+	out                []*payload.Metric
+}
+
+// add finishes m with the metric's unit, the data point's time and the
+// merged attributes.
+func (p *points) add(m *payload.Metric, set attribute.Set, t time.Time) {
+	m.Unit = p.unit
+	if !t.IsZero() {
+		m.SetTimestamp(t)
+	}
+	if attrs := convert.Merge(p.base, attributes(set.ToSlice())); attrs != nil {
+		m.SetAttrs(attrs)
+	}
+	p.out = append(p.out, m)
+}
+
+func sumPoints[N int64 | float64](p *points, d metricdata.Sum[N]) {
+	for _, dp := range d.DataPoints {
+		m := payload.NewGauge(p.source, p.name, float64(dp.Value), p.unit)
+		if d.IsMonotonic && d.Temporality == metricdata.DeltaTemporality {
+			m.Kind = "counter"
+		}
+		p.add(m, dp.Attributes, dp.Time)
+	}
+}
+
+func gaugePoints[N int64 | float64](p *points, d metricdata.Gauge[N]) {
+	for _, dp := range d.DataPoints {
+		p.add(payload.NewGauge(p.source, p.name, float64(dp.Value), p.unit), dp.Attributes, dp.Time)
+	}
+}
+
+func histogramPoints[N int64 | float64](p *points, d metricdata.Histogram[N]) {
+	for _, dp := range d.DataPoints {
+		m := distribution(p, dp.Count, float64(dp.Sum), dp.Min, dp.Max)
+		if m == nil {
+			continue
+		}
+		m.Kind = "histogram"
+		m.Histogram = &payload.Histogram{Bounds: dp.Bounds, Counts: dp.BucketCounts}
+		p.add(m, dp.Attributes, dp.Time)
+	}
+}
+
+// exponentialPoints sends exponential histograms as summaries; their
+// buckets are not kept.
+func exponentialPoints[N int64 | float64](p *points, d metricdata.ExponentialHistogram[N]) {
+	for _, dp := range d.DataPoints {
+		if m := distribution(p, dp.Count, float64(dp.Sum), dp.Min, dp.Max); m != nil {
+			p.add(m, dp.Attributes, dp.Time)
+		}
+	}
+}
+
+func summaryPoints(p *points, d metricdata.Summary) {
+	for _, dp := range d.DataPoints {
+		m := distribution(p, dp.Count, dp.Sum, metricdata.Extrema[float64]{}, metricdata.Extrema[float64]{})
+		if m == nil {
+			continue
+		}
+		m.Kind = "summary"
+		for _, qv := range dp.QuantileValues {
+			if m.Summary.Quantiles == nil {
+				m.Summary.Quantiles = make(map[string]float64, len(dp.QuantileValues))
+			}
+			m.Summary.Quantiles[payload.QuantileKey(qv.Quantile)] = qv.Value
+			switch qv.Quantile {
+			case 0:
+				m.Summary.Min = qv.Value
+			case 1:
+				m.Summary.Max = qv.Value
+			}
+		}
+		p.add(m, dp.Attributes, dp.Time)
+	}
+}
+
+// distribution returns a distribution metric whose Value is the mean, or
+// nil for an empty data point.
+func distribution[N int64 | float64](p *points, count uint64, sum float64, lo, hi metricdata.Extrema[N]) *payload.Metric {
+	if count == 0 {
+		return nil
+	}
+	m := payload.NewDistribution(p.source, p.name, sum/float64(count), p.unit)
+	m.Summary = &payload.Summary{Count: count, Sum: sum}
+	if v, ok := lo.Value(); ok {
+		m.Summary.Min = float64(v)
+	}
+	if v, ok := hi.Value(); ok {
+		m.Summary.Max = float64(v)
+	}
+	return m
+}
@@ -0,0 +1,105 @@
+// Package otel exports OpenTelemetry traces, logs and metrics to LogFlux.
+// The exporters map spans, log records and metric data points onto v2
+// payloads and queue them on a client.ResilientClient, which encrypts,
+// batches and retries them like any other entry:
+//
+//	c, err := client.NewResilientClientWithHandshake(cfg)
+//	if err != nil { ... }
+//	defer c.Close()
+//
+//	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(logfluxotel.NewSpanExporter(c)))
+//	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewBatchProcessor(logfluxotel.NewLogExporter(c))))
+//	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(logfluxotel.NewMetricExporter(c))))
+//
+// Resource attributes are merged into every payload's attributes, and the
+// resource's service.name becomes the payload source. The exporters do not
+// own the client: Shutdown flushes it but does not close it.
+//
+// It lives in its own module so that the SDK itself does not depend on
+// OpenTelemetry.
+package otel
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/otel/internal/convert"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// defaultFlushTimeout bounds ForceFlush and Shutdown when the context has
+// no deadline.
+const defaultFlushTimeout = 5 * time.Second
+
+// exporter holds the client and shutdown state shared by the exporters.
+type exporter struct {
+	client  *client.ResilientClient
+	stopped atomic.Bool
+}
+
+// ForceFlush waits until the entries queued on the client are sent, or the
+// context is done.
+func (e *exporter) ForceFlush(ctx context.Context) error {
+	if e.stopped.Load() {
+		return nil
+	}
+	return e.flush(ctx)
+}
+
+// Shutdown flushes the client and stops the exporter; later calls do
+// nothing. The client stays open.
+func (e *exporter) Shutdown(ctx context.Context) error {
+	if e.stopped.Swap(true) {
+		return nil
+	}
+	return e.flush(ctx)
+}
+
+func (e *exporter) flush(ctx context.Context) error {
+	timeout := defaultFlushTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return e.client.Flush(timeout)
+}
+
+// attributes converts OpenTelemetry attributes.
+func attributes(kvs []attribute.KeyValue) payload.Attributes {
+	if len(kvs) == 0 {
+		return nil
+	}
+	a := make(payload.Attributes, len(kvs))
+	for _, kv := range kvs {
+		a.Set(string(kv.Key), convert.Value(kv.Value.AsInterface()))
+	}
+	return a
+}
+
+// resourceAttributes converts the attributes of res, which may be nil.
+func resourceAttributes(res *resource.Resource) payload.Attributes {
+	if res == nil {
+		return nil
+	}
+	return attributes(res.Attributes())
+}
+
+// scopeAttributes describes the instrumentation scope.
+func scopeAttributes(s instrumentation.Scope) payload.Attributes {
+	if s.Name == "" {
+		return nil
+	}
+	a := payload.Attributes{convert.ScopeName: s.Name}
+	if s.Version != "" {
+		a[convert.ScopeVersion] = s.Version
+	}
+	return a
+}
@@ -0,0 +1,207 @@
+package otel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/log/logtest"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+)
+
+var (
+	testTraceID = trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	testSpanID  = trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+	testParent  = trace.SpanID{0x53, 0x99, 0x5c, 0x3f, 0x42, 0xcd, 0x8a, 0xd8}
+	testRes     = resource.NewSchemaless(attribute.String("service.name", "checkout"), attribute.String("deployment.environment", "prod"))
+	testScope   = instrumentation.Scope{Name: "net/http", Version: "1.0"}
+)
+
+func TestSpanPayload(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	stub := tracetest.SpanStub{
+		Name:                 "GET /cart",
+		SpanContext:          trace.NewSpanContext(trace.SpanContextConfig{TraceID: testTraceID, SpanID: testSpanID}),
+		Parent:               trace.NewSpanContext(trace.SpanContextConfig{TraceID: testTraceID, SpanID: testParent}),
+		SpanKind:             trace.SpanKindServer,
+		StartTime:            start,
+		EndTime:              start.Add(150 * time.Millisecond),
+		Attributes:           []attribute.KeyValue{attribute.String("http.request.method", "GET"), attribute.Int("http.response.status_code", 500)},
+		Events:               []sdktrace.Event{{Name: "retry", Time: start.Add(time.Millisecond), Attributes: []attribute.KeyValue{attribute.Int("attempt", 2)}}},
+		Links:                []sdktrace.Link{{SpanContext: trace.NewSpanContext(trace.SpanContextConfig{TraceID: testTraceID, SpanID: testParent})}},
+		Status:               sdktrace.Status{Code: codes.Error, Description: "upstream failed"},
+		Resource:             testRes,
+		InstrumentationScope: testScope,
+	}
+	p := spanPayload(stub.Snapshot())
+
+	if p.TraceID != testTraceID.String() || p.SpanID != testSpanID.String() || p.ParentSpanID != testParent.String() {
+		t.Errorf("ids = %s/%s/%s", p.TraceID, p.SpanID, p.ParentSpanID)
+	}
+	if p.Source != "checkout" {
+		t.Errorf("source = %q, want checkout", p.Source)
+	}
+	if p.Operation != "http.server" || p.Kind != "server" || p.Name != "GET /cart" {
+		t.Errorf("operation/kind/name = %q/%q/%q", p.Operation, p.Kind, p.Name)
+	}
+	if p.Status != "error" || p.StatusMessage != "upstream failed" {
+		t.Errorf("status = %q %q", p.Status, p.StatusMessage)
+	}
+	if p.DurationMs != 150 {
+		t.Errorf("duration = %d, want 150", p.DurationMs)
+	}
+	if len(p.Events) != 1 || p.Events[0].Name != "retry" || fmt.Sprint(p.Events[0].Attributes["attempt"]) != "2" {
+		t.Errorf("events = %+v", p.Events)
+	}
+	if len(p.Links) != 1 || p.Links[0].SpanID != testParent.String() {
+		t.Errorf("links = %+v", p.Links)
+	}
+	for k, want := range map[string]string{
+		"deployment.environment":    "prod",
+		"otel.scope.name":           "net/http",
+		"otel.scope.version":        "1.0",
+		"http.response.status_code": "500",
+	} {
+		if got := fmt.Sprint(p.Attributes[k]); got != want {
+			t.Errorf("attribute %s = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestSpanPayloadUnsetStatusIsOK(t *testing.T) {
+	stub := tracetest.SpanStub{
+		Name:        "work",
+		SpanContext: trace.NewSpanContext(trace.SpanContextConfig{TraceID: testTraceID, SpanID: testSpanID}),
+		SpanKind:    trace.SpanKindInternal,
+	}
+	p := spanPayload(stub.Snapshot())
+	if p.Status != "ok" || p.ParentSpanID != "" || p.Operation != "internal" {
+		t.Errorf("status/parent/operation = %q/%q/%q", p.Status, p.ParentSpanID, p.Operation)
+	}
+}
+
+func TestLogPayload(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := logtest.RecordFactory{
+		Timestamp:    ts,
+		Severity:     log.SeverityWarn,
+		SeverityText: "WARN",
+		Body:         log.StringValue("disk almost full"),
+		Attributes: []log.KeyValue{
+			log.Int("free_mb", 12),
+			log.Map("disk", log.String("mount", "/var")),
+			log.Bytes("raw", []byte{1, 2}),
+		},
+		TraceID:              testTraceID,
+		SpanID:               testSpanID,
+		Resource:             testRes,
+		InstrumentationScope: &testScope,
+	}.NewRecord()
+	p := logPayload(&r)
+
+	if p.Message != "disk almost full" || p.Level != models.LogLevelWarning {
+		t.Errorf("message/level = %q/%d", p.Message, p.Level)
+	}
+	if p.Logger != "net/http" || p.Source != "checkout" {
+		t.Errorf("logger/source = %q/%q", p.Logger, p.Source)
+	}
+	if p.TraceID != testTraceID.String() || p.SpanID != testSpanID.String() {
+		t.Errorf("ids = %s/%s", p.TraceID, p.SpanID)
+	}
+	if p.Ts != ts.Format(time.RFC3339Nano) {
+		t.Errorf("timestamp = %q", p.Ts)
+	}
+	for k, want := range map[string]string{
+		"free_mb":                "12",
+		"disk.mount":             "/var",
+		"raw":                    "AQI=",
+		"otel.severity_text":     "WARN",
+		"deployment.environment": "prod",
+	} {
+		if got := fmt.Sprint(p.Attributes[k]); got != want {
+			t.Errorf("attribute %s = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestLogPayloadFallsBackToObservedTime(t *testing.T) {
+	observed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := logtest.RecordFactory{ObservedTimestamp: observed, Body: log.IntValue(7)}.NewRecord()
+	p := logPayload(&r)
+	if p.Ts != observed.Format(time.RFC3339Nano) || p.Message != "7" || p.Level != models.LogLevelInfo {
+		t.Errorf("timestamp/message/level = %q/%q/%d", p.Ts, p.Message, p.Level)
+	}
+}
+
+func TestMetricPayloads(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	attrs := attribute.NewSet(attribute.String("route", "/cart"))
+	rm := &metricdata.ResourceMetrics{
+		Resource: testRes,
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Scope: testScope,
+			Metrics: []metricdata.Metrics{
+				{Name: "requests", Unit: "{request}", Data: metricdata.Sum[int64]{
+					Temporality: metricdata.DeltaTemporality, IsMonotonic: true,
+					DataPoints: []metricdata.DataPoint[int64]{{Attributes: attrs, Time: now, Value: 3}},
+				}},
+				{Name: "in_flight", Data: metricdata.Sum[int64]{
+					Temporality: metricdata.CumulativeTemporality,
+					DataPoints:  []metricdata.DataPoint[int64]{{Value: 2}},
+				}},
+				{Name: "temperature", Unit: "Cel", Data: metricdata.Gauge[float64]{
+					DataPoints: []metricdata.DataPoint[float64]{{Value: 21.5}},
+				}},
+				{Name: "latency", Unit: "ms", Data: metricdata.Histogram[float64]{
+					Temporality: metricdata.DeltaTemporality,
+					DataPoints: []metricdata.HistogramDataPoint[float64]{
+						{Count: 4, Sum: 100, Bounds: []float64{10, 50}, BucketCounts: []uint64{1, 2, 1},
+							Min: metricdata.NewExtrema(5.0), Max: metricdata.NewExtrema(60.0)},
+						{Count: 0, Bounds: []float64{10, 50}, BucketCounts: []uint64{0, 0, 0}},
+					},
+				}},
+				{Name: "rpc_duration", Data: metricdata.Summary{
+					DataPoints: []metricdata.SummaryDataPoint{{Count: 2, Sum: 3, QuantileValues: []metricdata.QuantileValue{
+						{Quantile: 0, Value: 1}, {Quantile: 0.5, Value: 1.5}, {Quantile: 1, Value: 2},
+					}}},
+				}},
+			},
+		}},
+	}
+	ms := metricPayloads(rm)
+	if len(ms) != 5 {
+		t.Fatalf("got %d metrics, want 5 (empty histogram point skipped)", len(ms))
+	}
+
+	if m := ms[0]; m.Kind != "counter" || m.Value != 3 || m.Unit != "{request}" || m.Source != "checkout" ||
+		m.Attributes["route"] != "/cart" || m.Attributes["otel.scope.name"] != "net/http" || m.Ts != now.Format(time.RFC3339Nano) {
+		t.Errorf("counter = %+v", m)
+	}
+	if m := ms[1]; m.Kind != "gauge" || m.Value != 2 {
+		t.Errorf("cumulative sum = %+v, want gauge 2", m)
+	}
+	if m := ms[2]; m.Kind != "gauge" || m.Value != 21.5 || m.Unit != "Cel" {
+		t.Errorf("gauge = %+v", m)
+	}
+	h := ms[3]
+	if h.Kind != "histogram" || h.Value != 25 || h.Histogram == nil || len(h.Histogram.Counts) != 3 || h.Histogram.Counts[1] != 2 {
+		t.Errorf("histogram = %+v %+v", h, h.Histogram)
+	}
+	if s := h.Summary; s == nil || s.Count != 4 || s.Sum != 100 || s.Min != 5 || s.Max != 60 {
+		t.Errorf("histogram summary = %+v", h.Summary)
+	}
+	s := ms[4]
+	if s.Kind != "summary" || s.Summary.Min != 1 || s.Summary.Max != 2 || s.Summary.Quantiles["p50"] != 1.5 {
+		t.Errorf("summary = %+v %+v", s, s.Summary)
+	}
+}
@@ -0,0 +1,99 @@
+package otlpreceiver
+
+import (
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/otel/internal/convert"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// attributes converts OTLP attributes.
+func attributes(kvs []*commonpb.KeyValue) payload.Attributes {
+	if len(kvs) == 0 {
+		return nil
+	}
+	a := make(payload.Attributes, len(kvs))
+	for _, kv := range kvs {
+		a.Set(kv.GetKey(), anyValue(kv.GetValue()))
+	}
+	return a
+}
+
+// anyValue converts an OTLP value: arrays to slices and key-value lists to
+// maps, which Attributes.Set flattens into dotted keys.
+func anyValue(v *commonpb.AnyValue) any {
+	switch v := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return v.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return v.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return v.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return convert.Value(v.BytesValue)
+	case *commonpb.AnyValue_ArrayValue:
+		values := v.ArrayValue.GetValues()
+		out := make([]any, len(values))
+		for i, item := range values {
+			out[i] = anyValue(item)
+		}
+		return convert.Value(out)
+	case *commonpb.AnyValue_KvlistValue:
+		m := make(map[string]any, len(v.KvlistValue.GetValues()))
+		for _, kv := range v.KvlistValue.GetValues() {
+			m[kv.GetKey()] = anyValue(kv.GetValue())
+		}
+		return m
+	}
+	return nil
+}
+
+// scopeAttributes describes the instrumentation scope, including its own
+// attributes.
+func scopeAttributes(s *commonpb.InstrumentationScope) payload.Attributes {
+	a := attributes(s.GetAttributes())
+	if s.GetName() == "" {
+		return a
+	}
+	if a == nil {
+		a = make(payload.Attributes, 2)
+	}
+	a[convert.ScopeName] = s.GetName()
+	if s.GetVersion() != "" {
+		a[convert.ScopeVersion] = s.GetVersion()
+	}
+	return a
+}
+
+// tally counts the items of a request and the ones that could not be
+// queued.
+type tally struct {
+	total, rejected int
+	err             error
+}
+
+// send queues p on the handler's client.
+func (t *tally) send(h *Handler, p any, level, entryType int) {
+	t.total++
+	if err := convert.Send(h.client, p, level, entryType); err != nil {
+		t.reject(err)
+	}
+}
+
+// reject counts an item that was not queued; the first error is kept for
+// the response.
+func (t *tally) reject(err error) {
+	t.rejected++
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+func (t *tally) message() string {
+	if t.err == nil {
+		return ""
+	}
+	return t.err.Error()
+}
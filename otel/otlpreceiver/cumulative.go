@@ -0,0 +1,115 @@
+package otlpreceiver
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// totals are the running totals of a cumulative series.
+type totals struct {
+	start   uint64 // start time, which changes when the sender restarts
+	value   float64
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// tracked are the last totals of a series and when they were recorded.
+type tracked struct {
+	totals
+	seen time.Time
+}
+
+// minSweepInterval bounds how often a full table is scanned for stale
+// series, so a flood of new series does not scan it for every point.
+const minSweepInterval = time.Second
+
+// cumulative turns the running totals of cumulative sums, histograms and
+// summaries into increases since the previous export, as LogFlux counters
+// and histograms carry. The first export of a series reports its totals, and
+// so does an export after a reset: a new start time or a total that went
+// down. Series not exported for ttl are forgotten once the table is full.
+type cumulative struct {
+	mu     sync.Mutex
+	max    int
+	ttl    time.Duration
+	now    func() time.Time
+	swept  time.Time // last scan for stale series
+	series map[string]tracked
+}
+
+func newCumulative(max int, ttl time.Duration) *cumulative {
+	return &cumulative{max: max, ttl: ttl, now: time.Now, series: make(map[string]tracked)}
+}
+
+// delta records cur for key and returns the increase since the previous
+// totals. ok is false, and nothing is recorded, for a new series while max
+// series are tracked and none of them is stale.
+func (c *cumulative) delta(key string, cur totals) (d totals, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	prev, seen := c.series[key]
+	if !seen && len(c.series) >= c.max {
+		c.evictStale(now)
+		if len(c.series) >= c.max {
+			return totals{}, false
+		}
+	}
+	c.series[key] = tracked{totals: cur, seen: now}
+	if !seen || (cur.start != 0 && cur.start != prev.start) ||
+		cur.value < prev.value || cur.count < prev.count || len(cur.buckets) != len(prev.buckets) {
+		return cur, true
+	}
+	d = totals{
+		start: cur.start,
+		value: cur.value - prev.value,
+		count: cur.count - prev.count,
+		sum:   cur.sum - prev.sum,
+	}
+	if cur.buckets != nil {
+		d.buckets = make([]uint64, len(cur.buckets))
+		for i, n := range cur.buckets {
+			if n >= prev.buckets[i] {
+				d.buckets[i] = n - prev.buckets[i]
+			}
+		}
+	}
+	return d, true
+}
+
+// evictStale forgets the series not seen for ttl. c.mu must be held.
+func (c *cumulative) evictStale(now time.Time) {
+	if now.Sub(c.swept) < minSweepInterval {
+		return
+	}
+	c.swept = now
+	for key, s := range c.series {
+		if now.Sub(s.seen) >= c.ttl {
+			delete(c.series, key)
+		}
+	}
+}
+
+// seriesKey identifies a series by metric name and attributes.
+func seriesKey(name string, attrs payload.Attributes) string {
+	values := attrs.Strings()
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		b.WriteByte(0)
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(values[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package otlpreceiver
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// encoding is the wire format of a request and its response.
+type encoding interface {
+	contentType() string
+	unmarshal(data []byte, m proto.Message) error
+	marshal(m proto.Message) ([]byte, error)
+}
+
+type protobufEncoding struct{}
+
+func (protobufEncoding) contentType() string { return contentTypeProtobuf }
+
+func (protobufEncoding) unmarshal(data []byte, m proto.Message) error {
+	return proto.Unmarshal(data, m)
+}
+
+func (protobufEncoding) marshal(m proto.Message) ([]byte, error) { return proto.Marshal(m) }
+
+// jsonEncoding is the OTLP JSON mapping: the protobuf JSON mapping, except
+// that trace and span IDs are hex rather than base64 strings.
+type jsonEncoding struct{}
+
+func (jsonEncoding) contentType() string { return contentTypeJSON }
+
+func (jsonEncoding) unmarshal(data []byte, m proto.Message) error {
+	data, err := hexIDsToBase64(data)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, m)
+}
+
+func (jsonEncoding) marshal(m proto.Message) ([]byte, error) { return protojson.Marshal(m) }
+
+// idFields are the JSON names of the byte fields OTLP encodes as hex.
+var idFields = map[string]bool{
+	"traceId": true, "trace_id": true,
+	"spanId": true, "span_id": true,
+	"parentSpanId": true, "parent_span_id": true,
+}
+
+// hexIDsToBase64 rewrites the hex trace and span IDs in an OTLP JSON
+// document to base64, which protojson expects for byte fields.
+func hexIDsToBase64(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // keep 64-bit integers exact
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if err := rewriteIDs(doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+func rewriteIDs(v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if s, ok := child.(string); ok && idFields[k] {
+				id, err := hex.DecodeString(s)
+				if err != nil {
+					return fmt.Errorf("%s: invalid hex ID %q", k, s)
+				}
+				v[k] = base64.StdEncoding.EncodeToString(id)
+				continue
+			}
+			if err := rewriteIDs(child); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, child := range v {
+			if err := rewriteIDs(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package otlpreceiver
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/otel/internal/convert"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+func newLogsRequest() proto.Message { return &collogspb.ExportLogsServiceRequest{} }
+
+func (h *Handler) exportLogs(m proto.Message) (proto.Message, int, int) {
+	req := m.(*collogspb.ExportLogsServiceRequest)
+	var t tally
+	for _, rl := range req.GetResourceLogs() {
+		res := attributes(rl.GetResource().GetAttributes())
+		for _, sl := range rl.GetScopeLogs() {
+			base := convert.Merge(res, scopeAttributes(sl.GetScope()))
+			for _, r := range sl.GetLogRecords() {
+				p := logPayload(convert.Source(res), sl.GetScope().GetName(), base, r)
+				t.send(h, p, p.Level, models.EntryTypeLog)
+			}
+		}
+	}
+	resp := &collogspb.ExportLogsServiceResponse{}
+	if t.rejected > 0 {
+		resp.PartialSuccess = &collogspb.ExportLogsPartialSuccess{
+			RejectedLogRecords: int64(t.rejected),
+			ErrorMessage:       t.message(),
+		}
+	}
+	return resp, t.total, t.rejected
+}
+
+// logPayload maps a log record like the SDK log exporter does.
+func logPayload(source, scope string, base payload.Attributes, r *logspb.LogRecord) *payload.Log {
+	recAttrs := attributes(r.GetAttributes())
+	if text := r.GetSeverityText(); text != "" {
+		if recAttrs == nil {
+			recAttrs = make(payload.Attributes, 1)
+		}
+		recAttrs["otel.severity_text"] = text
+	}
+
+	p := payload.NewLog(source, bodyString(r.GetBody()), convert.Level(int(r.GetSeverityNumber())))
+	p.Logger = scope
+	if id := r.GetTraceId(); validID(id) {
+		p.TraceID = hex.EncodeToString(id)
+	}
+	if id := r.GetSpanId(); validID(id) {
+		p.SpanID = hex.EncodeToString(id)
+	}
+	ts := r.GetTimeUnixNano()
+	if ts == 0 {
+		ts = r.GetObservedTimeUnixNano()
+	}
+	if ts != 0 {
+		p.SetTimestamp(unixNano(ts))
+	}
+	if attrs := convert.Merge(base, recAttrs); attrs != nil {
+		p.SetAttrs(attrs)
+	}
+	return p
+}
+
+// bodyString returns a string body as-is and other bodies in their JSON
+// form.
+func bodyString(v *commonpb.AnyValue) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.GetValue().(*commonpb.AnyValue_StringValue); ok {
+		return s.StringValue
+	}
+	data, err := json.Marshal(anyValue(v))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
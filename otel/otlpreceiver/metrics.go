@@ -0,0 +1,224 @@
+package otlpreceiver
+
+import (
+	"errors"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/otel/internal/convert"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// errTooManySeries rejects points of cumulative series beyond MaxSeries.
+var errTooManySeries = errors.New("too many cumulative series")
+
+func newMetricsRequest() proto.Message { return &colmetricspb.ExportMetricsServiceRequest{} }
+
+func (h *Handler) exportMetrics(m proto.Message) (proto.Message, int, int) {
+	req := m.(*colmetricspb.ExportMetricsServiceRequest)
+	var t tally
+	for _, rm := range req.GetResourceMetrics() {
+		res := attributes(rm.GetResource().GetAttributes())
+		for _, sm := range rm.GetScopeMetrics() {
+			base := convert.Merge(res, scopeAttributes(sm.GetScope()))
+			for _, metric := range sm.GetMetrics() {
+				pts := &points{h: h, t: &t, source: convert.Source(res), metric: metric, base: base}
+				pts.convert()
+			}
+		}
+	}
+	resp := &colmetricspb.ExportMetricsServiceResponse{}
+	if t.rejected > 0 {
+		resp.PartialSuccess = &colmetricspb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: int64(t.rejected),
+			ErrorMessage:       t.message(),
+		}
+	}
+	return resp, t.total, t.rejected
+}
+
+// points maps the data points of one metric like the SDK metric exporter
+// does. Cumulative points are first turned into increases; points without
+// a recorded value, and increases of nothing, are skipped.
+type points struct {
+	h      *Handler
+	t      *tally
+	source string
+	metric *metricspb.Metric
+	base   payload.Attributes
+}
+
+func (p *points) convert() {
+	switch d := p.metric.GetData().(type) {
+	case *metricspb.Metric_Gauge:
+		for _, dp := range d.Gauge.GetDataPoints() {
+			p.number(dp, false, false)
+		}
+	case *metricspb.Metric_Sum:
+		cumulative := d.Sum.GetAggregationTemporality() == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+		for _, dp := range d.Sum.GetDataPoints() {
+			p.number(dp, d.Sum.GetIsMonotonic(), cumulative)
+		}
+	case *metricspb.Metric_Histogram:
+		cumulative := d.Histogram.GetAggregationTemporality() == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+		for _, dp := range d.Histogram.GetDataPoints() {
+			p.histogram(dp, cumulative)
+		}
+	case *metricspb.Metric_ExponentialHistogram:
+		cumulative := d.ExponentialHistogram.GetAggregationTemporality() == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+		for _, dp := range d.ExponentialHistogram.GetDataPoints() {
+			p.exponential(dp, cumulative)
+		}
+	case *metricspb.Metric_Summary:
+		for _, dp := range d.Summary.GetDataPoints() {
+			p.summary(dp)
+		}
+	}
+}
+
+// number maps a gauge or sum point. Monotonic sums become counters, other
+// sums gauges of their current value.
+func (p *points) number(dp *metricspb.NumberDataPoint, monotonic, cumulative bool) {
+	if noValue(dp.GetFlags()) {
+		return
+	}
+	v := dp.GetAsDouble()
+	if i, ok := dp.GetValue().(*metricspb.NumberDataPoint_AsInt); ok {
+		v = float64(i.AsInt)
+	}
+	attrs := attributes(dp.GetAttributes())
+	if !monotonic {
+		p.send(payload.NewGauge(p.source, p.metric.GetName(), v, p.metric.GetUnit()), attrs, dp.GetTimeUnixNano())
+		return
+	}
+	if cumulative {
+		d, ok := p.delta(attrs, totals{start: dp.GetStartTimeUnixNano(), value: v})
+		if !ok {
+			return
+		}
+		v = d.value
+	}
+	p.send(payload.NewCounter(p.source, p.metric.GetName(), v), attrs, dp.GetTimeUnixNano())
+}
+
+func (p *points) histogram(dp *metricspb.HistogramDataPoint, cumulative bool) {
+	if noValue(dp.GetFlags()) {
+		return
+	}
+	attrs := attributes(dp.GetAttributes())
+	cur := totals{start: dp.GetStartTimeUnixNano(), count: dp.GetCount(), sum: dp.GetSum(), buckets: dp.GetBucketCounts()}
+	if cumulative {
+		var ok bool
+		if cur, ok = p.delta(attrs, cur); !ok {
+			return
+		}
+	}
+	m := p.distribution(cur, dp.Min, dp.Max)
+	if m == nil {
+		return
+	}
+	m.Kind = "histogram"
+	if len(cur.buckets) == len(dp.GetExplicitBounds())+1 {
+		m.Histogram = &payload.Histogram{Bounds: dp.GetExplicitBounds(), Counts: cur.buckets}
+	}
+	p.send(m, attrs, dp.GetTimeUnixNano())
+}
+
+// exponential sends an exponential histogram as a distribution; its
+// buckets are not kept.
+func (p *points) exponential(dp *metricspb.ExponentialHistogramDataPoint, cumulative bool) {
+	if noValue(dp.GetFlags()) {
+		return
+	}
+	attrs := attributes(dp.GetAttributes())
+	cur := totals{start: dp.GetStartTimeUnixNano(), count: dp.GetCount(), sum: dp.GetSum()}
+	if cumulative {
+		var ok bool
+		if cur, ok = p.delta(attrs, cur); !ok {
+			return
+		}
+	}
+	if m := p.distribution(cur, dp.Min, dp.Max); m != nil {
+		p.send(m, attrs, dp.GetTimeUnixNano())
+	}
+}
+
+// summary maps a summary point. Its count and sum are cumulative; its
+// quantiles cover the sender's own window and are kept as they are.
+func (p *points) summary(dp *metricspb.SummaryDataPoint) {
+	if noValue(dp.GetFlags()) {
+		return
+	}
+	attrs := attributes(dp.GetAttributes())
+	cur, ok := p.delta(attrs, totals{start: dp.GetStartTimeUnixNano(), count: dp.GetCount(), sum: dp.GetSum()})
+	if !ok {
+		return
+	}
+	m := p.distribution(cur, nil, nil)
+	if m == nil {
+		return
+	}
+	m.Kind = "summary"
+	for _, qv := range dp.GetQuantileValues() {
+		if m.Summary.Quantiles == nil {
+			m.Summary.Quantiles = make(map[string]float64, len(dp.GetQuantileValues()))
+		}
+		m.Summary.Quantiles[payload.QuantileKey(qv.GetQuantile())] = qv.GetValue()
+		switch qv.GetQuantile() {
+		case 0:
+			m.Summary.Min = qv.GetValue()
+		case 1:
+			m.Summary.Max = qv.GetValue()
+		}
+	}
+	p.send(m, attrs, dp.GetTimeUnixNano())
+}
+
+// delta converts cumulative totals, rejecting the point if its series
+// cannot be tracked.
+func (p *points) delta(attrs payload.Attributes, cur totals) (totals, bool) {
+	d, ok := p.h.cumulative.delta(seriesKey(p.metric.GetName(), convert.Merge(p.base, attrs)), cur)
+	if !ok {
+		p.t.total++
+		p.t.reject(errTooManySeries)
+	}
+	return d, ok
+}
+
+// distribution returns a distribution metric whose Value is the mean, or
+// nil if nothing was recorded. Min and max are those the sender reports,
+// which for cumulative points cover its whole collection period.
+func (p *points) distribution(t totals, min, max *float64) *payload.Metric {
+	if t.count == 0 {
+		return nil
+	}
+	m := payload.NewDistribution(p.source, p.metric.GetName(), t.sum/float64(t.count), p.metric.GetUnit())
+	m.Summary = &payload.Summary{Count: t.count, Sum: t.sum}
+	if min != nil {
+		m.Summary.Min = *min
+	}
+	if max != nil {
+		m.Summary.Max = *max
+	}
+	return m
+}
+
+// send finishes m with the metric's unit, the point's time and the merged
+// attributes, and queues it.
+func (p *points) send(m *payload.Metric, attrs payload.Attributes, ts uint64) {
+	m.Unit = p.metric.GetUnit()
+	if ts != 0 {
+		m.SetTimestamp(unixNano(ts))
+	}
+	if merged := convert.Merge(p.base, attrs); merged != nil {
+		m.SetAttrs(merged)
+	}
+	p.t.send(p.h, m, models.LogLevelInfo, models.EntryTypeMetric)
+}
+
+func noValue(flags uint32) bool {
+	return flags&uint32(metricspb.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK) != 0
+}
@@ -0,0 +1,203 @@
+// Package otlpreceiver receives OTLP/HTTP exports and forwards them to
+// LogFlux. Handler accepts traces, logs and metrics in protobuf or JSON
+// encoding, optionally gzip-compressed, maps them like the exporters in the
+// parent package and queues them on a client.ResilientClient, which
+// encrypts them before they leave the process:
+//
+//	mux.Handle("/v1/", otlpreceiver.NewHandler(c, otlpreceiver.Options{}))
+//
+// Point OTLP senders at the handler with their HTTP protocol and the
+// endpoint http://host:port; they append /v1/traces, /v1/logs and
+// /v1/metrics themselves. The logflux-otlp-relay command runs the handler
+// as a standalone local relay.
+package otlpreceiver
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+)
+
+// Options configures a Handler.
+type Options struct {
+	// MaxBodySize limits the size of a request body after decompression
+	// (default: 16 MiB). Larger requests are rejected with 413.
+	MaxBodySize int64
+	// MaxSeries limits the cumulative sum and histogram series whose
+	// previous totals are kept to convert them to increases (default:
+	// 10000). Points of further series are rejected.
+	MaxSeries int
+	// SeriesTTL is how long a cumulative series may go without an export
+	// before its slot can be reused by a new series (default: 1h). A
+	// forgotten series that comes back reports its totals again.
+	SeriesTTL time.Duration
+}
+
+const (
+	defaultMaxBodySize = 16 << 20
+	defaultMaxSeries   = 10000
+	defaultSeriesTTL   = time.Hour
+)
+
+// OTLP/HTTP content types.
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// Handler is an http.Handler serving the OTLP/HTTP paths /v1/traces,
+// /v1/logs and /v1/metrics, also when mounted under a prefix. Requests are
+// answered once their entries are queued; entries the client refuses are
+// reported as rejected in the response's partial success, and a request
+// whose entries are all refused is answered with 503 so that the sender
+// retries it.
+type Handler struct {
+	client     *client.ResilientClient
+	maxBody    int64
+	cumulative *cumulative
+}
+
+// NewHandler returns a handler that sends through c. The handler does not
+// own c; close it after the server has stopped.
+func NewHandler(c *client.ResilientClient, opts Options) *Handler {
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = defaultMaxBodySize
+	}
+	if opts.MaxSeries <= 0 {
+		opts.MaxSeries = defaultMaxSeries
+	}
+	if opts.SeriesTTL <= 0 {
+		opts.SeriesTTL = defaultSeriesTTL
+	}
+	return &Handler{
+		client:     c,
+		maxBody:    opts.MaxBodySize,
+		cumulative: newCumulative(opts.MaxSeries, opts.SeriesTTL),
+	}
+}
+
+// signal decodes and forwards one OTLP signal. export returns the response
+// to encode and the number of items received and rejected.
+type signal struct {
+	request func() proto.Message
+	export  func(h *Handler, req proto.Message) (resp proto.Message, total, rejected int)
+}
+
+var signals = map[string]signal{
+	"/v1/traces":  {newTraceRequest, (*Handler).exportTraces},
+	"/v1/logs":    {newLogsRequest, (*Handler).exportLogs},
+	"/v1/metrics": {newMetricsRequest, (*Handler).exportMetrics},
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var sig signal
+	found := false
+	for suffix, s := range signals {
+		if strings.HasSuffix(r.URL.Path, suffix) {
+			sig, found = s, true
+			break
+		}
+	}
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	enc, err := requestEncoding(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	body, status, err := h.readBody(r)
+	if err != nil {
+		writeStatus(w, enc, status, err)
+		return
+	}
+	req := sig.request()
+	if err := enc.unmarshal(body, req); err != nil {
+		writeStatus(w, enc, http.StatusBadRequest, fmt.Errorf("decoding request: %w", err))
+		return
+	}
+
+	resp, total, rejected := sig.export(h, req)
+	if total > 0 && rejected == total {
+		w.Header().Set("Retry-After", "1")
+		writeStatus(w, enc, http.StatusServiceUnavailable, errors.New("entries could not be queued"))
+		return
+	}
+	writeMessage(w, enc, http.StatusOK, resp)
+}
+
+// requestEncoding picks the codec for the request's content type.
+func requestEncoding(r *http.Request) (encoding, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported content type %q", r.Header.Get("Content-Type"))
+	}
+	switch mediaType {
+	case contentTypeProtobuf:
+		return protobufEncoding{}, nil
+	case contentTypeJSON:
+		return jsonEncoding{}, nil
+	}
+	return nil, fmt.Errorf("unsupported content type %q", mediaType)
+}
+
+// readBody reads the request body, decompressing it as needed, and returns
+// the HTTP status to answer with if that fails.
+func (h *Handler) readBody(r *http.Request) ([]byte, int, error) {
+	var body io.Reader = r.Body
+	switch enc := strings.ToLower(r.Header.Get("Content-Encoding")); enc {
+	case "", "identity":
+	case "gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("reading gzip body: %w", err)
+		}
+		defer zr.Close()
+		body = zr
+	default:
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content encoding %q", enc)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, h.maxBody+1))
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("reading body: %w", err)
+	}
+	if int64(len(data)) > h.maxBody {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("body exceeds %d bytes", h.maxBody)
+	}
+	return data, 0, nil
+}
+
+// writeStatus answers with an error status carrying a google.rpc.Status, as
+// OTLP/HTTP specifies.
+func writeStatus(w http.ResponseWriter, enc encoding, code int, err error) {
+	writeMessage(w, enc, code, &spb.Status{Message: err.Error()})
+}
+
+func writeMessage(w http.ResponseWriter, enc encoding, code int, m proto.Message) {
+	data, err := enc.marshal(m)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", enc.contentType())
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,452 @@
+package otlpreceiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+)
+
+// recorder captures the entries queued on a test client.
+type recorder struct {
+	mu      sync.Mutex
+	entries []models.LogEntry
+}
+
+func (r *recorder) hook(e *models.LogEntry) *models.LogEntry {
+	r.mu.Lock()
+	r.entries = append(r.entries, *e)
+	r.mu.Unlock()
+	return nil // captured, not sent
+}
+
+// payloads decodes the captured entries of entryType.
+func (r *recorder) payloads(t *testing.T, entryType int) []map[string]any {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []map[string]any
+	for _, e := range r.entries {
+		if e.EntryType != entryType {
+			continue
+		}
+		var p map[string]any
+		if err := json.Unmarshal([]byte(e.Message), &p); err != nil {
+			t.Fatalf("decoding payload %q: %v", e.Message, err)
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// newTestClient returns a client connected to a mock ingestor that only
+// completes handshakes; entries are captured by rec.
+func newTestClient(t *testing.T, rec *recorder) *client.ResilientClient {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/handshake/init", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"public_key": string(pemBytes)})
+	})
+	mux.HandleFunc("/v1/handshake/complete", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"key_id": "test-key"})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg := client.DefaultResilientClientConfig()
+	cfg.APIKey = "eu-lf_testkey123"
+	cfg.Node = "test-node"
+	cfg.CustomEndpointURL = srv.URL
+	cfg.FailsafeMode = false
+	cfg.BeforeSend = rec.hook
+	c, err := client.NewResilientClientWithHandshake(cfg)
+	if err != nil {
+		t.Fatalf("NewResilientClientWithHandshake: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func post(t *testing.T, h http.Handler, path, contentType string, body []byte, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func mustMarshal(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+	data, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatalf("proto.Marshal: %v", err)
+	}
+	return data
+}
+
+func str(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+}
+
+var testResource = &resourcepb.Resource{Attributes: []*commonpb.KeyValue{str("service.name", "checkout")}}
+
+func TestTracesProtobufGzip(t *testing.T) {
+	rec := &recorder{}
+	h := NewHandler(newTestClient(t, rec), Options{})
+
+	req := &coltracepb.ExportTraceServiceRequest{ResourceSpans: []*tracepb.ResourceSpans{{
+		Resource: testResource,
+		ScopeSpans: []*tracepb.ScopeSpans{{
+			Scope: &commonpb.InstrumentationScope{Name: "net/http"},
+			Spans: []*tracepb.Span{{
+				TraceId:           bytes.Repeat([]byte{0xab}, 16),
+				SpanId:            bytes.Repeat([]byte{0xcd}, 8),
+				ParentSpanId:      bytes.Repeat([]byte{0xef}, 8),
+				Name:              "GET /cart",
+				Kind:              tracepb.Span_SPAN_KIND_SERVER,
+				StartTimeUnixNano: 1_700_000_000_000_000_000,
+				EndTimeUnixNano:   1_700_000_000_250_000_000,
+				Attributes:        []*commonpb.KeyValue{str("http.request.method", "GET")},
+				Status:            &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: "boom"},
+				Events:            []*tracepb.Span_Event{{Name: "retry", TimeUnixNano: 1_700_000_000_100_000_000}},
+			}},
+		}},
+	}}}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write(mustMarshal(t, req))
+	_ = zw.Close()
+
+	w := post(t, h, "/v1/traces", "application/x-protobuf", gz.Bytes(), map[string]string{"Content-Encoding": "gzip"})
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-protobuf" {
+		t.Fatalf("status %d, content type %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+	var resp coltracepb.ExportTraceServiceResponse
+	if err := proto.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.PartialSuccess != nil {
+		t.Errorf("response = %v, %v; want empty success", &resp, err)
+	}
+
+	traces := rec.payloads(t, models.EntryTypeTrace)
+	if len(traces) != 1 {
+		t.Fatalf("got %d traces, want 1", len(traces))
+	}
+	p := traces[0]
+	for k, want := range map[string]any{
+		"trace_id":       strings.Repeat("ab", 16),
+		"span_id":        strings.Repeat("cd", 8),
+		"parent_span_id": strings.Repeat("ef", 8),
+		"source":         "checkout",
+		"operation":      "http.server",
+		"kind":           "server",
+		"status":         "error",
+		"status_message": "boom",
+		"duration_ms":    float64(250),
+	} {
+		if p[k] != want {
+			t.Errorf("%s = %v, want %v", k, p[k], want)
+		}
+	}
+	attrs, _ := p["attributes"].(map[string]any)
+	if attrs["otel.scope.name"] != "net/http" || attrs["http.request.method"] != "GET" {
+		t.Errorf("attributes = %v", attrs)
+	}
+	if events, _ := p["events"].([]any); len(events) != 1 {
+		t.Errorf("events = %v", p["events"])
+	}
+}
+
+func TestLogsJSON(t *testing.T) {
+	rec := &recorder{}
+	h := NewHandler(newTestClient(t, rec), Options{})
+
+	body := `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"worker"}}]},
+	"scopeLogs":[{"scope":{"name":"jobs"},"logRecords":[
+		{"timeUnixNano":"1700000000000000000","severityNumber":17,"severityText":"ERROR",
+		 "body":{"stringValue":"job failed"},
+		 "traceId":"5b8efff798038103d269b633813fc60c","spanId":"eee19b7ec3c1b174",
+		 "attributes":[{"key":"attempt","value":{"intValue":"3"}}],"futureField":true},
+		{"observedTimeUnixNano":"1700000000000000000","body":{"kvlistValue":{"values":[{"key":"a","value":{"boolValue":true}}]}}}
+	]}]}]}`
+	w := post(t, h, "/v1/logs", "application/json; charset=utf-8", []byte(body), nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status %d, content type %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+
+	logs := rec.payloads(t, models.EntryTypeLog)
+	if len(logs) != 2 {
+		t.Fatalf("got %d logs, want 2", len(logs))
+	}
+	p := logs[0]
+	for k, want := range map[string]any{
+		"message":  "job failed",
+		"level":    float64(models.LogLevelError),
+		"logger":   "jobs",
+		"source":   "worker",
+		"trace_id": "5b8efff798038103d269b633813fc60c",
+		"span_id":  "eee19b7ec3c1b174",
+		"ts":       "2023-11-14T22:13:20Z",
+	} {
+		if p[k] != want {
+			t.Errorf("%s = %v, want %v", k, p[k], want)
+		}
+	}
+	attrs, _ := p["attributes"].(map[string]any)
+	if attrs["attempt"] != float64(3) || attrs["otel.severity_text"] != "ERROR" {
+		t.Errorf("attributes = %v", attrs)
+	}
+	if q := logs[1]; q["message"] != `{"a":true}` || q["ts"] != "2023-11-14T22:13:20Z" || q["level"] != float64(models.LogLevelInfo) {
+		t.Errorf("second log = %v", q)
+	}
+}
+
+func cumulativeSum(start uint64, value int64, attrs ...*commonpb.KeyValue) *colmetricspb.ExportMetricsServiceRequest {
+	return &colmetricspb.ExportMetricsServiceRequest{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		Resource: testResource,
+		ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: []*metricspb.Metric{{
+			Name: "requests",
+			Unit: "{request}",
+			Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				IsMonotonic:            true,
+				DataPoints: []*metricspb.NumberDataPoint{{
+					Attributes:        attrs,
+					StartTimeUnixNano: start,
+					Value:             &metricspb.NumberDataPoint_AsInt{AsInt: value},
+				}},
+			}},
+		}}}},
+	}}}
+}
+
+func TestMetricsCumulativeSumsBecomeIncreases(t *testing.T) {
+	rec := &recorder{}
+	h := NewHandler(newTestClient(t, rec), Options{})
+
+	for _, req := range []*colmetricspb.ExportMetricsServiceRequest{
+		cumulativeSum(1, 10),
+		cumulativeSum(1, 15),
+		cumulativeSum(2, 4), // sender restarted
+		cumulativeSum(2, 3), // went down: reset
+	} {
+		if w := post(t, h, "/v1/metrics", "application/x-protobuf", mustMarshal(t, req), nil); w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body)
+		}
+	}
+	var got []string
+	for _, p := range rec.payloads(t, models.EntryTypeMetric) {
+		got = append(got, fmt.Sprintf("%s %v %s", p["kind"], p["value"], p["unit"]))
+	}
+	want := []string{"counter 10 {request}", "counter 5 {request}", "counter 4 {request}", "counter 3 {request}"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("metrics = %q, want %q", got, want)
+	}
+}
+
+func TestMetricsHistogramAndGauge(t *testing.T) {
+	rec := &recorder{}
+	h := NewHandler(newTestClient(t, rec), Options{})
+
+	hist := func(count uint64, sum float64, buckets []uint64) *colmetricspb.ExportMetricsServiceRequest {
+		return &colmetricspb.ExportMetricsServiceRequest{ResourceMetrics: []*metricspb.ResourceMetrics{{
+			ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: []*metricspb.Metric{
+				{Name: "latency", Unit: "ms", Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+					AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+					DataPoints: []*metricspb.HistogramDataPoint{{
+						StartTimeUnixNano: 1, Count: count, Sum: &sum,
+						ExplicitBounds: []float64{10, 100}, BucketCounts: buckets,
+					}},
+				}}},
+				{Name: "queue_depth", Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{
+					DataPoints: []*metricspb.NumberDataPoint{
+						{Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 7}},
+						{Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 1},
+							Flags: uint32(metricspb.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK)},
+					},
+				}}},
+			}}},
+		}}}
+	}
+	for _, req := range []*colmetricspb.ExportMetricsServiceRequest{
+		hist(4, 100, []uint64{1, 2, 1}),
+		hist(4, 100, []uint64{1, 2, 1}), // nothing new: skipped
+		hist(6, 300, []uint64{1, 3, 2}),
+	} {
+		if w := post(t, h, "/v1/metrics", "application/x-protobuf", mustMarshal(t, req), nil); w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body)
+		}
+	}
+
+	var hists, gauges []map[string]any
+	for _, p := range rec.payloads(t, models.EntryTypeMetric) {
+		if p["kind"] == "histogram" {
+			hists = append(hists, p)
+		} else {
+			gauges = append(gauges, p)
+		}
+	}
+	if len(gauges) != 3 || gauges[0]["value"] != float64(7) {
+		t.Errorf("gauges = %v, want three of 7", gauges)
+	}
+	if len(hists) != 2 {
+		t.Fatalf("got %d histograms, want 2", len(hists))
+	}
+	last := hists[1]
+	if last["value"] != float64(100) {
+		t.Errorf("mean = %v, want 100", last["value"])
+	}
+	if counts := fmt.Sprint(last["histogram"].(map[string]any)["counts"]); counts != "[0 1 1]" {
+		t.Errorf("counts = %s, want [0 1 1]", counts)
+	}
+	if s := last["summary"].(map[string]any); s["count"] != float64(2) || s["sum"] != float64(200) {
+		t.Errorf("summary = %v", s)
+	}
+}
+
+func TestMetricsMaxSeriesRejectsPoints(t *testing.T) {
+	rec := &recorder{}
+	h := NewHandler(newTestClient(t, rec), Options{MaxSeries: 1})
+
+	post(t, h, "/v1/metrics", "application/x-protobuf", mustMarshal(t, cumulativeSum(1, 1, str("route", "/a"))), nil)
+	w := post(t, h, "/v1/metrics", "application/json", mustJSON(t, cumulativeSum(1, 1, str("route", "/b"))), nil)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503 when every point is rejected: %s", w.Code, w.Body)
+	}
+	if n := len(rec.payloads(t, models.EntryTypeMetric)); n != 1 {
+		t.Errorf("sent %d metrics, want 1", n)
+	}
+}
+
+func TestMetricsStaleSeriesFreesSlot(t *testing.T) {
+	rec := &recorder{}
+	h := NewHandler(newTestClient(t, rec), Options{MaxSeries: 1, SeriesTTL: time.Minute})
+	now := time.Now()
+	h.cumulative.now = func() time.Time { return now }
+
+	post(t, h, "/v1/metrics", "application/x-protobuf", mustMarshal(t, cumulativeSum(1, 1, str("route", "/a"))), nil)
+	now = now.Add(30 * time.Second)
+	if w := post(t, h, "/v1/metrics", "application/x-protobuf", mustMarshal(t, cumulativeSum(1, 1, str("route", "/b"))), nil); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503 while /a is still live", w.Code)
+	}
+
+	now = now.Add(time.Minute)
+	if w := post(t, h, "/v1/metrics", "application/x-protobuf", mustMarshal(t, cumulativeSum(1, 1, str("route", "/b"))), nil); w.Code != http.StatusOK {
+		t.Fatalf("status %d, want /b accepted once /a is stale: %s", w.Code, w.Body)
+	}
+	if n := len(rec.payloads(t, models.EntryTypeMetric)); n != 2 {
+		t.Errorf("sent %d metrics, want 2", n)
+	}
+}
+
+func TestPartialSuccess(t *testing.T) {
+	rec := &recorder{}
+	h := NewHandler(newTestClient(t, rec), Options{MaxSeries: 1})
+
+	req := cumulativeSum(1, 1, str("route", "/a"))
+	other := cumulativeSum(1, 1, str("route", "/b"))
+	req.ResourceMetrics = append(req.ResourceMetrics, other.ResourceMetrics...)
+	w := post(t, h, "/v1/metrics", "application/json", mustJSON(t, req), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var resp colmetricspb.ExportMetricsServiceResponse
+	if err := protojson.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if ps := resp.GetPartialSuccess(); ps.GetRejectedDataPoints() != 1 || ps.GetErrorMessage() != errTooManySeries.Error() {
+		t.Errorf("partial success = %v", ps)
+	}
+}
+
+func mustJSON(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		t.Fatalf("protojson.Marshal: %v", err)
+	}
+	return data
+}
+
+func TestClosedClientAnswers503(t *testing.T) {
+	rec := &recorder{}
+	c := newTestClient(t, rec)
+	h := NewHandler(c, Options{})
+	_ = c.Close()
+
+	w := post(t, h, "/v1/traces", "application/x-protobuf", mustMarshal(t, &coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{Name: "x"}}}}}},
+	}), nil)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("status %d, Retry-After %q; want 503 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	rec := &recorder{}
+	h := NewHandler(newTestClient(t, rec), Options{MaxBodySize: 128})
+
+	tests := []struct {
+		name, method, path, contentType, body string
+		header                                map[string]string
+		want                                  int
+	}{
+		{"unknown path", http.MethodPost, "/v1/profiles", "application/json", "{}", nil, http.StatusNotFound},
+		{"method", http.MethodGet, "/v1/logs", "application/json", "", nil, http.StatusMethodNotAllowed},
+		{"content type", http.MethodPost, "/v1/logs", "text/plain", "{}", nil, http.StatusUnsupportedMediaType},
+		{"content encoding", http.MethodPost, "/v1/logs", "application/json", "{}", map[string]string{"Content-Encoding": "br"}, http.StatusUnsupportedMediaType},
+		{"bad gzip", http.MethodPost, "/v1/logs", "application/json", "{}", map[string]string{"Content-Encoding": "gzip"}, http.StatusBadRequest},
+		{"bad json", http.MethodPost, "/v1/logs", "application/json", "{", nil, http.StatusBadRequest},
+		{"bad hex id", http.MethodPost, "/v1/traces", "application/json", `{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"xyz"}]}]}]}`, nil, http.StatusBadRequest},
+		{"bad protobuf", http.MethodPost, "/v1/traces", "application/x-protobuf", "\xff\xff", nil, http.StatusBadRequest},
+		{"too large", http.MethodPost, "/v1/logs", "application/json", strings.Repeat(" ", 129) + "{}", nil, http.StatusRequestEntityTooLarge},
+		{"prefixed path", http.MethodPost, "/otlp/v1/logs", "application/json", "{}", nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
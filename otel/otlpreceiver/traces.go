@@ -0,0 +1,103 @@
+package otlpreceiver
+
+import (
+	"encoding/hex"
+	"strings"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/otel/internal/convert"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+func newTraceRequest() proto.Message { return &coltracepb.ExportTraceServiceRequest{} }
+
+func (h *Handler) exportTraces(m proto.Message) (proto.Message, int, int) {
+	req := m.(*coltracepb.ExportTraceServiceRequest)
+	var t tally
+	for _, rs := range req.GetResourceSpans() {
+		res := attributes(rs.GetResource().GetAttributes())
+		for _, ss := range rs.GetScopeSpans() {
+			base := convert.Merge(res, scopeAttributes(ss.GetScope()))
+			for _, s := range ss.GetSpans() {
+				t.send(h, spanPayload(convert.Source(res), base, s), models.LogLevelInfo, models.EntryTypeTrace)
+			}
+		}
+	}
+	resp := &coltracepb.ExportTraceServiceResponse{}
+	if t.rejected > 0 {
+		resp.PartialSuccess = &coltracepb.ExportTracePartialSuccess{
+			RejectedSpans: int64(t.rejected),
+			ErrorMessage:  t.message(),
+		}
+	}
+	return resp, t.total, t.rejected
+}
+
+// spanPayload maps a span like the SDK span exporter does.
+func spanPayload(source string, base payload.Attributes, s *tracepb.Span) *payload.Trace {
+	attrs := convert.Merge(base, attributes(s.GetAttributes()))
+	kind := spanKind(s.GetKind())
+	start, end := unixNano(s.GetStartTimeUnixNano()), unixNano(s.GetEndTimeUnixNano())
+
+	p := payload.NewTrace(source, hex.EncodeToString(s.GetTraceId()), hex.EncodeToString(s.GetSpanId()),
+		convert.Operation(kind, attrs), s.GetName(), start, end)
+	p.Kind = kind
+	if parent := s.GetParentSpanId(); validID(parent) {
+		p.ParentSpanID = hex.EncodeToString(parent)
+	}
+	if st := s.GetStatus(); st.GetCode() == tracepb.Status_STATUS_CODE_ERROR {
+		p.Status = "error"
+		p.StatusMessage = st.GetMessage()
+	}
+	for _, ev := range s.GetEvents() {
+		p.Events = append(p.Events, payload.SpanEvent{
+			Name:       ev.GetName(),
+			Ts:         convert.Timestamp(unixNano(ev.GetTimeUnixNano())),
+			Attributes: attributes(ev.GetAttributes()),
+		})
+	}
+	for _, l := range s.GetLinks() {
+		p.Links = append(p.Links, payload.SpanLink{
+			TraceID:    hex.EncodeToString(l.GetTraceId()),
+			SpanID:     hex.EncodeToString(l.GetSpanId()),
+			Attributes: attributes(l.GetAttributes()),
+		})
+	}
+	if !end.IsZero() {
+		p.SetTimestamp(end)
+	}
+	p.SetAttrs(attrs)
+	return p
+}
+
+// spanKind returns the kind's name as the SDK exporter reports it, e.g.
+// "server"; unspecified spans are internal.
+func spanKind(k tracepb.Span_SpanKind) string {
+	if k == tracepb.Span_SPAN_KIND_UNSPECIFIED {
+		return "internal"
+	}
+	return strings.ToLower(strings.TrimPrefix(k.String(), "SPAN_KIND_"))
+}
+
+// unixNano converts an OTLP timestamp; 0 is the zero time.
+func unixNano(ns uint64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(ns)).UTC()
+}
+
+// validID reports whether id is a set trace or span ID.
+func validID(id []byte) bool {
+	for _, b := range id {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package otel
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/otel/internal/convert"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// SpanExporter is an sdktrace.SpanExporter that sends spans as trace
+// entries (type 3).
+type SpanExporter struct {
+	exporter
+}
+
+var _ sdktrace.SpanExporter = (*SpanExporter)(nil)
+
+// NewSpanExporter returns a span exporter sending through c.
+func NewSpanExporter(c *client.ResilientClient) *SpanExporter {
+	return &SpanExporter{exporter{client: c}}
+}
+
+// ExportSpans queues spans on the client.
+func (e *SpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if e.stopped.Load() {
+		return nil
+	}
+	var errs []error
+	for _, s := range spans {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := convert.Send(e.client, spanPayload(s), models.LogLevelInfo, models.EntryTypeTrace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// spanPayload maps a finished span. Status "error" carries the status
+// description; unset and ok statuses both become "ok".
+func spanPayload(s sdktrace.ReadOnlySpan) *payload.Trace {
+	res := resourceAttributes(s.Resource())
+	attrs := convert.Merge(res, scopeAttributes(s.InstrumentationScope()), attributes(s.Attributes()))
+	kind := s.SpanKind().String()
+	sc := s.SpanContext()
+
+	p := payload.NewTrace(convert.Source(res), sc.TraceID().String(), sc.SpanID().String(),
+		convert.Operation(kind, attrs), s.Name(), s.StartTime(), s.EndTime())
+	p.Kind = kind
+	if parent := s.Parent(); parent.IsValid() {
+		p.ParentSpanID = parent.SpanID().String()
+	}
+	if st := s.Status(); st.Code == codes.Error {
+		p.Status = "error"
+		p.StatusMessage = st.Description
+	}
+	for _, ev := range s.Events() {
+		p.Events = append(p.Events, payload.SpanEvent{
+			Name:       ev.Name,
+			Ts:         convert.Timestamp(ev.Time),
+			Attributes: attributes(ev.Attributes),
+		})
+	}
+	for _, l := range s.Links() {
+		p.Links = append(p.Links, payload.SpanLink{
+			TraceID:    l.SpanContext.TraceID().String(),
+			SpanID:     l.SpanContext.SpanID().String(),
+			Attributes: attributes(l.Attributes),
+		})
+	}
+	p.SetTimestamp(s.EndTime())
+	p.SetAttrs(attrs)
+	return p
+}
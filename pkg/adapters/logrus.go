@@ -2,7 +2,6 @@ package adapters
 
 import (
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -217,7 +216,7 @@ func (l *LogrusLogger) Fatal(args ...interface{}) {
 		message := l.formatMessage(sprint(args...), nil)
 		_ = l.client.SendLogWithTimestampAndLevel(message, time.Now(), mapLogrusLevel(LogrusFatalLevel))
 	}
-	os.Exit(1)
+	exitFatal(l.client)
 }
 
 func (l *LogrusLogger) Panic(args ...interface{}) {
@@ -273,7 +272,7 @@ func (l *LogrusLogger) Fatalf(format string, args ...interface{}) {
 		message := sprintf(format, args...)
 		_ = l.client.SendLogWithTimestampAndLevel(message, time.Now(), mapLogrusLevel(LogrusFatalLevel))
 	}
-	os.Exit(1)
+	exitFatal(l.client)
 }
 
 func (l *LogrusLogger) Panicf(format string, args ...interface{}) {
@@ -329,7 +328,7 @@ func (l *LogrusLogger) Fatalln(args ...interface{}) {
 		message := sprintln(args...)
 		_ = l.client.SendLogWithTimestampAndLevel(message, time.Now(), mapLogrusLevel(LogrusFatalLevel))
 	}
-	os.Exit(1)
+	exitFatal(l.client)
 }
 
 func (l *LogrusLogger) Panicln(args ...interface{}) {
@@ -340,6 +339,14 @@ func (l *LogrusLogger) Panicln(args ...interface{}) {
 	panic(message)
 }
 
+// send delivers an entry. Clients implementing AttributeLogger receive
+// e.Data as typed attributes; others get it appended to the message.
+func (e *LogrusEntry) send(message string, level int) error {
+	return sendStructured(e.logger.client, e.Time, level, message, e.Data, func() string {
+		return e.logger.formatMessage(message, e.Data)
+	})
+}
+
 // LogrusEntry methods
 func (e *LogrusEntry) WithField(key string, value interface{}) *LogrusEntry {
 	if e.Data == nil {
@@ -374,29 +381,25 @@ func (e *LogrusEntry) WithTime(t time.Time) *LogrusEntry {
 // Log methods for LogrusEntry
 func (e *LogrusEntry) Trace(args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusTraceLevel) {
-		message := e.logger.formatMessage(sprint(args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusTraceLevel))
+		_ = e.send(sprint(args...), mapLogrusLevel(LogrusTraceLevel))
 	}
 }
 
 func (e *LogrusEntry) Debug(args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusDebugLevel) {
-		message := e.logger.formatMessage(sprint(args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusDebugLevel))
+		_ = e.send(sprint(args...), mapLogrusLevel(LogrusDebugLevel))
 	}
 }
 
 func (e *LogrusEntry) Info(args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusInfoLevel) {
-		message := e.logger.formatMessage(sprint(args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusInfoLevel))
+		_ = e.send(sprint(args...), mapLogrusLevel(LogrusInfoLevel))
 	}
 }
 
 func (e *LogrusEntry) Warn(args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusWarnLevel) {
-		message := e.logger.formatMessage(sprint(args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusWarnLevel))
+		_ = e.send(sprint(args...), mapLogrusLevel(LogrusWarnLevel))
 	}
 }
 
@@ -406,53 +409,47 @@ func (e *LogrusEntry) Warning(args ...interface{}) {
 
 func (e *LogrusEntry) Error(args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusErrorLevel) {
-		message := e.logger.formatMessage(sprint(args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusErrorLevel))
+		_ = e.send(sprint(args...), mapLogrusLevel(LogrusErrorLevel))
 	}
 }
 
 func (e *LogrusEntry) Fatal(args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusFatalLevel) {
-		message := e.logger.formatMessage(sprint(args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusFatalLevel))
+		_ = e.send(sprint(args...), mapLogrusLevel(LogrusFatalLevel))
 	}
-	os.Exit(1)
+	exitFatal(e.logger.client)
 }
 
 func (e *LogrusEntry) Panic(args ...interface{}) {
-	message := e.logger.formatMessage(sprint(args...), e.Data)
+	raw := sprint(args...)
 	if e.logger.IsLevelEnabled(LogrusPanicLevel) {
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusPanicLevel))
+		_ = e.send(raw, mapLogrusLevel(LogrusPanicLevel))
 	}
-	panic(message)
+	panic(e.logger.formatMessage(raw, e.Data))
 }
 
 // Formatted log methods for LogrusEntry
 func (e *LogrusEntry) Tracef(format string, args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusTraceLevel) {
-		message := e.logger.formatMessage(sprintf(format, args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusTraceLevel))
+		_ = e.send(sprintf(format, args...), mapLogrusLevel(LogrusTraceLevel))
 	}
 }
 
 func (e *LogrusEntry) Debugf(format string, args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusDebugLevel) {
-		message := e.logger.formatMessage(sprintf(format, args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusDebugLevel))
+		_ = e.send(sprintf(format, args...), mapLogrusLevel(LogrusDebugLevel))
 	}
 }
 
 func (e *LogrusEntry) Infof(format string, args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusInfoLevel) {
-		message := e.logger.formatMessage(sprintf(format, args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusInfoLevel))
+		_ = e.send(sprintf(format, args...), mapLogrusLevel(LogrusInfoLevel))
 	}
 }
 
 func (e *LogrusEntry) Warnf(format string, args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusWarnLevel) {
-		message := e.logger.formatMessage(sprintf(format, args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusWarnLevel))
+		_ = e.send(sprintf(format, args...), mapLogrusLevel(LogrusWarnLevel))
 	}
 }
 
@@ -462,53 +459,47 @@ func (e *LogrusEntry) Warningf(format string, args ...interface{}) {
 
 func (e *LogrusEntry) Errorf(format string, args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusErrorLevel) {
-		message := e.logger.formatMessage(sprintf(format, args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusErrorLevel))
+		_ = e.send(sprintf(format, args...), mapLogrusLevel(LogrusErrorLevel))
 	}
 }
 
 func (e *LogrusEntry) Fatalf(format string, args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusFatalLevel) {
-		message := e.logger.formatMessage(sprintf(format, args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusFatalLevel))
+		_ = e.send(sprintf(format, args...), mapLogrusLevel(LogrusFatalLevel))
 	}
-	os.Exit(1)
+	exitFatal(e.logger.client)
 }
 
 func (e *LogrusEntry) Panicf(format string, args ...interface{}) {
-	message := e.logger.formatMessage(sprintf(format, args...), e.Data)
+	raw := sprintf(format, args...)
 	if e.logger.IsLevelEnabled(LogrusPanicLevel) {
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusPanicLevel))
+		_ = e.send(raw, mapLogrusLevel(LogrusPanicLevel))
 	}
-	panic(message)
+	panic(e.logger.formatMessage(raw, e.Data))
 }
 
 // Line log methods for LogrusEntry
 func (e *LogrusEntry) Traceln(args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusTraceLevel) {
-		message := e.logger.formatMessage(sprintln(args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusTraceLevel))
+		_ = e.send(sprintln(args...), mapLogrusLevel(LogrusTraceLevel))
 	}
 }
 
 func (e *LogrusEntry) Debugln(args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusDebugLevel) {
-		message := e.logger.formatMessage(sprintln(args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusDebugLevel))
+		_ = e.send(sprintln(args...), mapLogrusLevel(LogrusDebugLevel))
 	}
 }
 
 func (e *LogrusEntry) Infoln(args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusInfoLevel) {
-		message := e.logger.formatMessage(sprintln(args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusInfoLevel))
+		_ = e.send(sprintln(args...), mapLogrusLevel(LogrusInfoLevel))
 	}
 }
 
 func (e *LogrusEntry) Warnln(args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusWarnLevel) {
-		message := e.logger.formatMessage(sprintln(args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusWarnLevel))
+		_ = e.send(sprintln(args...), mapLogrusLevel(LogrusWarnLevel))
 	}
 }
 
@@ -518,25 +509,23 @@ func (e *LogrusEntry) Warningln(args ...interface{}) {
 
 func (e *LogrusEntry) Errorln(args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusErrorLevel) {
-		message := e.logger.formatMessage(sprintln(args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusErrorLevel))
+		_ = e.send(sprintln(args...), mapLogrusLevel(LogrusErrorLevel))
 	}
 }
 
 func (e *LogrusEntry) Fatalln(args ...interface{}) {
 	if e.logger.IsLevelEnabled(LogrusFatalLevel) {
-		message := e.logger.formatMessage(sprintln(args...), e.Data)
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusFatalLevel))
+		_ = e.send(sprintln(args...), mapLogrusLevel(LogrusFatalLevel))
 	}
-	os.Exit(1)
+	exitFatal(e.logger.client)
 }
 
 func (e *LogrusEntry) Panicln(args ...interface{}) {
-	message := e.logger.formatMessage(sprintln(args...), e.Data)
+	raw := sprintln(args...)
 	if e.logger.IsLevelEnabled(LogrusPanicLevel) {
-		_ = e.logger.client.SendLogWithTimestampAndLevel(message, e.Time, mapLogrusLevel(LogrusPanicLevel))
+		_ = e.send(raw, mapLogrusLevel(LogrusPanicLevel))
 	}
-	panic(message)
+	panic(e.logger.formatMessage(raw, e.Data))
 }
 
 // Helper functions to match fmt package behavior
@@ -0,0 +1,160 @@
+package adapters
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// SlogHandler is a log/slog Handler that sends records to LogFlux. Groups
+// become dotted attribute keys. With a client implementing AttributeLogger
+// (such as *logflux.Hub) attributes keep their native types; otherwise they
+// are appended to the message as key=value text.
+//
+// Usage:
+//
+//	logger := slog.New(adapters.NewSlogHandler(logflux.CurrentHub(), nil))
+//	logger.Info("request", "status", 200, "latency", elapsed)
+type SlogHandler struct {
+	client LoggerInterface
+	level  slog.Leveler
+	attrs  payload.Attributes // attributes from WithAttrs, never mutated
+	prefix string             // dotted group prefix, e.g. "http."
+}
+
+// NewSlogHandler creates a slog handler. opts may be nil; only opts.Level is
+// used (default: slog.LevelInfo).
+func NewSlogHandler(client LoggerInterface, opts *slog.HandlerOptions) *SlogHandler {
+	h := &SlogHandler{client: client, level: slog.LevelInfo}
+	if opts != nil && opts.Level != nil {
+		h.level = opts.Level
+	}
+	return h
+}
+
+// mapSlogLevel converts slog levels to LogFlux levels.
+func mapSlogLevel(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return models.LogLevelDebug
+	case level < slog.LevelWarn:
+		return models.LogLevelInfo
+	case level < slog.LevelError:
+		return models.LogLevelWarning
+	case level == slog.LevelError:
+		return models.LogLevelError
+	default:
+		return models.LogLevelCritical
+	}
+}
+
+// Enabled reports whether level is at or above the handler's level.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle sends the record.
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := h.attrs.Clone()
+	if attrs == nil {
+		attrs = make(payload.Attributes, r.NumAttrs())
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(attrs, h.prefix, a)
+		return true
+	})
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	level := mapSlogLevel(r.Level)
+	if al, ok := h.client.(AttributeLogger); ok {
+		return al.SendLogWithAttributes(r.Message, ts, level, attrs)
+	}
+	return h.client.SendLogWithTimestampAndLevel(formatSlogMessage(r.Message, attrs), ts, level)
+}
+
+// WithAttrs returns a handler that adds attrs to every record.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = h.attrs.Clone()
+	if h2.attrs == nil {
+		h2.attrs = make(payload.Attributes, len(attrs))
+	}
+	for _, a := range attrs {
+		addSlogAttr(h2.attrs, h.prefix, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a handler that nests subsequent attributes under name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// addSlogAttr stores a under prefix, flattening groups to dotted keys.
+func addSlogAttr(attrs payload.Attributes, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		group := v.Group()
+		if len(group) == 0 {
+			return
+		}
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range group {
+			addSlogAttr(attrs, prefix, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	key := prefix + a.Key
+	switch v.Kind() {
+	case slog.KindString:
+		attrs[key] = v.String()
+	case slog.KindInt64:
+		attrs[key] = v.Int64()
+	case slog.KindFloat64:
+		attrs.Set(key, v.Float64())
+	case slog.KindBool:
+		attrs[key] = v.Bool()
+	default:
+		attrs.Set(key, v.Any())
+	}
+}
+
+// formatSlogMessage appends attributes as sorted key=value pairs.
+func formatSlogMessage(message string, attrs payload.Attributes) string {
+	if len(attrs) == 0 {
+		return message
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys)+1)
+	if message != "" {
+		parts = append(parts, message)
+	}
+	for _, k := range keys {
+		parts = append(parts, k+"="+formatValue(attrs[k]))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,98 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// fakeAttrClient records entries sent with typed attributes.
+type fakeAttrClient struct {
+	fakeClient
+	entries []attrEntry
+}
+
+type attrEntry struct {
+	msg   string
+	lvl   int
+	attrs payload.Attributes
+}
+
+func (f *fakeAttrClient) SendLogWithAttributes(message string, _ time.Time, level int, attrs payload.Attributes) error {
+	f.entries = append(f.entries, attrEntry{message, level, attrs})
+	return nil
+}
+
+func TestSlogHandler_TypedAttributes(t *testing.T) {
+	f := &fakeAttrClient{}
+	logger := slog.New(NewSlogHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug})).
+		With("service", "api").
+		WithGroup("http")
+
+	logger.Debug("request",
+		"status", 200,
+		"latency", 1500*time.Microsecond,
+		"cached", true,
+		slog.Group("req", "method", "GET"),
+		"err", errors.New("boom"),
+	)
+
+	if len(f.entries) != 1 || len(f.calls) != 0 {
+		t.Fatalf("entries=%d text calls=%d", len(f.entries), len(f.calls))
+	}
+	got := f.entries[0]
+	want := payload.Attributes{
+		"service":         "api",
+		"http.status":     int64(200),
+		"http.latency":    1.5,
+		"http.cached":     true,
+		"http.req.method": "GET",
+		"http.err":        "boom",
+	}
+	if got.msg != "request" || got.lvl != models.LogLevelDebug || !reflect.DeepEqual(got.attrs, want) {
+		t.Fatalf("got %q %d %#v", got.msg, got.lvl, got.attrs)
+	}
+}
+
+func TestSlogHandler_TextFallbackAndLevels(t *testing.T) {
+	f := &fakeClient{}
+	logger := slog.New(NewSlogHandler(f, nil))
+
+	logger.Debug("dropped")
+	logger.Warn("slow", "ms", 12, "db", "main")
+	logger.Log(context.Background(), slog.LevelError+4, "down")
+
+	if len(f.calls) != 2 {
+		t.Fatalf("unexpected calls: %#v", f.calls)
+	}
+	if f.calls[0].msg != "slow db=main ms=12" || f.calls[0].lvl != models.LogLevelWarning {
+		t.Errorf("call 0 = %#v", f.calls[0])
+	}
+	if f.calls[1].lvl != models.LogLevelCritical {
+		t.Errorf("level above error = %d, want critical", f.calls[1].lvl)
+	}
+}
+
+func TestAdapters_SendTypedFields(t *testing.T) {
+	f := &fakeAttrClient{}
+
+	NewZapLogger(f).Info("zap", ZapField{Key: "count", Value: 3})
+	NewLogrusLogger(f).WithField("ratio", 0.5).Info("logrus")
+	NewZerologLogger(f).Info().Bool("ok", true).Msg("zerolog")
+
+	want := []payload.Attributes{{"count": int64(3)}, {"ratio": 0.5}, {"ok": true}}
+	if len(f.entries) != len(want) {
+		t.Fatalf("entries = %#v", f.entries)
+	}
+	for i, w := range want {
+		if !reflect.DeepEqual(f.entries[i].attrs, w) {
+			t.Errorf("entry %d (%s) attrs = %#v, want %#v", i, f.entries[i].msg, f.entries[i].attrs, w)
+		}
+	}
+}
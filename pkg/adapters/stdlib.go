@@ -4,12 +4,12 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
 )
 
 // LoggerInterface defines the interface that clients must implement
@@ -18,6 +18,37 @@ type LoggerInterface interface {
 	Close() error
 }
 
+// AttributeLogger is implemented by clients that accept typed attributes,
+// such as *logflux.Hub. Adapters whose client implements it send structured
+// fields as native attribute values (numbers stay numbers) instead of
+// appending key=value text to the message.
+type AttributeLogger interface {
+	SendLogWithAttributes(message string, timestamp time.Time, level int, attrs payload.Attributes) error
+}
+
+// sendStructured sends message with fields through c: as typed attributes if
+// c implements AttributeLogger, otherwise as the text produced by format.
+func sendStructured(c LoggerInterface, ts time.Time, level int, message string, fields map[string]interface{}, format func() string) error {
+	if al, ok := c.(AttributeLogger); ok {
+		attrs := make(payload.Attributes, len(fields))
+		for k, v := range fields {
+			attrs.Set(k, v)
+		}
+		return al.SendLogWithAttributes(message, ts, level, attrs)
+	}
+	return c.SendLogWithTimestampAndLevel(format(), ts, level)
+}
+
+// exitFatal ends the process after a fatal log. Clients that queue entries,
+// such as *client.ResilientClient and *logflux.Hub, are flushed first for up
+// to client.FatalFlushTimeout so the fatal message is not lost.
+func exitFatal(c LoggerInterface) {
+	if f, ok := c.(interface{ Flush(time.Duration) error }); ok {
+		_ = f.Flush(client.FatalFlushTimeout)
+	}
+	client.Exit(1)
+}
+
 // StdlibLogger provides a drop-in replacement for the standard library log.Logger
 type StdlibLogger struct {
 	client LoggerInterface
@@ -87,25 +118,25 @@ func (l *StdlibLogger) Println(v ...interface{}) {
 	_ = l.client.SendLogWithTimestampAndLevel(message, time.Now(), models.LogLevelInfo)
 }
 
-// Fatal is equivalent to Print() followed by a call to os.Exit(1)
+// Fatal is equivalent to Print() followed by a flush and a call to os.Exit(1)
 func (l *StdlibLogger) Fatal(v ...interface{}) {
 	message := l.prefix + fmt.Sprint(v...)
 	_ = l.client.SendLogWithTimestampAndLevel(message, time.Now(), models.LogLevelCritical)
-	os.Exit(1)
+	exitFatal(l.client)
 }
 
-// Fatalf is equivalent to Printf() followed by a call to os.Exit(1)
+// Fatalf is equivalent to Printf() followed by a flush and a call to os.Exit(1)
 func (l *StdlibLogger) Fatalf(format string, v ...interface{}) {
 	message := l.prefix + fmt.Sprintf(format, v...)
 	_ = l.client.SendLogWithTimestampAndLevel(message, time.Now(), models.LogLevelCritical)
-	os.Exit(1)
+	exitFatal(l.client)
 }
 
-// Fatalln is equivalent to Println() followed by a call to os.Exit(1)
+// Fatalln is equivalent to Println() followed by a flush and a call to os.Exit(1)
 func (l *StdlibLogger) Fatalln(v ...interface{}) {
 	message := l.prefix + fmt.Sprintln(v...)
 	_ = l.client.SendLogWithTimestampAndLevel(message, time.Now(), models.LogLevelCritical)
-	os.Exit(1)
+	exitFatal(l.client)
 }
 
 // Panic is equivalent to Print() followed by a call to panic()
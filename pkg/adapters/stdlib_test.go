@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
 )
 
@@ -67,3 +68,37 @@ func TestStdlibLogger_PanicAndFatal(t *testing.T) {
 		t.Fatalf("expected panic")
 	}
 }
+
+// flushingClient records flushes, like a queueing client would get them.
+type flushingClient struct {
+	fakeClient
+	flushes int
+}
+
+func (f *flushingClient) Flush(time.Duration) error { f.flushes++; return nil }
+
+func TestAdapters_FatalFlushesAndExits(t *testing.T) {
+	var codes []int
+	prev := client.SetExitFunc(func(code int) { codes = append(codes, code) })
+	defer client.SetExitFunc(prev)
+
+	f := &flushingClient{}
+	NewStdlibLogger(f, "").Fatal("stdlib")
+	NewLogrusLogger(f).WithField("k", "v").Fatalf("%s", "logrus")
+	NewZapLogger(f).Fatal("zap")
+	NewZerologLogger(f).Fatal().Msg("zerolog")
+
+	if len(f.calls) != 4 || f.flushes != 4 || len(codes) != 4 {
+		t.Fatalf("calls=%d flushes=%d exits=%v", len(f.calls), f.flushes, codes)
+	}
+	for _, c := range codes {
+		if c != 1 {
+			t.Errorf("exit code %d, want 1", c)
+		}
+	}
+	for _, c := range f.calls {
+		if c.lvl != models.LogLevelCritical {
+			t.Errorf("%q sent at level %d", c.msg, c.lvl)
+		}
+	}
+}
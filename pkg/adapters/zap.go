@@ -2,12 +2,12 @@ package adapters
 
 import (
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
 )
 
 // ZapLevel represents zap log levels
@@ -80,6 +80,19 @@ func mapZapLevel(level ZapLevel) int {
 	}
 }
 
+// send delivers an entry. Clients implementing AttributeLogger receive the
+// fields as typed attributes; others get them appended to the message.
+func (l *ZapLogger) send(message string, fields []ZapField, level int) error {
+	if al, ok := l.client.(AttributeLogger); ok {
+		attrs := make(payload.Attributes, len(fields))
+		for _, f := range fields {
+			attrs.Set(f.Key, f.Value)
+		}
+		return al.SendLogWithAttributes(message, time.Now(), level, attrs)
+	}
+	return l.client.SendLogWithTimestampAndLevel(l.formatMessage(message, fields), time.Now(), level)
+}
+
 // formatMessage formats a message with structured fields
 func (l *ZapLogger) formatMessage(message string, fields []ZapField) string {
 	if len(fields) == 0 {
@@ -173,40 +186,35 @@ func (l *ZapLogger) Check(level ZapLevel, message string) *ZapLogger {
 func (l *ZapLogger) Debug(message string, fields ...ZapField) {
 	if l.level <= ZapDebugLevel {
 		allFields := append(l.fields, fields...)
-		msg := l.formatMessage(message, allFields)
-		_ = l.client.SendLogWithTimestampAndLevel(msg, time.Now(), mapZapLevel(ZapDebugLevel))
+		_ = l.send(message, allFields, mapZapLevel(ZapDebugLevel))
 	}
 }
 
 func (l *ZapLogger) Info(message string, fields ...ZapField) {
 	if l.level <= ZapInfoLevel {
 		allFields := append(l.fields, fields...)
-		msg := l.formatMessage(message, allFields)
-		_ = l.client.SendLogWithTimestampAndLevel(msg, time.Now(), mapZapLevel(ZapInfoLevel))
+		_ = l.send(message, allFields, mapZapLevel(ZapInfoLevel))
 	}
 }
 
 func (l *ZapLogger) Warn(message string, fields ...ZapField) {
 	if l.level <= ZapWarnLevel {
 		allFields := append(l.fields, fields...)
-		msg := l.formatMessage(message, allFields)
-		_ = l.client.SendLogWithTimestampAndLevel(msg, time.Now(), mapZapLevel(ZapWarnLevel))
+		_ = l.send(message, allFields, mapZapLevel(ZapWarnLevel))
 	}
 }
 
 func (l *ZapLogger) Error(message string, fields ...ZapField) {
 	if l.level <= ZapErrorLevel {
 		allFields := append(l.fields, fields...)
-		msg := l.formatMessage(message, allFields)
-		_ = l.client.SendLogWithTimestampAndLevel(msg, time.Now(), mapZapLevel(ZapErrorLevel))
+		_ = l.send(message, allFields, mapZapLevel(ZapErrorLevel))
 	}
 }
 
 func (l *ZapLogger) DPanic(message string, fields ...ZapField) {
 	if l.level <= ZapDPanicLevel {
 		allFields := append(l.fields, fields...)
-		msg := l.formatMessage(message, allFields)
-		_ = l.client.SendLogWithTimestampAndLevel(msg, time.Now(), mapZapLevel(ZapDPanicLevel))
+		_ = l.send(message, allFields, mapZapLevel(ZapDPanicLevel))
 		// In development, this would panic, but we'll skip for compatibility
 	}
 }
@@ -214,19 +222,17 @@ func (l *ZapLogger) DPanic(message string, fields ...ZapField) {
 func (l *ZapLogger) Panic(message string, fields ...ZapField) {
 	if l.level <= ZapPanicLevel {
 		allFields := append(l.fields, fields...)
-		msg := l.formatMessage(message, allFields)
-		_ = l.client.SendLogWithTimestampAndLevel(msg, time.Now(), mapZapLevel(ZapPanicLevel))
-		panic(msg)
+		_ = l.send(message, allFields, mapZapLevel(ZapPanicLevel))
+		panic(l.formatMessage(message, allFields))
 	}
 }
 
 func (l *ZapLogger) Fatal(message string, fields ...ZapField) {
 	if l.level <= ZapFatalLevel {
 		allFields := append(l.fields, fields...)
-		msg := l.formatMessage(message, allFields)
-		_ = l.client.SendLogWithTimestampAndLevel(msg, time.Now(), mapZapLevel(ZapFatalLevel))
+		_ = l.send(message, allFields, mapZapLevel(ZapFatalLevel))
 	}
-	os.Exit(1)
+	exitFatal(l.client)
 }
 
 func (l *ZapLogger) Sync() error {
@@ -392,12 +392,16 @@ func (l *ZerologLogger) Log() *ZerologEvent {
 // Print methods for printf-style logging
 func (l *ZerologLogger) Print(v ...interface{}) {
 	msg := fmt.Sprint(v...)
-	_ = l.client.SendLogWithTimestampAndLevel(l.formatZerologMessage(msg, l.fields), time.Now(), models.LogLevelInfo)
+	_ = sendStructured(l.client, time.Now(), models.LogLevelInfo, msg, l.fields, func() string {
+		return l.formatZerologMessage(msg, l.fields)
+	})
 }
 
 func (l *ZerologLogger) Printf(format string, v ...interface{}) {
 	msg := fmt.Sprintf(format, v...)
-	_ = l.client.SendLogWithTimestampAndLevel(l.formatZerologMessage(msg, l.fields), time.Now(), models.LogLevelInfo)
+	_ = sendStructured(l.client, time.Now(), models.LogLevelInfo, msg, l.fields, func() string {
+		return l.formatZerologMessage(msg, l.fields)
+	})
 }
 
 func (l *ZerologLogger) Close() error {
@@ -572,8 +576,12 @@ func (e *ZerologEvent) Msg(msg string) {
 		allFields[k] = v
 	}
 
-	finalMsg := e.logger.formatZerologMessage(msg, allFields)
-	_ = e.logger.client.SendLogWithTimestampAndLevel(finalMsg, time.Now(), mapZerologLevel(e.level))
+	_ = sendStructured(e.logger.client, time.Now(), mapZerologLevel(e.level), msg, allFields, func() string {
+		return e.logger.formatZerologMessage(msg, allFields)
+	})
+	if e.level == ZerologFatalLevel {
+		exitFatal(e.logger.client)
+	}
 }
 
 func (e *ZerologEvent) Msgf(format string, v ...interface{}) {
@@ -11,11 +11,14 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/config"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/crypto"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/sdkversion"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/diag"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/discovery"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/handshake"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
@@ -43,6 +46,24 @@ type ClientConfig struct {
 	DiscoveryTimeout  time.Duration
 	CustomEndpointURL string
 	BeforeSend        BeforeSendFunc
+
+	// Failover and re-discovery
+	FallbackEndpointURLs     []string      // Extra ingest base URLs tried after the discovered ones
+	DiscoveryRefreshInterval time.Duration // Re-run discovery before a send once this old (0 disables)
+	FailoverCooldown         time.Duration // How long a failed endpoint is skipped (default 30s)
+
+	// DiscoveryCache, when set, serves discovery results from disk
+	// (stale-while-revalidate) to speed up and harden start-up.
+	DiscoveryCache *discovery.FileCache
+
+	// HTTP transport. HTTPClient is used as-is when set; otherwise the
+	// client is built from Transport, or from TransportOptions.
+	HTTPClient       *http.Client
+	Transport        http.RoundTripper
+	TransportOptions TransportOptions
+
+	// Logger receives SDK diagnostic events (nil disables them).
+	Logger diag.Logger
 }
 
 // Client is a synchronous LogFlux client (blocks until HTTP response).
@@ -67,7 +88,19 @@ type Client struct {
 	rateLimitReset     int64
 
 	discoveryClient *discovery.DiscoveryClient
-	endpoints       *discovery.EndpointInfo
+	pool            *discovery.EndpointPool
+	session         *discovery.EndpointInfo // endpoint the current key was negotiated with
+	refreshInterval time.Duration
+	refreshing      atomic.Bool // a background discovery refresh is running
+
+	// mu guards the session state (encryptor, key, session endpoint and
+	// server key) and the rate limit fields. It is held for reading while a
+	// body is encrypted, so a re-handshake cannot zero a key in use;
+	// failoverMu serializes re-handshakes.
+	mu         sync.RWMutex
+	failoverMu sync.Mutex
+
+	log diag.Logger
 }
 
 func NewClient(apiKey, node string) (*Client, error) {
@@ -97,19 +130,26 @@ func NewClientWithConfig(cfg ClientConfig) (*Client, error) {
 		return nil, err
 	}
 
-	httpClient := &http.Client{Timeout: cfg.HTTPTimeout}
-	if httpClient.Timeout == 0 {
-		httpClient.Timeout = 30 * time.Second
+	httpTimeout := cfg.HTTPTimeout
+	if httpTimeout == 0 {
+		httpTimeout = 30 * time.Second
+	}
+	httpClient, err := newHTTPClient(cfg.HTTPClient, cfg.Transport, cfg.TransportOptions, httpTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transport configuration: %w", err)
 	}
+	log := diag.OrNop(cfg.Logger)
 
 	var discoveryClient *discovery.DiscoveryClient
 	var endpoints *discovery.EndpointInfo
+	var staleEndpoints bool
 
 	if cfg.CustomEndpointURL != "" {
 		discoveryClient = discovery.NewDiscoveryClient(discovery.DiscoveryConfig{
 			APIKey:     cfg.APIKey,
 			Timeout:    10 * time.Second,
 			HTTPClient: httpClient,
+			Logger:     log,
 		})
 		endpoints = discoveryClient.SetCustomEndpoint(cfg.CustomEndpointURL)
 	} else {
@@ -121,11 +161,12 @@ func NewClientWithConfig(cfg ClientConfig) (*Client, error) {
 			APIKey:     cfg.APIKey,
 			Timeout:    discoveryTimeout,
 			HTTPClient: httpClient,
+			Cache:      cfg.DiscoveryCache,
+			Logger:     log,
 		})
 		ctx, cancel := context.WithTimeout(context.Background(), discoveryTimeout)
 		defer cancel()
-		var err error
-		endpoints, err = discoveryClient.DiscoverEndpoints(ctx, "")
+		endpoints, staleEndpoints, err = discoveryClient.DiscoverEndpointsCached(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("endpoint discovery failed: %w", err)
 		}
@@ -140,15 +181,37 @@ func NewClientWithConfig(cfg ClientConfig) (*Client, error) {
 		}
 	}
 
-	handshakeURL := endpoints.GetHandshakeURL()
-	handshakeResult, err := handshake.PerformHandshakeWithURL(handshakeURL, cfg.APIKey, httpClient)
+	pool := discovery.NewEndpointPool(endpoints, cfg.FallbackEndpointURLs)
+	pool.SetCooldown(cfg.FailoverCooldown)
+	if staleEndpoints {
+		// Served from a stale cache entry: revalidate without blocking start-up.
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := discoveryClient.RefreshPool(ctx, pool); err != nil {
+				log.Debug("endpoint refresh failed, keeping cached endpoints", "error", err)
+			}
+		}()
+	}
+
+	handshakeResult, session, err := handshakeWithFailover(pool, cfg.APIKey, httpClient, log)
+	if errors.Is(err, handshake.ErrIngestorUnavailable) && cfg.CustomEndpointURL == "" && discoveryClient.IsCached(endpoints) {
+		if p, r, s, rerr := rediscoverAndHandshake(discoveryClient, cfg.FallbackEndpointURLs, cfg.FailoverCooldown, cfg.APIKey, httpClient, log); p != nil {
+			pool, handshakeResult, session, err = p, r, s, rerr
+		}
+	}
 	if err != nil {
 		if errors.Is(err, handshake.ErrIngestorUnavailable) {
-			return nil, fmt.Errorf("cannot connect to %s: %v", endpoints.BaseURL, err)
+			return nil, fmt.Errorf("cannot connect to %s: %v", session.BaseURL, err)
 		}
 		return nil, fmt.Errorf("handshake failed: %w", err)
 	}
 
+	refreshInterval := cfg.DiscoveryRefreshInterval
+	if cfg.CustomEndpointURL != "" {
+		refreshInterval = 0
+	}
+
 	enc := crypto.NewEncryptor(handshakeResult.AESKey)
 	// Zero source key material after the encryptor has its own copy
 	for i := range handshakeResult.AESKey {
@@ -169,7 +232,10 @@ func NewClientWithConfig(cfg ClientConfig) (*Client, error) {
 		serverPublicKeyPEM:   handshakeResult.ServerPublicKeyPEM,
 		serverKeyFingerprint: handshakeResult.ServerKeyFingerprint,
 		discoveryClient:      discoveryClient,
-		endpoints:            endpoints,
+		pool:                 pool,
+		session:              session,
+		refreshInterval:      refreshInterval,
+		log:                  log,
 	}, nil
 }
 
@@ -178,19 +244,25 @@ func NewClientFromEnv(node string) (*Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	rc := ResilientClientConfigFromConfig(cfg)
+	if node != "" {
+		rc.Node = node
+	}
 	return NewClientWithConfig(ClientConfig{
-		APIKey:            cfg.APIKey,
-		Node:              node,
-		Environment:       cfg.Environment,
-		LogGroup:          cfg.LogGroup,
-		EnableCompression: cfg.EnableCompression,
-		DiscoveryTimeout:  10 * time.Second,
-		HTTPTimeout: func() time.Duration {
-			if cfg.HTTPTimeout > 0 {
-				return time.Duration(cfg.HTTPTimeout) * time.Second
-			}
-			return 30 * time.Second
-		}(),
+		APIKey:                   rc.APIKey,
+		Node:                     rc.Node,
+		Environment:              rc.Environment,
+		LogGroup:                 rc.LogGroup,
+		CustomEndpointURL:        rc.CustomEndpointURL,
+		FallbackEndpointURLs:     rc.FallbackEndpointURLs,
+		DiscoveryRefreshInterval: time.Duration(cfg.DiscoveryRefreshInterval) * time.Second,
+		FailoverCooldown:         rc.FailoverCooldown,
+		DiscoveryCache:           rc.DiscoveryCache,
+		TransportOptions:         rc.TransportOptions,
+		EnableCompression:        rc.EnableCompression,
+		DiscoveryTimeout:         10 * time.Second,
+		Logger:                   rc.Logger,
+		HTTPTimeout:              rc.HTTPTimeout,
 	})
 }
 
@@ -270,11 +342,7 @@ func (c *Client) SendEntry(entry models.LogEntry) error {
 		entry = *result
 	}
 
-	body, contentType, err := c.buildMultipartBody([]models.LogEntry{entry})
-	if err != nil {
-		return err
-	}
-	return c.doIngest(body, contentType)
+	return c.sendEntries([]models.LogEntry{entry})
 }
 
 // SendLogBatch sends multiple entries in a single multipart/mixed request.
@@ -319,25 +387,130 @@ func (c *Client) SendLogBatch(messages []LogMessage) error {
 		return nil
 	}
 
-	body, contentType, err := c.buildMultipartBody(entries)
+	return c.sendEntries(entries)
+}
+
+// sendEntries encrypts and sends entries, failing over to the next endpoint
+// (with a fresh handshake) while the current one is unreachable.
+func (c *Client) sendEntries(entries []models.LogEntry) error {
+	c.maybeRefreshEndpoints()
+
+	var lastErr error
+	for attempt := 0; attempt < c.pool.Len(); attempt++ {
+		if err := c.ensureSession(); err != nil {
+			lastErr = err
+			continue
+		}
+		body, contentType, session, err := c.buildMultipartBody(entries)
+		if err != nil {
+			return err
+		}
+		err = c.doIngest(session, body, contentType)
+		if !c.shouldFailover(err) {
+			return err
+		}
+		lastErr = err
+		if _, switched := markFailed(c.pool, session.BaseURL, err, c.log); !switched {
+			break
+		}
+	}
+	return lastErr
+}
+
+// shouldFailover reports whether a send error means the endpoint is down.
+func (c *Client) shouldFailover(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, handshake.ErrIngestorUnavailable) {
+		return true
+	}
+	var statusErr *ingestStatusError
+	return errors.As(err, &statusErr) && isEndpointUnavailable(statusErr.statusCode)
+}
+
+// currentSession returns the endpoint the current key was negotiated with.
+func (c *Client) currentSession() *discovery.EndpointInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.session
+}
+
+// ensureSession re-handshakes when the pool has moved to a different endpoint.
+func (c *Client) ensureSession() error {
+	ep := c.pool.Current()
+	if session := c.currentSession(); session != nil && session.BaseURL == ep.BaseURL {
+		return nil
+	}
+
+	c.failoverMu.Lock()
+	defer c.failoverMu.Unlock()
+	// Another sender may have switched while we waited for the lock.
+	ep = c.pool.Current()
+	if session := c.currentSession(); session != nil && session.BaseURL == ep.BaseURL {
+		return nil
+	}
+	result, err := performHandshake(ep, c.apiKey, c.httpClient, c.log)
 	if err != nil {
-		return err
+		c.pool.MarkFailed(ep.BaseURL)
+		return fmt.Errorf("failover to %s: %w", ep.BaseURL, err)
+	}
+	enc := crypto.NewEncryptor(result.AESKey)
+	for i := range result.AESKey {
+		result.AESKey[i] = 0
+	}
+	c.mu.Lock()
+	old := c.encryptor
+	c.encryptor = enc
+	c.keyUUID = result.KeyUUID
+	c.limits = result.Limits
+	c.serverPublicKeyPEM = result.ServerPublicKeyPEM
+	c.serverKeyFingerprint = result.ServerKeyFingerprint
+	c.session = ep
+	// Senders encrypt under the read lock, so nobody still uses the old key.
+	if old != nil {
+		old.Close()
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// maybeRefreshEndpoints starts a background discovery refresh when the last
+// result is older than the configured refresh interval and none is running.
+// Sends keep using the current endpoints meanwhile; failures keep them.
+func (c *Client) maybeRefreshEndpoints() {
+	if c.refreshInterval <= 0 || time.Since(c.pool.RefreshedAt()) < c.refreshInterval {
+		return
 	}
-	return c.doIngest(body, contentType)
+	if !c.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer c.refreshing.Store(false)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := c.discoveryClient.RefreshPool(ctx, c.pool); err != nil {
+			c.log.Debug("endpoint refresh failed, keeping current endpoints", "error", err)
+		}
+	}()
 }
 
-// buildMultipartBody creates a multipart/mixed request body.
-func (c *Client) buildMultipartBody(entries []models.LogEntry) (*bytes.Buffer, string, error) {
+// buildMultipartBody creates a multipart/mixed request body. It also returns
+// the endpoint the encryption key belongs to, which the body must be sent to.
+func (c *Client) buildMultipartBody(entries []models.LogEntry) (*bytes.Buffer, string, *discovery.EndpointInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	b := &multipartBuilder{
 		encryptor:         c.encryptor,
 		keyUUID:           c.keyUUID,
 		enableCompression: c.enableCompression,
 	}
-	return b.build(entries)
+	body, contentType, err := b.build(entries)
+	return body, contentType, c.session, err
 }
 
-func (c *Client) doIngest(body *bytes.Buffer, contentType string) error {
-	req, err := http.NewRequest("POST", c.endpoints.GetIngestURL(), body)
+func (c *Client) doIngest(session *discovery.EndpointInfo, body *bytes.Buffer, contentType string) error {
+	req, err := http.NewRequest("POST", session.GetIngestURL(), body)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -347,7 +520,7 @@ func (c *Client) doIngest(body *bytes.Buffer, contentType string) error {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("%w: cannot connect to %s: %v", handshake.ErrIngestorUnavailable, c.endpoints.BaseURL, err)
+		return fmt.Errorf("%w: cannot connect to %s: %v", handshake.ErrIngestorUnavailable, session.BaseURL, err)
 	}
 	defer resp.Body.Close()
 
@@ -355,12 +528,25 @@ func (c *Client) doIngest(body *bytes.Buffer, contentType string) error {
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
 		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseSize))
-		return parseErrorResponse(resp.StatusCode, respBody, resp.Header)
+		return &ingestStatusError{
+			statusCode: resp.StatusCode,
+			err:        parseErrorResponse(resp.StatusCode, respBody, resp.Header),
+		}
 	}
 
 	return nil
 }
 
+// ingestStatusError keeps the HTTP status next to the parsed server error so
+// failover can tell endpoint outages from request errors.
+type ingestStatusError struct {
+	statusCode int
+	err        error
+}
+
+func (e *ingestStatusError) Error() string { return e.err.Error() }
+func (e *ingestStatusError) Unwrap() error { return e.err }
+
 func parseErrorResponse(statusCode int, body []byte, headers http.Header) error {
 	var errResp models.ErrorResponse
 	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Code != "" {
@@ -405,6 +591,8 @@ func (c *Client) SendLogWithEntryType(message string, level, entryType int) erro
 }
 
 func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.encryptor != nil {
 		c.encryptor.Close()
 	}
@@ -413,12 +601,25 @@ func (c *Client) Close() error {
 func (c *Client) SetTimeout(timeout time.Duration)               { c.httpClient.Timeout = timeout }
 func (c *Client) GetNodeName() string                            { return c.node }
 func (c *Client) GetAPIKeyMasked() string                        { return maskAPIKey(c.apiKey) }
-func (c *Client) GetServerPublicKeyFingerprint() string          { return c.serverKeyFingerprint }
-func (c *Client) GetServerPublicKeyPEM() string                  { return c.serverPublicKeyPEM }
+func (c *Client) GetServerPublicKeyFingerprint() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.serverKeyFingerprint
+}
+
+func (c *Client) GetServerPublicKeyPEM() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.serverPublicKeyPEM
+}
+
+
 func (c *Client) EnableCompressionMode(enable bool)              { c.enableCompression = enable }
 func (c *Client) IsCompressionEnabled() bool                     { return c.enableCompression }
 
 func (c *Client) updateRateLimitInfo(resp *http.Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if v := resp.Header.Get("X-RateLimit-Limit"); v != "" {
 		if val, err := strconv.Atoi(v); err == nil {
 			c.rateLimitLimit = val
@@ -436,12 +637,20 @@ func (c *Client) updateRateLimitInfo(resp *http.Response) {
 	}
 }
 
+// CurrentEndpoint returns the endpoint that currently receives traffic.
+func (c *Client) CurrentEndpoint() *discovery.EndpointInfo { return c.pool.Current() }
+
+// Endpoints returns all known ingest endpoints in priority order.
+func (c *Client) Endpoints() []*discovery.EndpointInfo { return c.pool.All() }
+
 func (c *Client) GetRateLimitInfo() (limit, remaining int, resetTime int64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.rateLimitLimit, c.rateLimitRemaining, c.rateLimitReset
 }
 
 func (c *Client) GetVersion() (map[string]interface{}, error) {
-	req, err := http.NewRequest("GET", c.endpoints.GetVersionURL(), nil)
+	req, err := http.NewRequest("GET", c.pool.Current().GetVersionURL(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -463,7 +672,7 @@ func (c *Client) GetVersion() (map[string]interface{}, error) {
 }
 
 func (c *Client) HealthCheck() error {
-	req, err := http.NewRequest("GET", c.endpoints.GetHealthURL(), nil)
+	req, err := http.NewRequest("GET", c.pool.Current().GetHealthURL(), nil)
 	if err != nil {
 		return err
 	}
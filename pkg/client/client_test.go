@@ -8,7 +8,12 @@ import (
 	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/config"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
 )
 
 // mock ingestor with handshake endpoints; bypass discovery by using custom endpoint URL
@@ -73,3 +78,94 @@ func TestClient_SendLog_Smoke(t *testing.T) {
 		t.Fatalf("Send info failed: %v", err)
 	}
 }
+
+func TestResilientClientConfigFromConfig(t *testing.T) {
+	cfg := ResilientClientConfigFromConfig(&config.Config{
+		APIKey:              "eu-lf_testkey123",
+		Node:                "node-1",
+		Source:              "billing",
+		Release:             "1.2.3",
+		SampleRate:          0.5,
+		FailoverCooldown:    45,
+		DiscoveryCache:      true,
+		DiscoveryCacheDir:   t.TempDir(),
+		DiscoveryCacheTTL:   60,
+		MaxConnsPerHost:     4,
+		IdleConnTimeout:     15,
+		DisableHTTP2:        true,
+		FlushInterval:       7,
+		InitialDelay:        250,
+		EnableCompression:   true,
+		MaxIdleConnsPerHost: 2,
+	})
+	if cfg.Node != "node-1" || cfg.Source != "billing" || cfg.Release != "1.2.3" || cfg.SampleRate != 0.5 {
+		t.Errorf("identity = %q/%q/%q/%v", cfg.Node, cfg.Source, cfg.Release, cfg.SampleRate)
+	}
+	if cfg.FailoverCooldown != 45*time.Second || cfg.FlushInterval != 7*time.Second ||
+		cfg.RetryConfig.InitialDelay != 250*time.Millisecond {
+		t.Errorf("durations = %v/%v/%v", cfg.FailoverCooldown, cfg.FlushInterval, cfg.RetryConfig.InitialDelay)
+	}
+	if cfg.DiscoveryCache == nil {
+		t.Error("expected a discovery cache")
+	}
+	tr := cfg.TransportOptions
+	if tr.MaxConnsPerHost != 4 || tr.MaxIdleConnsPerHost != 2 || tr.IdleConnTimeout != 15*time.Second || !tr.DisableHTTP2 {
+		t.Errorf("transport = %+v", tr)
+	}
+	if cfg.QueueSize != DefaultResilientClientConfig().QueueSize {
+		t.Errorf("QueueSize = %d, want default", cfg.QueueSize)
+	}
+}
+
+func TestResilientClient_SourceReleaseAndSampling(t *testing.T) {
+	srv := newFailoverServer(t)
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var sent []models.LogEntry
+	cfg := DefaultResilientClientConfig()
+	cfg.APIKey = "eu-lf_testkey123"
+	cfg.CustomEndpointURL = srv.URL
+	cfg.Environment = "production"
+	cfg.Source = "billing"
+	cfg.Release = "1.2.3"
+	cfg.SampleRate = 0.000001
+	cfg.BeforeSend = func(e *models.LogEntry) *models.LogEntry {
+		mu.Lock()
+		sent = append(sent, *e)
+		mu.Unlock()
+		return e
+	}
+	c, err := NewResilientClientWithHandshake(cfg)
+	if err != nil {
+		t.Fatalf("NewResilientClientWithHandshake: %v", err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 20; i++ {
+		_ = c.SendLogWithLabels("sampled out", map[string]string{"k": "v"})
+	}
+	_ = c.SendLogWithEntryType(`{"action":"login"}`, models.LogLevelNotice, models.EntryTypeAudit)
+
+	c.sampler = nil
+	_ = c.SendLogWithLabels("hello", map[string]string{"k": "v"})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 2 || sent[0].EntryType != models.EntryTypeAudit {
+		t.Fatalf("sent %d entries, want the audit entry and one log: %+v", len(sent), sent)
+	}
+	var log struct {
+		Source     string            `json:"source"`
+		Message    string            `json:"message"`
+		Attributes map[string]string `json:"attributes"`
+		Meta       map[string]string `json:"meta"`
+	}
+	if err := json.Unmarshal([]byte(sent[1].Message), &log); err != nil {
+		t.Fatalf("log is not a v2 payload: %v (%s)", err, sent[1].Message)
+	}
+	if log.Source != "billing" || log.Message != "hello" || log.Attributes["k"] != "v" ||
+		log.Meta["release"] != "1.2.3" || log.Meta["environment"] != "production" {
+		t.Errorf("log = %+v", log)
+	}
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/diag"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestResilientClient_DiagnosticLogging(t *testing.T) {
+	srv := newFailoverServer(t)
+	defer srv.Close()
+	srv.ingestStatus.Store(http.StatusTooManyRequests)
+
+	const apiKey = "eu-lf_secretkey123456"
+	var out syncBuffer
+	cfg := DefaultResilientClientConfig()
+	cfg.APIKey = apiKey
+	cfg.Node = "node"
+	cfg.CustomEndpointURL = srv.URL
+	cfg.FlushInterval = 10 * time.Millisecond
+	cfg.RetryConfig.MaxRetries = 0
+	cfg.Logger = diag.New(&out)
+
+	c, err := NewResilientClientWithHandshake(cfg)
+	if err != nil {
+		t.Fatalf("NewResilientClientWithHandshake: %v", err)
+	}
+	_ = c.Info("hello")
+	deadline := time.Now().Add(2 * time.Second)
+	for srv.ingests.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	c.cancel() // skip the rate-limit wait in Close's flush
+	_ = c.Close()
+
+	logs := out.String()
+	for _, want := range []string{`msg="handshake completed"`, `msg="client started"`, `msg="rate limited, pausing sends"`, `msg="client closed"`} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("missing %s in diagnostics:\n%s", want, logs)
+		}
+	}
+	if strings.Contains(logs, apiKey) {
+		t.Errorf("diagnostics leaked the API key:\n%s", logs)
+	}
+	if !strings.Contains(logs, maskAPIKey(apiKey)) {
+		t.Errorf("expected masked API key %q in diagnostics", maskAPIKey(apiKey))
+	}
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// FatalFlushTimeout bounds how long the SDK's Fatal functions wait for
+// queued entries to be sent before exiting.
+const FatalFlushTimeout = 5 * time.Second
+
+var exitFunc atomic.Pointer[func(code int)]
+
+// SetExitFunc replaces the function that the Fatal functions of logflux,
+// pkg/logger and pkg/adapters call to end the process (default: os.Exit)
+// and returns the previous one. Tests use it to observe fatal paths; nil
+// restores os.Exit.
+func SetExitFunc(fn func(code int)) func(code int) {
+	var prev *func(code int)
+	if fn == nil {
+		prev = exitFunc.Swap(nil)
+	} else {
+		prev = exitFunc.Swap(&fn)
+	}
+	if prev == nil {
+		return os.Exit
+	}
+	return *prev
+}
+
+// Exit ends the process through the function set with SetExitFunc.
+func Exit(code int) {
+	if fn := exitFunc.Load(); fn != nil {
+		(*fn)(code)
+		return
+	}
+	os.Exit(code)
+}
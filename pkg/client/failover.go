@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/diag"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/discovery"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/handshake"
+)
+
+// handshakeWithFailover performs the handshake against the pool's current
+// endpoint, moving on to the next one while endpoints are unreachable.
+// Returns the endpoint the session was negotiated with.
+func handshakeWithFailover(pool *discovery.EndpointPool, apiKey string, httpClient *http.Client, log diag.Logger) (*handshake.HandshakeResult, *discovery.EndpointInfo, error) {
+	var lastErr error
+	for i := 0; i < pool.Len(); i++ {
+		ep := pool.Current()
+		result, err := performHandshake(ep, apiKey, httpClient, log)
+		if err == nil {
+			pool.MarkHealthy(ep.BaseURL)
+			return result, ep, nil
+		}
+		lastErr = err
+		// Only connectivity problems move us to another endpoint; an auth or
+		// protocol error would fail the same way everywhere.
+		if !errors.Is(err, handshake.ErrIngestorUnavailable) {
+			return nil, ep, err
+		}
+		if _, switched := markFailed(pool, ep.BaseURL, err, log); !switched {
+			break
+		}
+	}
+	return nil, pool.Current(), lastErr
+}
+
+// rediscoverAndHandshake is the recovery for a start-up handshake that found
+// every cached endpoint unreachable: the ingestor may have been
+// decommissioned within the cache TTL. It runs live discovery, which
+// replaces the cache entry only on success, and handshakes once more with a
+// pool built from the result.
+func rediscoverAndHandshake(dc *discovery.DiscoveryClient, fallbacks []string, cooldown time.Duration, apiKey string, httpClient *http.Client, log diag.Logger) (*discovery.EndpointPool, *handshake.HandshakeResult, *discovery.EndpointInfo, error) {
+	log.Warn("cached endpoints unreachable, rediscovering")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	endpoints, err := dc.Rediscover(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pool := discovery.NewEndpointPool(endpoints, fallbacks)
+	pool.SetCooldown(cooldown)
+	result, session, err := handshakeWithFailover(pool, apiKey, httpClient, log)
+	return pool, result, session, err
+}
+
+// performHandshake handshakes with ep and reports the outcome to log.
+func performHandshake(ep *discovery.EndpointInfo, apiKey string, httpClient *http.Client, log diag.Logger) (*handshake.HandshakeResult, error) {
+	log.Debug("handshake started", "endpoint", ep.BaseURL, "api_key", maskAPIKey(apiKey))
+	result, err := handshake.PerformHandshakeWithURL(ep.GetHandshakeURL(), apiKey, httpClient)
+	if err != nil {
+		log.Warn("handshake failed", "endpoint", ep.BaseURL, "api_key", maskAPIKey(apiKey), "error", err)
+		return nil, err
+	}
+	log.Info("handshake completed", "endpoint", ep.BaseURL, "key_uuid", result.KeyUUID)
+	return result, nil
+}
+
+// markFailed puts an endpoint into cooldown and reports where traffic goes next.
+func markFailed(pool *discovery.EndpointPool, baseURL string, cause error, log diag.Logger) (*discovery.EndpointInfo, bool) {
+	next, switched := pool.MarkFailed(baseURL)
+	if switched {
+		log.Warn("endpoint unavailable, failing over", "endpoint", baseURL, "next", next.BaseURL, "error", cause)
+	} else {
+		log.Warn("endpoint unavailable, no healthy fallback", "endpoint", baseURL, "error", cause)
+	}
+	return next, switched
+}
+
+// isEndpointUnavailable reports whether an ingest status code means the
+// endpoint itself is unhealthy (as opposed to a problem with the request).
+func isEndpointUnavailable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,360 @@
+package client
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/discovery"
+)
+
+// failoverServer is a mock ingestor that counts handshakes and ingests and
+// can be told to answer ingest with a fixed status.
+type failoverServer struct {
+	*httptest.Server
+	handshakes   atomic.Int32
+	ingests      atomic.Int32
+	ingestStatus atomic.Int32
+}
+
+func newFailoverServer(t *testing.T) *failoverServer {
+	t.Helper()
+	s := newUnstartedFailoverServer(t)
+	s.Start()
+	return s
+}
+
+func newUnstartedFailoverServer(t *testing.T) *failoverServer {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	s := &failoverServer{}
+	s.ingestStatus.Store(http.StatusAccepted)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/handshake/init", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"public_key": string(pemBytes)})
+	})
+	mux.HandleFunc("/v1/handshake/complete", func(w http.ResponseWriter, r *http.Request) {
+		s.handshakes.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]string{"key_id": "test-key"})
+	})
+	mux.HandleFunc("/v1/ingest", func(w http.ResponseWriter, r *http.Request) {
+		s.ingests.Add(1)
+		w.WriteHeader(int(s.ingestStatus.Load()))
+	})
+	s.Server = httptest.NewUnstartedServer(mux)
+	return s
+}
+
+func TestClient_FailoverOnUnreachablePrimary(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close()
+
+	fallback := newFailoverServer(t)
+	defer fallback.Close()
+
+	c, err := NewClientWithConfig(ClientConfig{
+		APIKey:               "eu-lf_testkey123",
+		Node:                 "node",
+		CustomEndpointURL:    deadURL,
+		FallbackEndpointURLs: []string{fallback.URL},
+		HTTPTimeout:          2 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithConfig: %v", err)
+	}
+	if c.CurrentEndpoint().BaseURL != fallback.URL {
+		t.Fatalf("expected fallback endpoint, got %s", c.CurrentEndpoint().BaseURL)
+	}
+	if err := c.Info("hello"); err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if fallback.ingests.Load() != 1 {
+		t.Errorf("expected 1 ingest on fallback, got %d", fallback.ingests.Load())
+	}
+}
+
+func TestClient_FailoverOn503Rehandshakes(t *testing.T) {
+	primary := newFailoverServer(t)
+	defer primary.Close()
+	fallback := newFailoverServer(t)
+	defer fallback.Close()
+
+	c, err := NewClientWithConfig(ClientConfig{
+		APIKey:               "eu-lf_testkey123",
+		Node:                 "node",
+		CustomEndpointURL:    primary.URL,
+		FallbackEndpointURLs: []string{fallback.URL},
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithConfig: %v", err)
+	}
+
+	primary.ingestStatus.Store(http.StatusServiceUnavailable)
+	if err := c.Info("hello"); err != nil {
+		t.Fatalf("Info should succeed via fallback: %v", err)
+	}
+	if fallback.handshakes.Load() != 1 {
+		t.Errorf("expected re-handshake with fallback, got %d", fallback.handshakes.Load())
+	}
+	if fallback.ingests.Load() != 1 {
+		t.Errorf("expected ingest on fallback, got %d", fallback.ingests.Load())
+	}
+}
+
+func TestClient_NoFailoverOnRequestError(t *testing.T) {
+	primary := newFailoverServer(t)
+	defer primary.Close()
+	fallback := newFailoverServer(t)
+	defer fallback.Close()
+
+	c, err := NewClientWithConfig(ClientConfig{
+		APIKey:               "eu-lf_testkey123",
+		Node:                 "node",
+		CustomEndpointURL:    primary.URL,
+		FallbackEndpointURLs: []string{fallback.URL},
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithConfig: %v", err)
+	}
+
+	primary.ingestStatus.Store(http.StatusBadRequest)
+	if err := c.Info("hello"); err == nil {
+		t.Fatal("expected 400 to be returned")
+	}
+	if fallback.ingests.Load() != 0 {
+		t.Errorf("400 must not fail over, fallback got %d ingests", fallback.ingests.Load())
+	}
+}
+
+func TestClient_ConcurrentSendsShareOneRehandshake(t *testing.T) {
+	primary := newFailoverServer(t)
+	defer primary.Close()
+	fallback := newFailoverServer(t)
+	defer fallback.Close()
+
+	c, err := NewClientWithConfig(ClientConfig{
+		APIKey:               "eu-lf_testkey123",
+		Node:                 "node",
+		CustomEndpointURL:    primary.URL,
+		FallbackEndpointURLs: []string{fallback.URL},
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithConfig: %v", err)
+	}
+
+	primary.ingestStatus.Store(http.StatusServiceUnavailable)
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.Info("hello")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Info: %v", err)
+		}
+	}
+	if got := fallback.handshakes.Load(); got != 1 {
+		t.Errorf("expected one re-handshake with fallback, got %d", got)
+	}
+	if got := fallback.ingests.Load(); got != 8 {
+		t.Errorf("expected 8 ingests on fallback, got %d", got)
+	}
+}
+
+// blockingTransport holds every request until release is closed.
+type blockingTransport struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (b *blockingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	b.calls.Add(1)
+	select {
+	case <-b.release:
+	case <-r.Context().Done():
+	}
+	return nil, r.Context().Err()
+}
+
+func TestClient_EndpointRefreshRunsInBackground(t *testing.T) {
+	srv := newFailoverServer(t)
+	defer srv.Close()
+	c, err := NewClientWithConfig(ClientConfig{
+		APIKey:            "eu-lf_testkey123",
+		Node:              "node",
+		CustomEndpointURL: srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithConfig: %v", err)
+	}
+	// Discovery that hangs: a send must not wait for it.
+	slow := &blockingTransport{release: make(chan struct{})}
+	defer close(slow.release)
+	c.discoveryClient = discovery.NewDiscoveryClient(discovery.DiscoveryConfig{
+		APIKey:     "eu-lf_testkey123",
+		HTTPClient: &http.Client{Transport: slow},
+	})
+	c.refreshInterval = time.Nanosecond
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := c.Info("hello"); err != nil {
+			t.Fatalf("Info: %v", err)
+		}
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("sends took %v while discovery hung", d)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for slow.calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := slow.calls.Load(); got != 1 {
+		t.Errorf("expected a single refresh in flight, got %d discovery requests", got)
+	}
+}
+
+// discoveryRedirect sends requests for logflux.io hosts to a test server,
+// so that discovery can be exercised without the network.
+type discoveryRedirect struct{ target string }
+
+func (d discoveryRedirect) RoundTrip(r *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(r.URL.Hostname(), "logflux.io") {
+		u, _ := url.Parse(d.target)
+		r = r.Clone(r.Context())
+		r.URL.Scheme, r.URL.Host, r.Host = u.Scheme, u.Host, u.Host
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestConstructors_RediscoverWhenCachedEndpointIsGone(t *testing.T) {
+	live := newFailoverServer(t)
+	defer live.Close()
+	disco := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"endpoints": map[string]any{"ingestor_url": live.URL}})
+	}))
+	defer disco.Close()
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close()
+
+	const apiKey = "eu-lf_testkey123"
+	newCache := func(t *testing.T) *discovery.FileCache {
+		cache, err := discovery.NewFileCache(t.TempDir(), time.Hour, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.Store(apiKey, &discovery.EndpointInfo{BaseURL: deadURL}); err != nil {
+			t.Fatal(err)
+		}
+		return cache
+	}
+	check := func(t *testing.T, cache *discovery.FileCache, current string) {
+		t.Helper()
+		if current != live.URL {
+			t.Errorf("current endpoint %s, want rediscovered %s", current, live.URL)
+		}
+		if got, _, ok := cache.Load(apiKey); !ok || got.BaseURL != live.URL {
+			t.Errorf("cache holds %+v, want the rediscovered endpoint", got)
+		}
+	}
+
+	t.Run("sync", func(t *testing.T) {
+		cache := newCache(t)
+		c, err := NewClientWithConfig(ClientConfig{
+			APIKey:         apiKey,
+			Node:           "node",
+			DiscoveryCache: cache,
+			Transport:      discoveryRedirect{disco.URL},
+			HTTPTimeout:    2 * time.Second,
+		})
+		if err != nil {
+			t.Fatalf("NewClientWithConfig: %v", err)
+		}
+		check(t, cache, c.CurrentEndpoint().BaseURL)
+	})
+	t.Run("resilient", func(t *testing.T) {
+		cache := newCache(t)
+		cfg := DefaultResilientClientConfig()
+		cfg.APIKey = apiKey
+		cfg.Node = "node"
+		cfg.DiscoveryCache = cache
+		cfg.Transport = discoveryRedirect{disco.URL}
+		cfg.HTTPTimeout = 2 * time.Second
+		cfg.DiscoveryRefreshInterval = 0
+		c, err := NewResilientClientWithHandshake(cfg)
+		if err != nil {
+			t.Fatalf("NewResilientClientWithHandshake: %v", err)
+		}
+		defer c.Close()
+		check(t, cache, c.CurrentEndpoint().BaseURL)
+	})
+}
+
+func TestResilientClient_FailoverOn503(t *testing.T) {
+	primary := newFailoverServer(t)
+	defer primary.Close()
+	fallback := newFailoverServer(t)
+	defer fallback.Close()
+
+	cfg := DefaultResilientClientConfig()
+	cfg.APIKey = "eu-lf_testkey123"
+	cfg.Node = "node"
+	cfg.CustomEndpointURL = primary.URL
+	cfg.FallbackEndpointURLs = []string{fallback.URL}
+	cfg.FlushInterval = 10 * time.Millisecond
+	cfg.RetryConfig.InitialDelay = 10 * time.Millisecond
+	cfg.RetryConfig.MaxDelay = 20 * time.Millisecond
+	cfg.WorkerCount = 1
+
+	c, err := NewResilientClientWithHandshake(cfg)
+	if err != nil {
+		t.Fatalf("NewResilientClientWithHandshake: %v", err)
+	}
+	defer c.Close()
+
+	primary.ingestStatus.Store(http.StatusServiceUnavailable)
+	if err := c.Info("hello"); err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for c.GetStats().EntriesSent == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if fallback.ingests.Load() == 0 {
+		t.Fatalf("expected entry to reach fallback, stats: %+v", c.GetStats())
+	}
+	if c.CurrentEndpoint().BaseURL != fallback.URL {
+		t.Errorf("expected fallback to be current, got %s", c.CurrentEndpoint().BaseURL)
+	}
+	if c.GetStats().EntriesSent != 1 {
+		t.Errorf("expected 1 sent entry, got %d", c.GetStats().EntriesSent)
+	}
+}
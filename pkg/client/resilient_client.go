@@ -6,10 +6,12 @@ import (
 	"crypto/rand"
 	"encoding/json"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -17,9 +19,11 @@ import (
 
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/config"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/crypto"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/diag"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/discovery"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/handshake"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/queue"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/retry"
 )
@@ -48,6 +52,32 @@ type ClientStats struct {
 	LastSendError  string
 	LastSendTime   time.Time
 	HandshakeOK    bool
+
+	// Entries carrying the SDK's own statistics (see SendSelfTelemetry).
+	// They are not included in the entry counters above and below; the
+	// byte and latency figures cover all requests.
+	SelfTelemetrySent    int64
+	SelfTelemetryDropped int64
+
+	// Per entry type name ("log", "metric", ...).
+	SentByType    map[string]int64
+	DroppedByType map[string]int64
+
+	// Bytes of entries before compression and encryption, and of request
+	// bodies as sent. CompressionRatio is BytesUncompressed / BytesSent,
+	// or 0 before the first send.
+	BytesUncompressed int64
+	BytesSent         int64
+	CompressionRatio  float64
+
+	// Ingest request latency over the last 512 requests.
+	SendLatencyP50 time.Duration
+	SendLatencyP95 time.Duration
+	SendLatencyP99 time.Duration
+
+	RateLimitedUntil time.Time // zero unless sends are paused by a 429
+	QuotaBlocked     []string  // categories blocked by an exceeded quota
+	Endpoint         string    // base URL currently receiving traffic
 }
 
 // ResilientClientConfig holds configuration for the resilient client.
@@ -70,6 +100,31 @@ type ResilientClientConfig struct {
 	EnableCompression bool
 	ResilientMode     bool
 	BeforeSend        BeforeSendFunc
+
+	// Source and Release, when set, make the plain-text log methods send
+	// v2 log payloads carrying them. SampleRate (0.0-1.0, 0 = send all)
+	// is the probability that a non-audit entry is sent.
+	Source     string
+	Release    string
+	SampleRate float64
+
+	// Failover and re-discovery
+	FallbackEndpointURLs     []string      // Extra ingest base URLs tried after the discovered ones
+	DiscoveryRefreshInterval time.Duration // Re-run discovery in the background (0 disables)
+	FailoverCooldown         time.Duration // How long a failed endpoint is skipped (default 30s)
+
+	// DiscoveryCache, when set, serves discovery results from disk
+	// (stale-while-revalidate) to speed up and harden start-up.
+	DiscoveryCache *discovery.FileCache
+
+	// HTTP transport. HTTPClient is used as-is when set; otherwise the
+	// client is built from Transport, or from TransportOptions.
+	HTTPClient       *http.Client
+	Transport        http.RoundTripper
+	TransportOptions TransportOptions
+
+	// Logger receives SDK diagnostic events (nil disables them).
+	Logger diag.Logger
 }
 
 func DefaultResilientClientConfig() ResilientClientConfig {
@@ -83,6 +138,9 @@ func DefaultResilientClientConfig() ResilientClientConfig {
 		WorkerCount:       2,
 		EnableCompression: true,
 		ResilientMode:     false,
+
+		DiscoveryRefreshInterval: 1 * time.Hour,
+		FailoverCooldown:         discovery.DefaultFailoverCooldown,
 	}
 }
 
@@ -105,7 +163,6 @@ func FaultTolerantClientConfig() ResilientClientConfig {
 type ResilientClient struct {
 	config     ResilientClientConfig
 	encryptor  *crypto.Encryptor
-	endpoints  *discovery.EndpointInfo
 	httpClient *http.Client
 	queue      *queue.Queue
 	retryer    *retry.Retryer
@@ -115,6 +172,13 @@ type ResilientClient struct {
 	serverPublicKeyPEM   string
 	serverKeyFingerprint string
 
+	// Endpoint failover. session is the endpoint the current key was
+	// negotiated with (guarded by mu); failoverMu serializes re-handshakes.
+	pool       *discovery.EndpointPool
+	discovery  *discovery.DiscoveryClient
+	session    *discovery.EndpointInfo
+	failoverMu sync.Mutex
+
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
@@ -130,6 +194,13 @@ type ResilientClient struct {
 	lastSendError   string
 	lastSendTime    time.Time
 	handshakeOK     bool
+	sentByType      map[string]int64
+	droppedByType   map[string]int64
+	bytesRaw        int64
+	bytesSent       int64
+	latencies       latencyRing
+	selfSent        int64
+	selfDropped     int64
 
 	// Rate limit state
 	rateLimitMu        sync.RWMutex
@@ -142,6 +213,16 @@ type ResilientClient struct {
 	quotaMu      sync.RWMutex
 	quotaBlocked map[string]bool
 
+	// settingsMu guards the config fields that Reconfigure can change at
+	// runtime (BatchSize, BeforeSend).
+	settingsMu sync.RWMutex
+
+	// context is set when Source or Release is configured; sampler when
+	// SampleRate is below 1.
+	context *payload.GlobalContext
+	sampler *payload.Sampler
+
+	log    diag.Logger
 	closed atomic.Bool
 }
 
@@ -152,30 +233,39 @@ func NewResilientClientWithHandshake(cfg ResilientClientConfig) (*ResilientClien
 	}
 	applyDefaults(&cfg)
 
-	httpClient := &http.Client{Timeout: cfg.HTTPTimeout}
+	httpClient, err := newHTTPClient(cfg.HTTPClient, cfg.Transport, cfg.TransportOptions, cfg.HTTPTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transport configuration: %w", err)
+	}
+	log := diag.OrNop(cfg.Logger)
 
 	var endpoints *discovery.EndpointInfo
+	var staleEndpoints bool
+	dc := discovery.NewDiscoveryClient(discovery.DiscoveryConfig{
+		APIKey: cfg.APIKey, Timeout: 10 * time.Second, HTTPClient: httpClient,
+		Cache: cfg.DiscoveryCache, Logger: log,
+	})
 	if cfg.CustomEndpointURL != "" {
-		dc := discovery.NewDiscoveryClient(discovery.DiscoveryConfig{
-			APIKey: cfg.APIKey, Timeout: 10 * time.Second, HTTPClient: httpClient,
-		})
 		endpoints = dc.SetCustomEndpoint(cfg.CustomEndpointURL)
 	} else {
-		dc := discovery.NewDiscoveryClient(discovery.DiscoveryConfig{
-			APIKey: cfg.APIKey, Timeout: 10 * time.Second, HTTPClient: httpClient,
-		})
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		var err error
-		endpoints, err = dc.DiscoverEndpoints(ctx, "")
+		endpoints, staleEndpoints, err = dc.DiscoverEndpointsCached(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("endpoint discovery failed: %w", err)
 		}
 	}
 
-	handshakeResult, err := handshake.PerformHandshakeWithURL(
-		endpoints.GetHandshakeURL(), cfg.APIKey, httpClient,
-	)
+	pool := discovery.NewEndpointPool(endpoints, cfg.FallbackEndpointURLs)
+	pool.SetCooldown(cfg.FailoverCooldown)
+
+	handshakeResult, session, err := handshakeWithFailover(pool, cfg.APIKey, httpClient, log)
+	if errors.Is(err, handshake.ErrIngestorUnavailable) && cfg.CustomEndpointURL == "" && dc.IsCached(endpoints) {
+		if p, r, s, rerr := rediscoverAndHandshake(dc, cfg.FallbackEndpointURLs, cfg.FailoverCooldown, cfg.APIKey, httpClient, log); p != nil {
+			pool, handshakeResult, session, err = p, r, s, rerr
+			staleEndpoints = false
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("handshake failed: %w", err)
 	}
@@ -199,18 +289,46 @@ func NewResilientClientWithHandshake(cfg ResilientClientConfig) (*ResilientClien
 		serverKeyFingerprint: handshakeResult.ServerKeyFingerprint,
 		ctx:                  ctx,
 		cancel:               cancel,
-		endpoints:            endpoints,
+		pool:                 pool,
+		discovery:            dc,
+		session:              session,
 		dropReasons:          make(map[DropReason]int64),
+		sentByType:           make(map[string]int64),
+		droppedByType:        make(map[string]int64),
 		quotaBlocked:         make(map[string]bool),
 		handshakeOK:          true,
+		log:                  log,
+	}
+	if cfg.Source != "" || cfg.Release != "" {
+		c.context = payload.NewContext()
+		c.context.Configure(cfg.Source, cfg.Environment, cfg.Release)
+	}
+	if cfg.SampleRate > 0 && cfg.SampleRate < 1 {
+		c.sampler = payload.NewSampler(cfg.SampleRate)
 	}
 
-	if cfg.ResilientMode && endpoints != nil {
-		c.retryer.SetHealthCheckURL(endpoints.GetHealthURL())
+	c.retryer.SetHTTPClient(httpClient)
+	c.retryer.SetLogger(log)
+	if cfg.ResilientMode {
+		c.retryer.SetHealthCheckURL(session.GetHealthURL())
 		c.retryer.EnableResilientMode(true)
 	}
 
 	c.startWorkers()
+	if staleEndpoints {
+		// Served from a stale cache entry: revalidate without blocking start-up.
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.refreshEndpoints()
+		}()
+	}
+	if cfg.CustomEndpointURL == "" && cfg.DiscoveryRefreshInterval > 0 {
+		c.wg.Add(1)
+		go c.discoveryRefresher()
+	}
+	log.Info("client started", "endpoint", session.BaseURL, "api_key", maskAPIKey(cfg.APIKey),
+		"workers", cfg.WorkerCount, "queue_size", cfg.QueueSize, "batch_size", cfg.BatchSize)
 	return c, nil
 }
 
@@ -220,16 +338,45 @@ func NewResilientClientFromEnvWithHandshake(node string) (*ResilientClient, erro
 	if err != nil {
 		return nil, err
 	}
+	cfg := ResilientClientConfigFromConfig(envCfg)
+	if node != "" {
+		cfg.Node = node
+	}
+	return NewResilientClientWithHandshake(cfg)
+}
+
+// ResilientClientConfigFromConfig maps file/env settings onto the default
+// client configuration. Unset (zero) values keep their defaults.
+func ResilientClientConfigFromConfig(envCfg *config.Config) ResilientClientConfig {
+	seconds := func(n int) time.Duration { return time.Duration(n) * time.Second }
 	cfg := DefaultResilientClientConfig()
-	cfg.Node = node
 	cfg.APIKey = envCfg.APIKey
+	cfg.Node = envCfg.Node
 	cfg.Environment = envCfg.Environment
 	cfg.LogGroup = envCfg.LogGroup
+	cfg.Source = envCfg.Source
+	cfg.Release = envCfg.Release
+	cfg.SampleRate = envCfg.SampleRate
+	cfg.CustomEndpointURL = envCfg.CustomEndpointURL
+	cfg.FallbackEndpointURLs = envCfg.FallbackEndpointURLs
+	cfg.TransportOptions = TransportOptionsFromConfig(envCfg)
+	if envCfg.DiscoveryRefreshInterval > 0 {
+		cfg.DiscoveryRefreshInterval = seconds(envCfg.DiscoveryRefreshInterval)
+	}
+	if envCfg.FailoverCooldown > 0 {
+		cfg.FailoverCooldown = seconds(envCfg.FailoverCooldown)
+	}
+	if envCfg.DiscoveryCache || envCfg.DiscoveryCacheDir != "" {
+		// A missing cache directory only costs start-up latency, so it is not fatal.
+		if dc, err := discovery.NewFileCache(envCfg.DiscoveryCacheDir, seconds(envCfg.DiscoveryCacheTTL), 0); err == nil {
+			cfg.DiscoveryCache = dc
+		}
+	}
 	if envCfg.QueueSize > 0 {
 		cfg.QueueSize = envCfg.QueueSize
 	}
 	if envCfg.FlushInterval > 0 {
-		cfg.FlushInterval = time.Duration(envCfg.FlushInterval) * time.Second
+		cfg.FlushInterval = seconds(envCfg.FlushInterval)
 	}
 	if envCfg.BatchSize > 0 {
 		cfg.BatchSize = envCfg.BatchSize
@@ -241,20 +388,23 @@ func NewResilientClientFromEnvWithHandshake(node string) (*ResilientClient, erro
 		cfg.RetryConfig.InitialDelay = time.Duration(envCfg.InitialDelay) * time.Millisecond
 	}
 	if envCfg.MaxDelay > 0 {
-		cfg.RetryConfig.MaxDelay = time.Duration(envCfg.MaxDelay) * time.Second
+		cfg.RetryConfig.MaxDelay = seconds(envCfg.MaxDelay)
 	}
 	if envCfg.BackoffFactor > 0 {
 		cfg.RetryConfig.BackoffFactor = envCfg.BackoffFactor
 	}
 	if envCfg.HTTPTimeout > 0 {
-		cfg.HTTPTimeout = time.Duration(envCfg.HTTPTimeout) * time.Second
+		cfg.HTTPTimeout = seconds(envCfg.HTTPTimeout)
 	}
 	if envCfg.WorkerCount > 0 {
 		cfg.WorkerCount = envCfg.WorkerCount
 	}
 	cfg.FailsafeMode = envCfg.FailsafeMode
 	cfg.EnableCompression = envCfg.EnableCompression
-	return NewResilientClientWithHandshake(cfg)
+	if envCfg.Debug {
+		cfg.Logger = diag.NewStderr()
+	}
+	return cfg
 }
 
 func applyDefaults(cfg *ResilientClientConfig) {
@@ -301,11 +451,32 @@ func (c *ResilientClient) SendLogWithTimestampAndLevel(message string, timestamp
 }
 
 func (c *ResilientClient) SendLogWithTimestampLevelAndLabels(message string, timestamp time.Time, level int, labels map[string]string) error {
-	return c.enqueueEntry(message, timestamp, level, models.EntryTypeLog, 0, labels, nil)
+	if c.context != nil {
+		// Source and release only travel inside a v2 payload.
+		p := payload.NewLog("", message, level)
+		p.Ts = timestamp.UTC().Format(time.RFC3339Nano)
+		if len(labels) > 0 {
+			p.SetAttributes(labels)
+		}
+		c.context.Apply(p)
+		data, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("failed to marshal log: %w", err)
+		}
+		message = string(data)
+	}
+	return c.enqueueEntry(message, timestamp, level, models.EntryTypeLog, 0, labels, nil, false)
 }
 
 func (c *ResilientClient) SendLogWithEntryType(message string, level, entryType int) error {
-	return c.enqueueEntry(message, time.Now(), level, entryType, 0, nil, nil)
+	return c.enqueueEntry(message, time.Now(), level, entryType, 0, nil, nil, false)
+}
+
+// SendSelfTelemetry is SendLogWithEntryType for the SDK's own statistics.
+// The entry is sent like any other, but GetStats counts it apart so the
+// statistics do not count their own reports.
+func (c *ResilientClient) SendSelfTelemetry(message string, level, entryType int) error {
+	return c.enqueueEntry(message, time.Now(), level, entryType, 0, nil, nil, true)
 }
 
 func (c *ResilientClient) SendLogWithLabels(message string, labels map[string]string) error {
@@ -316,7 +487,7 @@ func (c *ResilientClient) SendLogWithLevelAndLabels(message string, level int, l
 	return c.SendLogWithTimestampLevelAndLabels(message, time.Now(), level, labels)
 }
 
-func (c *ResilientClient) enqueueEntry(message string, timestamp time.Time, level, entryType, payloadType int, labels map[string]string, searchTokens []string) error {
+func (c *ResilientClient) enqueueEntry(message string, timestamp time.Time, level, entryType, payloadType int, labels map[string]string, searchTokens []string, selfTelemetry bool) error {
 	if c.closed.Load() {
 		if c.config.FailsafeMode {
 			return nil
@@ -327,6 +498,10 @@ func (c *ResilientClient) enqueueEntry(message string, timestamp time.Time, leve
 	if entryType == 0 {
 		entryType = models.EntryTypeLog
 	}
+	// Audit entries are never sampled out.
+	if c.sampler != nil && entryType != models.EntryTypeAudit && !c.sampler.ShouldSample() {
+		return nil
+	}
 
 	entry := models.LogEntry{
 		Message:      message,
@@ -340,17 +515,17 @@ func (c *ResilientClient) enqueueEntry(message string, timestamp time.Time, leve
 	}
 
 	if err := validateEntry(&entry); err != nil {
-		c.recordDrop(DropValidation, 1)
+		c.recordDrop(DropValidation, oneEntry(entry.EntryType, selfTelemetry))
 		if c.config.FailsafeMode {
 			return nil
 		}
 		return err
 	}
 
-	if c.config.BeforeSend != nil {
-		result := c.config.BeforeSend(&entry)
+	if beforeSend := c.beforeSend(); beforeSend != nil {
+		result := beforeSend(&entry)
 		if result == nil {
-			c.recordDrop(DropBeforeSend, 1)
+			c.recordDrop(DropBeforeSend, oneEntry(entry.EntryType, selfTelemetry))
 			return nil
 		}
 		entry = *result
@@ -362,7 +537,7 @@ func (c *ResilientClient) enqueueEntry(message string, timestamp time.Time, leve
 	blocked := c.quotaBlocked[category]
 	c.quotaMu.RUnlock()
 	if blocked {
-		c.recordDrop(DropQuotaExceeded, 1)
+		c.recordDrop(DropQuotaExceeded, oneEntry(entry.EntryType, selfTelemetry))
 		if c.config.FailsafeMode {
 			return nil
 		}
@@ -381,14 +556,19 @@ func (c *ResilientClient) enqueueEntry(message string, timestamp time.Time, leve
 		SearchTokens: entry.SearchTokens,
 		CreatedAt:    time.Now(),
 	}
+	if selfTelemetry {
+		qEntry = qEntry.WithSelfTelemetry()
+	}
 
 	if c.queue.Enqueue(qEntry) {
-		c.totalQueued.Add(1)
+		if !selfTelemetry {
+			c.totalQueued.Add(1)
+		}
 		return nil
 	}
 
 	// Queue full
-	c.recordDrop(DropQueueOverflow, 1)
+	c.recordDrop(DropQueueOverflow, oneEntry(entry.EntryType, selfTelemetry))
 	if c.config.FailsafeMode {
 		return nil
 	}
@@ -429,8 +609,8 @@ func (c *ResilientClient) SendLogBatch(messages []LogMessage) error {
 		if err := validateEntry(&entry); err != nil {
 			return err
 		}
-		if c.config.BeforeSend != nil {
-			result := c.config.BeforeSend(&entry)
+		if beforeSend := c.beforeSend(); beforeSend != nil {
+			result := beforeSend(&entry)
 			if result == nil {
 				continue
 			}
@@ -442,22 +622,18 @@ func (c *ResilientClient) SendLogBatch(messages []LogMessage) error {
 		return nil
 	}
 
+	counts := countEntries(entries)
 	err := c.retryer.Retry(c.ctx, func() error {
-		return c.sendMultipart(entries)
+		return c.sendMultipart(entries, counts)
 	})
 	if err != nil {
-		c.recordDrop(DropSendError, int64(len(entries)))
+		c.recordDrop(DropSendError, counts)
 		c.recordError(err)
 		if c.config.FailsafeMode {
 			return nil
 		}
 		return err
 	}
-
-	c.totalSent.Add(int64(len(entries)))
-	c.mu.Lock()
-	c.lastSendTime = time.Now()
-	c.mu.Unlock()
 	return nil
 }
 
@@ -474,7 +650,9 @@ func (c *ResilientClient) worker() {
 	defer c.wg.Done()
 	for {
 		// Block until at least one entry is available
-		entry := c.queue.DequeueWithContext(c.ctx)
+		// Take counts the batch as in flight under the queue lock, so Flush
+		// cannot observe an empty queue while this entry is still unsent.
+		entry := c.queue.Take(c.ctx)
 		if entry == nil {
 			return
 		}
@@ -484,10 +662,12 @@ func (c *ResilientClient) worker() {
 		pauseUntil := c.rateLimitPauseUntil
 		c.rateLimitMu.RUnlock()
 		if time.Now().Before(pauseUntil) {
+			c.log.Debug("worker paused for rate limit", "until", pauseUntil)
 			// Re-enqueue if possible, otherwise drop
 			if !c.queue.Enqueue(*entry) {
-				c.recordDrop(DropRateLimited, 1)
+				c.recordDrop(DropRateLimited, oneEntry(entry.EntryType, entry.SelfTelemetry()))
 			}
+			c.queue.Done()
 			select {
 			case <-c.ctx.Done():
 				return
@@ -507,10 +687,11 @@ func (c *ResilientClient) worker() {
 			Labels:       entry.Labels,
 			SearchTokens: entry.SearchTokens,
 		}}
+		counts := oneEntry(entry.EntryType, entry.SelfTelemetry())
 
 		// Drain more entries up to batch size
-		if c.config.BatchSize > 1 {
-			extra := c.queue.DequeueBatch(c.config.BatchSize - 1)
+		if batchSize := c.batchSize(); batchSize > 1 {
+			extra := c.queue.DequeueBatch(batchSize - 1)
 			for _, e := range extra {
 				entries = append(entries, models.LogEntry{
 					Message:      e.Message,
@@ -522,33 +703,37 @@ func (c *ResilientClient) worker() {
 					Labels:       e.Labels,
 					SearchTokens: e.SearchTokens,
 				})
+				counts = counts.add(e.EntryType, e.SelfTelemetry())
 			}
 		}
 
-		count := int64(len(entries))
 		err := c.retryer.Retry(c.ctx, func() error {
-			return c.sendMultipart(entries)
+			return c.sendMultipart(entries, counts)
 		})
 
 		if err != nil {
-			c.handleSendError(err, count)
+			c.handleSendError(err, counts)
 		} else {
-			c.totalSent.Add(count)
-			c.mu.Lock()
-			c.lastSendTime = time.Now()
-			c.mu.Unlock()
+			c.log.Debug("batch sent", "entries", len(entries))
 		}
+		c.queue.Done()
 	}
 }
 
-// sendMultipart builds and sends a multipart/mixed request.
-func (c *ResilientClient) sendMultipart(entries []models.LogEntry) error {
-	body, contentType, err := c.buildMultipartBody(entries)
+// sendMultipart builds and sends a multipart/mixed request, and records
+// counts (the entries' tally) as sent if the ingestor accepts it.
+func (c *ResilientClient) sendMultipart(entries []models.LogEntry, counts entryCounts) error {
+	if err := c.ensureSession(); err != nil {
+		return err
+	}
+
+	body, contentType, ep, err := c.buildMultipartBody(entries)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequestWithContext(c.ctx, "POST", c.endpoints.GetIngestURL(), body)
+	wire := body.Len()
+	req, err := http.NewRequestWithContext(c.ctx, "POST", ep.GetIngestURL(), body)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -556,14 +741,23 @@ func (c *ResilientClient) sendMultipart(entries []models.LogEntry) error {
 	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
 	req.Header.Set("User-Agent", userAgent)
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		if c.ctx.Err() == nil {
+			markFailed(c.pool, ep.BaseURL, err, c.log)
+		}
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
+	c.recordLatency(time.Since(start))
 
 	c.updateRateLimitInfo(resp)
 
+	if isEndpointUnavailable(resp.StatusCode) {
+		markFailed(c.pool, ep.BaseURL, fmt.Errorf("ingest returned status %d", resp.StatusCode), c.log)
+	}
+
 	if resp.StatusCode == http.StatusTooManyRequests {
 		retryAfter := 60 // default
 		if ra := resp.Header.Get("Retry-After"); ra != "" {
@@ -574,6 +768,7 @@ func (c *ResilientClient) sendMultipart(entries []models.LogEntry) error {
 		c.rateLimitMu.Lock()
 		c.rateLimitPauseUntil = time.Now().Add(time.Duration(retryAfter) * time.Second)
 		c.rateLimitMu.Unlock()
+		c.log.Warn("rate limited, pausing sends", "retry_after", time.Duration(retryAfter)*time.Second)
 
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseSize))
 		return retry.NewHTTPErrorFromResponse(resp, string(body))
@@ -586,6 +781,7 @@ func (c *ResilientClient) sendMultipart(entries []models.LogEntry) error {
 			c.quotaMu.Lock()
 			c.quotaBlocked[category] = true
 			c.quotaMu.Unlock()
+			c.log.Warn("quota exceeded, blocking category", "category", category)
 		}
 		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseSize))
 		return retry.NewHTTPErrorFromResponse(resp, string(respBody))
@@ -596,15 +792,23 @@ func (c *ResilientClient) sendMultipart(entries []models.LogEntry) error {
 		return retry.NewHTTPErrorFromResponse(resp, string(respBody))
 	}
 
+	raw := 0
+	for _, e := range entries {
+		raw += len(e.Message)
+	}
+	c.recordSent(counts, raw, wire)
 	return nil
 }
 
-// buildMultipartBody is shared with the sync Client via delegation.
-func (c *ResilientClient) buildMultipartBody(entries []models.LogEntry) (*bytes.Buffer, string, error) {
-	// Read encryptor and keyUUID under lock to avoid races with RenewSession
+// buildMultipartBody is shared with the sync Client via delegation. It also
+// returns the endpoint the encryption key belongs to, which the body must be
+// sent to.
+func (c *ResilientClient) buildMultipartBody(entries []models.LogEntry) (*bytes.Buffer, string, *discovery.EndpointInfo, error) {
+	// Read encryptor, keyUUID and session under lock to avoid races with RenewSession
 	c.mu.RLock()
 	enc := c.encryptor
 	keyID := c.keyUUID
+	session := c.session
 	c.mu.RUnlock()
 
 	builder := &multipartBuilder{
@@ -612,28 +816,39 @@ func (c *ResilientClient) buildMultipartBody(entries []models.LogEntry) (*bytes.
 		keyUUID:           keyID,
 		enableCompression: c.config.EnableCompression,
 	}
-	return builder.build(entries)
+	body, contentType, err := builder.build(entries)
+	return body, contentType, session, err
 }
 
-func (c *ResilientClient) handleSendError(err error, count int64) {
+func (c *ResilientClient) handleSendError(err error, types entryCounts) {
 	if httpErr, ok := err.(*retry.HTTPError); ok {
 		if httpErr.IsQuotaExceeded() {
-			c.recordDrop(DropQuotaExceeded, count)
+			c.recordDrop(DropQuotaExceeded, types)
 		} else if httpErr.IsRateLimited() {
-			c.recordDrop(DropRateLimited, count)
+			c.recordDrop(DropRateLimited, types)
 		} else {
-			c.recordDrop(DropSendError, count)
+			c.recordDrop(DropSendError, types)
 		}
 	} else {
-		c.recordDrop(DropNetworkError, count)
+		c.recordDrop(DropNetworkError, types)
 	}
 	c.recordError(err)
 }
 
-func (c *ResilientClient) recordDrop(reason DropReason, count int64) {
+func (c *ResilientClient) recordDrop(reason DropReason, types entryCounts) {
+	count := types.total()
+	if reason == DropBeforeSend {
+		c.log.Debug("entries dropped", "reason", reason, "count", count+types.self)
+	} else {
+		c.log.Warn("entries dropped", "reason", reason, "count", count+types.self)
+	}
 	c.mu.Lock()
-	c.totalDropped += count
-	c.dropReasons[reason] += count
+	if count > 0 {
+		c.totalDropped += count
+		c.dropReasons[reason] += count
+		types.addByName(c.droppedByType)
+	}
+	c.selfDropped += types.self
 	c.mu.Unlock()
 }
 
@@ -690,6 +905,7 @@ func (c *ResilientClient) GetStats() ClientStats {
 	for k, v := range c.dropReasons {
 		reasons[k] = v
 	}
+	p50, p95, p99 := c.latencies.percentiles()
 	stats := ClientStats{
 		EntriesSent:    c.totalSent.Load(),
 		EntriesDropped: c.totalDropped,
@@ -700,24 +916,65 @@ func (c *ResilientClient) GetStats() ClientStats {
 		LastSendError:  c.lastSendError,
 		LastSendTime:   c.lastSendTime,
 		HandshakeOK:    c.handshakeOK,
+
+		SelfTelemetrySent:    c.selfSent,
+		SelfTelemetryDropped: c.selfDropped,
+
+		SentByType:        copyCounts(c.sentByType),
+		DroppedByType:     copyCounts(c.droppedByType),
+		BytesUncompressed: c.bytesRaw,
+		BytesSent:         c.bytesSent,
+		SendLatencyP50:    p50,
+		SendLatencyP95:    p95,
+		SendLatencyP99:    p99,
 	}
 	c.mu.RUnlock()
+	if stats.BytesSent > 0 {
+		stats.CompressionRatio = float64(stats.BytesUncompressed) / float64(stats.BytesSent)
+	}
+
+	c.rateLimitMu.RLock()
+	if pause := c.rateLimitPauseUntil; time.Now().Before(pause) {
+		stats.RateLimitedUntil = pause
+	}
+	c.rateLimitMu.RUnlock()
+
+	c.quotaMu.RLock()
+	for category, blocked := range c.quotaBlocked {
+		if blocked {
+			stats.QuotaBlocked = append(stats.QuotaBlocked, category)
+		}
+	}
+	c.quotaMu.RUnlock()
+	sort.Strings(stats.QuotaBlocked)
+
+	stats.Endpoint = c.CurrentEndpoint().BaseURL
 	return stats
 }
 
+func copyCounts(m map[string]int64) map[string]int64 {
+	out := make(map[string]int64, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
 func (c *ResilientClient) GetRateLimitInfo() (limit, remaining int, resetTime time.Time) {
 	c.rateLimitMu.RLock()
 	defer c.rateLimitMu.RUnlock()
 	return c.rateLimitLimit, c.rateLimitRemaining, time.Unix(c.rateLimitReset, 0)
 }
 
+// Flush waits up to timeout until the queue is empty and the batches the
+// workers have taken from it are sent or have failed.
 func (c *ResilientClient) Flush(timeout time.Duration) error {
 	timer := time.NewTimer(timeout)
 	defer timer.Stop()
 	ticker := time.NewTicker(10 * time.Millisecond)
 	defer ticker.Stop()
 	for {
-		if c.queue.IsEmpty() {
+		if c.queue.Idle() {
 			return nil
 		}
 		select {
@@ -750,9 +1007,45 @@ func (c *ResilientClient) Close() error {
 		enc.Close()
 	}
 
+	c.mu.RLock()
+	dropped := c.totalDropped
+	c.mu.RUnlock()
+	c.log.Info("client closed", "sent", c.totalSent.Load(), "dropped", dropped)
 	return nil
 }
 
+// ClientSettings are the client options that can be changed without a new
+// handshake.
+type ClientSettings struct {
+	BatchSize  int            // Maximum entries per request (<= 0: default 100)
+	BeforeSend BeforeSendFunc // Transport-level hook; nil removes it
+}
+
+// Reconfigure applies s to a running client. Workers pick up the new batch
+// size with their next batch; queued entries are kept.
+func (c *ResilientClient) Reconfigure(s ClientSettings) {
+	if s.BatchSize <= 0 {
+		s.BatchSize = 100
+	}
+	c.settingsMu.Lock()
+	c.config.BatchSize = s.BatchSize
+	c.config.BeforeSend = s.BeforeSend
+	c.settingsMu.Unlock()
+	c.log.Info("client reconfigured", "batch_size", s.BatchSize, "before_send", s.BeforeSend != nil)
+}
+
+func (c *ResilientClient) batchSize() int {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.config.BatchSize
+}
+
+func (c *ResilientClient) beforeSend() BeforeSendFunc {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.config.BeforeSend
+}
+
 func (c *ResilientClient) GetNodeName() string               { return c.config.Node }
 func (c *ResilientClient) GetAPIKeyMasked() string            { return maskAPIKey(c.config.APIKey) }
 func (c *ResilientClient) GetServerPublicKeyFingerprint() string {
@@ -772,18 +1065,29 @@ func (c *ResilientClient) IsCompressionEnabled() bool        { return c.config.E
 func (c *ResilientClient) EnableResilientMode(enabled bool) {
 	c.config.ResilientMode = enabled
 	c.retryer.EnableResilientMode(enabled)
-	if enabled && c.endpoints != nil {
-		c.retryer.SetHealthCheckURL(c.endpoints.GetHealthURL())
+	if enabled {
+		c.retryer.SetHealthCheckURL(c.CurrentEndpoint().GetHealthURL())
 	}
 }
 
 func (c *ResilientClient) IsResilientModeEnabled() bool { return c.config.ResilientMode }
 
+// RenewSession performs a fresh handshake with the current endpoint and
+// swaps in the new key.
 func (c *ResilientClient) RenewSession() error {
-	handshakeResult, err := handshake.PerformHandshakeWithURL(
-		c.endpoints.GetHandshakeURL(), c.config.APIKey, c.httpClient,
-	)
+	c.failoverMu.Lock()
+	defer c.failoverMu.Unlock()
+	return c.renewSessionWith(c.pool.Current())
+}
+
+// renewSessionWith handshakes with ep and makes it the session endpoint.
+// Must be called with c.failoverMu held.
+func (c *ResilientClient) renewSessionWith(ep *discovery.EndpointInfo) error {
+	handshakeResult, err := performHandshake(ep, c.config.APIKey, c.httpClient, c.log)
 	if err != nil {
+		c.mu.Lock()
+		c.handshakeOK = false
+		c.mu.Unlock()
 		return fmt.Errorf("session renewal failed: %w", err)
 	}
 	newEncryptor := crypto.NewEncryptor(handshakeResult.AESKey)
@@ -798,16 +1102,84 @@ func (c *ResilientClient) RenewSession() error {
 	c.serverPublicKeyPEM = handshakeResult.ServerPublicKeyPEM
 	c.serverKeyFingerprint = handshakeResult.ServerKeyFingerprint
 	c.limits = handshakeResult.Limits
+	c.session = ep
+	c.handshakeOK = true
 	c.mu.Unlock()
 	// Zero old key material
 	if oldEncryptor != nil {
 		oldEncryptor.Close()
 	}
+	if c.config.ResilientMode {
+		c.retryer.SetHealthCheckURL(ep.GetHealthURL())
+	}
+	return nil
+}
+
+// ensureSession re-handshakes when the endpoint pool has moved away from the
+// endpoint the current key was negotiated with (failover or fail-back).
+func (c *ResilientClient) ensureSession() error {
+	ep := c.pool.Current()
+	c.mu.RLock()
+	session := c.session
+	c.mu.RUnlock()
+	if session != nil && session.BaseURL == ep.BaseURL {
+		return nil
+	}
+
+	c.failoverMu.Lock()
+	defer c.failoverMu.Unlock()
+	// Another worker may have switched while we waited for the lock.
+	ep = c.pool.Current()
+	c.mu.RLock()
+	session = c.session
+	c.mu.RUnlock()
+	if session != nil && session.BaseURL == ep.BaseURL {
+		return nil
+	}
+	if err := c.renewSessionWith(ep); err != nil {
+		markFailed(c.pool, ep.BaseURL, err, c.log)
+		return fmt.Errorf("failover to %s: %w", ep.BaseURL, err)
+	}
 	return nil
 }
 
+// discoveryRefresher periodically re-runs endpoint discovery so that
+// long-running processes pick up endpoint changes.
+func (c *ResilientClient) discoveryRefresher() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(c.config.DiscoveryRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.refreshEndpoints()
+		}
+	}
+}
+
+// refreshEndpoints re-runs discovery once and applies the result to the pool.
+func (c *ResilientClient) refreshEndpoints() {
+	ctx, cancel := context.WithTimeout(c.ctx, 10*time.Second)
+	defer cancel()
+	if err := c.discovery.RefreshPool(ctx, c.pool); err != nil {
+		c.log.Debug("endpoint refresh failed, keeping current endpoints", "error", err)
+	}
+}
+
+// CurrentEndpoint returns the endpoint that currently receives traffic.
+func (c *ResilientClient) CurrentEndpoint() *discovery.EndpointInfo {
+	return c.pool.Current()
+}
+
+// Endpoints returns all known ingest endpoints in priority order.
+func (c *ResilientClient) Endpoints() []*discovery.EndpointInfo {
+	return c.pool.All()
+}
+
 func (c *ResilientClient) HealthCheck() error {
-	req, err := http.NewRequest("GET", c.endpoints.GetHealthURL(), nil)
+	req, err := http.NewRequest("GET", c.CurrentEndpoint().GetHealthURL(), nil)
 	if err != nil {
 		return err
 	}
@@ -824,7 +1196,7 @@ func (c *ResilientClient) HealthCheck() error {
 }
 
 func (c *ResilientClient) GetVersion() (map[string]interface{}, error) {
-	req, err := http.NewRequest("GET", c.endpoints.GetVersionURL(), nil)
+	req, err := http.NewRequest("GET", c.CurrentEndpoint().GetVersionURL(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"sort"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+)
+
+// latencyWindow is how many recent request latencies the send-latency
+// percentiles are computed over.
+const latencyWindow = 512
+
+// latencyRing keeps the most recent request latencies.
+type latencyRing struct {
+	samples []time.Duration
+	next    int
+}
+
+func (r *latencyRing) add(d time.Duration) {
+	if len(r.samples) < latencyWindow {
+		r.samples = append(r.samples, d)
+		return
+	}
+	r.samples[r.next] = d
+	r.next = (r.next + 1) % latencyWindow
+}
+
+// percentiles returns the 50th, 95th and 99th percentile, or zeros if no
+// request has completed yet.
+func (r *latencyRing) percentiles() (p50, p95, p99 time.Duration) {
+	if len(r.samples) == 0 {
+		return 0, 0, 0
+	}
+	sorted := append([]time.Duration(nil), r.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	at := func(q float64) time.Duration { return sorted[int(q*float64(len(sorted)-1))] }
+	return at(0.50), at(0.95), at(0.99)
+}
+
+// entryCounts counts application entries per entry type, and self-telemetry
+// entries apart so the SDK's statistics do not count their own reports.
+type entryCounts struct {
+	byType map[int]int64
+	self   int64
+}
+
+func (t entryCounts) add(entryType int, selfTelemetry bool) entryCounts {
+	if selfTelemetry {
+		t.self++
+		return t
+	}
+	if t.byType == nil {
+		t.byType = make(map[int]int64)
+	}
+	t.byType[entryType]++
+	return t
+}
+
+func oneEntry(entryType int, selfTelemetry bool) entryCounts {
+	return entryCounts{}.add(entryType, selfTelemetry)
+}
+
+// countEntries counts application entries.
+func countEntries(entries []models.LogEntry) entryCounts {
+	var counts entryCounts
+	for _, e := range entries {
+		counts = counts.add(e.EntryType, false)
+	}
+	return counts
+}
+
+// total is the number of application entries.
+func (t entryCounts) total() int64 {
+	var n int64
+	for _, c := range t.byType {
+		n += c
+	}
+	return n
+}
+
+// addByName adds the application entries to m, keyed by entry type name.
+func (t entryCounts) addByName(m map[string]int64) {
+	for entryType, c := range t.byType {
+		m[models.EntryTypeName(entryType)] += c
+	}
+}
+
+// recordSent counts a batch that was accepted by the ingestor. raw is the
+// size of the entries before compression and encryption, wire the size of
+// the request body.
+func (c *ResilientClient) recordSent(counts entryCounts, raw, wire int) {
+	c.totalSent.Add(counts.total())
+	c.mu.Lock()
+	c.lastSendTime = time.Now()
+	counts.addByName(c.sentByType)
+	c.selfSent += counts.self
+	c.bytesRaw += int64(raw)
+	c.bytesSent += int64(wire)
+	c.mu.Unlock()
+}
+
+func (c *ResilientClient) recordLatency(d time.Duration) {
+	c.mu.Lock()
+	c.latencies.add(d)
+	c.mu.Unlock()
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+)
+
+func TestResilientClient_Stats(t *testing.T) {
+	srv := newFailoverServer(t)
+	defer srv.Close()
+
+	cfg := DefaultResilientClientConfig()
+	cfg.APIKey = "eu-lf_testkey123"
+	cfg.Node = "node"
+	cfg.CustomEndpointURL = srv.URL
+	cfg.WorkerCount = 1
+	cfg.RetryConfig.MaxRetries = 0
+
+	c, err := NewResilientClientWithHandshake(cfg)
+	if err != nil {
+		t.Fatalf("NewResilientClientWithHandshake: %v", err)
+	}
+	defer c.Close()
+
+	_ = c.SendLogWithEntryType(`{"message":"hello hello hello hello hello"}`, models.LogLevelInfo, models.EntryTypeLog)
+	_ = c.SendLogWithEntryType(`{"name":"requests","value":1}`, models.LogLevelInfo, models.EntryTypeMetric)
+	if err := c.Flush(3 * time.Second); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	stats := c.GetStats()
+	if stats.SentByType["log"] != 1 || stats.SentByType["metric"] != 1 {
+		t.Errorf("SentByType = %v", stats.SentByType)
+	}
+	if stats.BytesSent == 0 || stats.BytesUncompressed == 0 || stats.CompressionRatio == 0 {
+		t.Errorf("bytes = %d/%d, ratio %v", stats.BytesUncompressed, stats.BytesSent, stats.CompressionRatio)
+	}
+	if stats.SendLatencyP50 <= 0 || stats.SendLatencyP99 < stats.SendLatencyP50 {
+		t.Errorf("latency p50 = %v, p99 = %v", stats.SendLatencyP50, stats.SendLatencyP99)
+	}
+	if stats.Endpoint != srv.URL || !stats.HandshakeOK {
+		t.Errorf("endpoint = %q, handshake ok = %v", stats.Endpoint, stats.HandshakeOK)
+	}
+
+	srv.ingestStatus.Store(http.StatusInsufficientStorage)
+	_ = c.SendLogWithEntryType(`{"name":"requests","value":1}`, models.LogLevelInfo, models.EntryTypeMetric)
+	_ = c.Flush(3 * time.Second)
+	stats = c.GetStats()
+	if len(stats.QuotaBlocked) != 1 || stats.QuotaBlocked[0] != models.CategoryEvents {
+		t.Errorf("QuotaBlocked = %v", stats.QuotaBlocked)
+	}
+	if stats.DroppedByType["metric"] != 1 {
+		t.Errorf("DroppedByType = %v", stats.DroppedByType)
+	}
+}
+
+func TestResilientClient_StatsExcludeSelfTelemetry(t *testing.T) {
+	srv := newFailoverServer(t)
+	defer srv.Close()
+
+	cfg := DefaultResilientClientConfig()
+	cfg.APIKey = "eu-lf_testkey123"
+	cfg.Node = "node"
+	cfg.CustomEndpointURL = srv.URL
+	cfg.WorkerCount = 1
+	cfg.RetryConfig.MaxRetries = 0
+
+	c, err := NewResilientClientWithHandshake(cfg)
+	if err != nil {
+		t.Fatalf("NewResilientClientWithHandshake: %v", err)
+	}
+	defer c.Close()
+
+	_ = c.SendLogWithEntryType(`{"message":"hello"}`, models.LogLevelInfo, models.EntryTypeLog)
+	_ = c.SendSelfTelemetry(`{"name":"logflux.sdk.entries.sent","value":1}`, models.LogLevelInfo, models.EntryTypeMetric)
+	if err := c.Flush(3 * time.Second); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	stats := c.GetStats()
+	if stats.EntriesSent != 1 || stats.EntriesQueued != 1 || stats.SentByType["metric"] != 0 || stats.SelfTelemetrySent != 1 {
+		t.Errorf("sent = %d, queued = %d, by type %v, self telemetry %d",
+			stats.EntriesSent, stats.EntriesQueued, stats.SentByType, stats.SelfTelemetrySent)
+	}
+
+	srv.ingestStatus.Store(http.StatusInternalServerError)
+	_ = c.SendSelfTelemetry(`{"name":"logflux.sdk.entries.sent","value":1}`, models.LogLevelInfo, models.EntryTypeMetric)
+	_ = c.Flush(3 * time.Second)
+	stats = c.GetStats()
+	if stats.EntriesDropped != 0 || len(stats.DropReasons) != 0 || len(stats.DroppedByType) != 0 || stats.SelfTelemetryDropped != 1 {
+		t.Errorf("dropped = %d, reasons %v, by type %v, self telemetry %d",
+			stats.EntriesDropped, stats.DropReasons, stats.DroppedByType, stats.SelfTelemetryDropped)
+	}
+}
+
+func TestLatencyRing(t *testing.T) {
+	var r latencyRing
+	if p50, _, _ := r.percentiles(); p50 != 0 {
+		t.Errorf("empty p50 = %v", p50)
+	}
+	for i := 1; i <= latencyWindow+100; i++ {
+		r.add(time.Duration(i) * time.Millisecond)
+	}
+	if len(r.samples) != latencyWindow {
+		t.Fatalf("kept %d samples", len(r.samples))
+	}
+	p50, _, p99 := r.percentiles()
+	if p50 != 356*time.Millisecond || p99 != 606*time.Millisecond {
+		t.Errorf("p50 = %v, p99 = %v", p50, p99)
+	}
+}
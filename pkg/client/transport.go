@@ -0,0 +1,171 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/config"
+)
+
+// TransportOptions configures the HTTP transport used for discovery,
+// handshake and ingest requests. The zero value keeps Go's default transport.
+type TransportOptions struct {
+	// ProxyURL routes all SDK traffic through an HTTP(S) proxy, e.g.
+	// "http://proxy.corp:3128". When empty, HTTP_PROXY/HTTPS_PROXY/NO_PROXY apply.
+	ProxyURL string
+
+	// RootCAs replaces the system trust store. CAFile adds a PEM bundle to
+	// RootCAs, or to the system pool when RootCAs is nil.
+	RootCAs *x509.CertPool
+	CAFile  string
+
+	// Client certificates for mTLS, given directly or as PEM files.
+	Certificates []tls.Certificate
+	CertFile     string
+	KeyFile      string
+
+	MinTLSVersion uint16 // Default: TLS 1.2
+
+	// Connection pool tuning.
+	MaxIdleConns        int           // Default: 100
+	MaxIdleConnsPerHost int           // Default: 10
+	MaxConnsPerHost     int           // Default: unlimited
+	IdleConnTimeout     time.Duration // Default: 90s
+
+	// DisableHTTP2 forces HTTP/1.1, e.g. for proxies that mishandle HTTP/2.
+	DisableHTTP2 bool
+}
+
+// IsZero reports whether no transport option is set.
+func (o TransportOptions) IsZero() bool {
+	return o.ProxyURL == "" && o.RootCAs == nil && o.CAFile == "" &&
+		len(o.Certificates) == 0 && o.CertFile == "" && o.KeyFile == "" &&
+		o.MinTLSVersion == 0 && o.MaxIdleConns == 0 && o.MaxIdleConnsPerHost == 0 &&
+		o.MaxConnsPerHost == 0 && o.IdleConnTimeout == 0 && !o.DisableHTTP2
+}
+
+// NewTransport builds an *http.Transport from the options.
+func (o TransportOptions) NewTransport() (*http.Transport, error) {
+	t := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     !o.DisableHTTP2,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		MaxConnsPerHost:       o.MaxConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if o.MaxIdleConns > 0 {
+		t.MaxIdleConns = o.MaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+	if o.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = o.IdleConnTimeout
+	}
+	if o.DisableHTTP2 {
+		// A non-nil, empty map disables the transport's HTTP/2 upgrade.
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	if o.ProxyURL != "" {
+		proxyURL, err := url.Parse(o.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", o.ProxyURL)
+		}
+		t.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := o.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	t.TLSClientConfig = tlsConfig
+	return t, nil
+}
+
+func (o TransportOptions) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if o.MinTLSVersion != 0 {
+		cfg.MinVersion = o.MinTLSVersion
+	}
+
+	cfg.RootCAs = o.RootCAs
+	if o.CAFile != "" {
+		pemData, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := o.RootCAs
+		if pool == nil {
+			if pool, err = x509.SystemCertPool(); err != nil || pool == nil {
+				pool = x509.NewCertPool()
+			}
+		} else {
+			pool = pool.Clone()
+		}
+		if !pool.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	cfg.Certificates = append(cfg.Certificates, o.Certificates...)
+	if o.CertFile != "" || o.KeyFile != "" {
+		if o.CertFile == "" || o.KeyFile == "" {
+			return nil, fmt.Errorf("client certificate requires both CertFile and KeyFile")
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+	return cfg, nil
+}
+
+// TransportOptionsFromConfig maps the file/env transport settings.
+func TransportOptionsFromConfig(cfg *config.Config) TransportOptions {
+	return TransportOptions{
+		ProxyURL: cfg.ProxyURL,
+		CAFile:   cfg.CAFile,
+		CertFile: cfg.CertFile,
+		KeyFile:  cfg.KeyFile,
+
+		MaxIdleConns:        cfg.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     cfg.MaxConnsPerHost,
+		IdleConnTimeout:     time.Duration(cfg.IdleConnTimeout) * time.Second,
+		DisableHTTP2:        cfg.DisableHTTP2,
+	}
+}
+
+// newHTTPClient returns the HTTP client shared by discovery, handshake and
+// ingest. Precedence: a caller-supplied client, then a caller-supplied
+// transport, then TransportOptions, then Go's default transport.
+func newHTTPClient(httpClient *http.Client, transport http.RoundTripper, opts TransportOptions, timeout time.Duration) (*http.Client, error) {
+	if httpClient != nil {
+		return httpClient, nil
+	}
+	c := &http.Client{Timeout: timeout, Transport: transport}
+	if transport == nil && !opts.IsZero() {
+		t, err := opts.NewTransport()
+		if err != nil {
+			return nil, err
+		}
+		c.Transport = t
+	}
+	return c, nil
+}
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTransportOptions_InvalidProxyURL(t *testing.T) {
+	_, err := TransportOptions{ProxyURL: "://bad"}.NewTransport()
+	if err == nil {
+		t.Fatal("expected error for invalid proxy URL")
+	}
+}
+
+func TestTransportOptions_ProxyURL(t *testing.T) {
+	tr, err := TransportOptions{ProxyURL: "http://proxy.example:3128"}.NewTransport()
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://ingest.example/v1/ingest", nil)
+	proxy, err := tr.Proxy(req)
+	if err != nil || proxy == nil || proxy.Host != "proxy.example:3128" {
+		t.Fatalf("expected proxy.example:3128, got %v (err %v)", proxy, err)
+	}
+}
+
+func TestTransportOptions_DisableHTTP2(t *testing.T) {
+	tr, err := TransportOptions{DisableHTTP2: true}.NewTransport()
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	if tr.ForceAttemptHTTP2 || tr.TLSNextProto == nil {
+		t.Error("expected HTTP/2 to be disabled")
+	}
+}
+
+func TestTransportOptions_CertFileWithoutKeyFile(t *testing.T) {
+	_, err := TransportOptions{CertFile: "client.pem"}.NewTransport()
+	if err == nil {
+		t.Fatal("expected error when KeyFile is missing")
+	}
+}
+
+func TestTransportOptions_EmptyCAFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (TransportOptions{CAFile: path}).NewTransport(); err == nil {
+		t.Fatal("expected error for CA file without certificates")
+	}
+}
+
+func TestClient_CustomCAFile(t *testing.T) {
+	srv := newUnstartedFailoverServer(t)
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, pemData, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewClientWithConfig(ClientConfig{
+		APIKey:            "eu-lf_testkey123",
+		Node:              "node",
+		CustomEndpointURL: srv.URL,
+		HTTPTimeout:       2 * time.Second,
+		TransportOptions:  TransportOptions{CAFile: caFile},
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithConfig: %v", err)
+	}
+	if err := c.Info("hello"); err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if srv.ingests.Load() != 1 {
+		t.Errorf("expected 1 ingest, got %d", srv.ingests.Load())
+	}
+}
+
+func TestClient_UntrustedServerFails(t *testing.T) {
+	srv := newUnstartedFailoverServer(t)
+	srv.StartTLS()
+	defer srv.Close()
+
+	_, err := NewClientWithConfig(ClientConfig{
+		APIKey:            "eu-lf_testkey123",
+		Node:              "node",
+		CustomEndpointURL: srv.URL,
+		HTTPTimeout:       2 * time.Second,
+		TransportOptions:  TransportOptions{RootCAs: x509.NewCertPool()},
+	})
+	if err == nil {
+		t.Fatal("expected handshake to fail against an untrusted certificate")
+	}
+}
+
+func TestClient_MutualTLS(t *testing.T) {
+	srv := newUnstartedFailoverServer(t)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	// The test server's own certificate doubles as the client certificate.
+	clientCert := srv.TLS.Certificates[0]
+
+	_, err := NewClientWithConfig(ClientConfig{
+		APIKey:            "eu-lf_testkey123",
+		Node:              "node",
+		CustomEndpointURL: srv.URL,
+		HTTPTimeout:       2 * time.Second,
+		TransportOptions:  TransportOptions{RootCAs: roots},
+	})
+	if err == nil {
+		t.Fatal("expected handshake to fail without a client certificate")
+	}
+
+	c, err := NewClientWithConfig(ClientConfig{
+		APIKey:            "eu-lf_testkey123",
+		Node:              "node",
+		CustomEndpointURL: srv.URL,
+		HTTPTimeout:       2 * time.Second,
+		TransportOptions: TransportOptions{
+			RootCAs:      roots,
+			Certificates: []tls.Certificate{clientCert},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithConfig with client cert: %v", err)
+	}
+	if err := c.Info("hello"); err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+}
+
+// countingTransport counts requests passing through it.
+type countingTransport struct {
+	n atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.n.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestResilientClient_CustomTransport(t *testing.T) {
+	srv := newFailoverServer(t)
+	defer srv.Close()
+
+	rt := &countingTransport{}
+	cfg := DefaultResilientClientConfig()
+	cfg.APIKey = "eu-lf_testkey123"
+	cfg.Node = "node"
+	cfg.CustomEndpointURL = srv.URL
+	cfg.Transport = rt
+	cfg.FlushInterval = 10 * time.Millisecond
+
+	c, err := NewResilientClientWithHandshake(cfg)
+	if err != nil {
+		t.Fatalf("NewResilientClientWithHandshake: %v", err)
+	}
+	if err := c.Info("hello"); err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	// Two handshake requests plus at least one ingest.
+	if got := rt.n.Load(); got < 3 {
+		t.Errorf("expected SDK traffic through custom transport, got %d requests", got)
+	}
+	if srv.ingests.Load() == 0 {
+		t.Error("expected at least one ingest")
+	}
+}
+
+func TestResilientClient_InvalidTransportOptions(t *testing.T) {
+	cfg := DefaultResilientClientConfig()
+	cfg.APIKey = "eu-lf_testkey123"
+	cfg.Node = "node"
+	cfg.CustomEndpointURL = "http://127.0.0.1:1"
+	cfg.TransportOptions = TransportOptions{ProxyURL: "://bad"}
+	if _, err := NewResilientClientWithHandshake(cfg); err == nil {
+		t.Fatal("expected error for invalid transport options")
+	}
+}
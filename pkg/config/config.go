@@ -1,36 +1,108 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"net/url"
 	"os"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
 )
 
-// Config holds all SDK configuration loaded from environment variables.
+// Config holds all SDK configuration loaded from a config file and/or
+// environment variables.
+//
+// Each field is tagged with its file key; the matching environment variable
+// is LOGFLUX_ followed by the upper-cased key (e.g. flush_interval ->
+// LOGFLUX_FLUSH_INTERVAL). Fields marked "seconds" or "milliseconds" accept
+// either a plain integer in that unit or a Go duration string such as "1m30s".
 type Config struct {
-	APIKey      string
-	Environment string
-	Node        string
-	LogGroup    string
+	APIKey      string `config:"api_key"`
+	Environment string `config:"environment"`
+	Node        string `config:"node"`
+	Source      string `config:"source"`
+	Release     string `config:"release"`
+	LogGroup    string `config:"log_group"`
+
+	CustomEndpointURL        string   `config:"custom_endpoint_url"`
+	FallbackEndpointURLs     []string `config:"fallback_endpoint_urls"`
+	DiscoveryRefreshInterval int      `config:"discovery_refresh_interval,seconds"`
+	DiscoveryCache           bool     `config:"discovery_cache"`
+	DiscoveryCacheDir        string   `config:"discovery_cache_dir"`
+	DiscoveryCacheTTL        int      `config:"discovery_cache_ttl,seconds"` // seconds
+	FailoverCooldown         int      `config:"failover_cooldown,seconds"`   // seconds
+
+	QueueSize     int `config:"queue_size"`
+	FlushInterval int `config:"flush_interval,seconds"` // seconds
+	BatchSize     int `config:"batch_size"`
+
+	MaxRetries    int     `config:"max_retries"`
+	InitialDelay  int     `config:"initial_delay,milliseconds"` // milliseconds
+	MaxDelay      int     `config:"max_delay,seconds"`          // seconds
+	BackoffFactor float64 `config:"backoff_factor"`
+
+	HTTPTimeout int    `config:"http_timeout,seconds"` // seconds
+	ProxyURL    string `config:"proxy_url"`
+	CAFile      string `config:"ca_file"`
+	CertFile    string `config:"cert_file"`
+	KeyFile     string `config:"key_file"`
+
+	MaxIdleConns        int  `config:"max_idle_conns"`
+	MaxIdleConnsPerHost int  `config:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int  `config:"max_conns_per_host"`
+	IdleConnTimeout     int  `config:"idle_conn_timeout,seconds"` // seconds
+	DisableHTTP2        bool `config:"disable_http2"`
+
+	FailsafeMode      bool `config:"failsafe_mode"`
+	WorkerCount       int  `config:"worker_count"`
+	EnableCompression bool `config:"enable_compression"`
+	Debug             bool `config:"debug"`
+
+	SampleRate     float64 `config:"sample_rate"` // 0.0-1.0; 0 means send all
+	MaxBreadcrumbs int     `config:"max_breadcrumbs"`
+
+	MinLevel string   `config:"min_level"` // level name, e.g. "info"
+	Levels   []string `config:"levels"`    // per-logger levels, e.g. "db=debug,http=warn"
+
+	Scrub       bool     `config:"scrub"`        // enable the built-in PII/secret scrubber
+	ScrubKeys   []string `config:"scrub_keys"`   // extra denylisted attribute keys
+	ScrubIPs    bool     `config:"scrub_ips"`    // also scrub IP addresses
+	ScrubAction string   `config:"scrub_action"` // mask, hash or remove
+
+	DuplicateErrorLimit  int `config:"duplicate_error_limit"`          // errors per fingerprint per window; 0 = off
+	DuplicateErrorWindow int `config:"duplicate_error_window,seconds"` // seconds
 
-	QueueSize     int
-	FlushInterval int // seconds
-	BatchSize     int
+	MaxStackFrames    int      `config:"max_stack_frames"`
+	StackContextLines int      `config:"stack_context_lines"`
+	InAppPrefixes     []string `config:"in_app_prefixes"`
 
-	MaxRetries    int
-	InitialDelay  int // milliseconds
-	MaxDelay      int // seconds
-	BackoffFactor float64
+	RepanicOnRecover    bool `config:"repanic_on_recover"`
+	RecoverFlushTimeout int  `config:"recover_flush_timeout,seconds"` // seconds
 
-	HTTPTimeout int // seconds
+	RuntimeMetrics         bool     `config:"runtime_metrics"`                  // enable the runtime metrics collector
+	RuntimeMetricsInterval int      `config:"runtime_metrics_interval,seconds"` // seconds
+	RuntimeMetricsAllow    []string `config:"runtime_metrics_allow"`            // metric names or dotted prefixes
 
-	FailsafeMode      bool
-	WorkerCount       int
-	EnableCompression bool
-	Debug             bool
+	MetricAggregation         bool `config:"metric_aggregation"`                  // aggregate metrics in the client
+	MetricAggregationInterval int  `config:"metric_aggregation_interval,seconds"` // seconds
+	MaxMetricSeries           int  `config:"max_metric_series"`
+
+	HistogramBuckets []string `config:"histogram_buckets"` // ascending bucket upper bounds, e.g. "10,50,100"
+
+	SelfTelemetry         bool `config:"self_telemetry"`                  // send SDK statistics as metrics
+	SelfTelemetryInterval int  `config:"self_telemetry_interval,seconds"` // seconds
 }
 
+// EnvPrefix is prepended to upper-cased config keys to form env var names.
+const EnvPrefix = "LOGFLUX_"
+
 // ValidateAPIKey checks if the key matches <region>-lf_<key> format.
 func ValidateAPIKey(key string) error {
 	parts := strings.SplitN(key, "-", 2)
@@ -51,91 +123,337 @@ func ValidateAPIKey(key string) error {
 	return nil
 }
 
+// defaultConfig returns the values used for keys that are not set anywhere.
+func defaultConfig() *Config {
+	return &Config{EnableCompression: true}
+}
+
 // LoadConfig loads configuration from environment variables.
 func LoadConfig() (*Config, error) {
 	return LoadConfigFromEnv()
 }
 
 // LoadConfigFromEnv loads configuration from environment variables.
+// Malformed values and validation failures are all reported in one error.
 func LoadConfigFromEnv() (*Config, error) {
-	config := &Config{
-		APIKey:      os.Getenv("LOGFLUX_API_KEY"),
-		Environment: os.Getenv("LOGFLUX_ENVIRONMENT"),
-		Node:        os.Getenv("LOGFLUX_NODE"),
-		LogGroup:    os.Getenv("LOGFLUX_LOG_GROUP"),
+	config := defaultConfig()
+	errs := config.applyEnv()
+	return finish(config, errs)
+}
+
+// LoadConfigFile loads configuration from a YAML, JSON or TOML file (chosen
+// by extension), then applies environment variables on top, so env wins over
+// the file. Malformed values and validation failures are all reported in one
+// error.
+func LoadConfigFile(path string) (*Config, error) {
+	values, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
 	}
+	config := defaultConfig()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	if v := os.Getenv("LOGFLUX_QUEUE_SIZE"); v != "" {
-		if val, err := strconv.Atoi(v); err == nil {
-			config.QueueSize = val
+	var errs []error
+	for _, key := range keys {
+		raw := values[key]
+		f, ok := fieldsByKey[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown key %q", path, key))
+			continue
 		}
-	}
-	if v := os.Getenv("LOGFLUX_FLUSH_INTERVAL"); v != "" {
-		if val, err := strconv.Atoi(v); err == nil {
-			config.FlushInterval = val
+		if err := f.setFileValue(config, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, key, err))
 		}
 	}
-	if v := os.Getenv("LOGFLUX_BATCH_SIZE"); v != "" {
-		if val, err := strconv.Atoi(v); err == nil {
-			config.BatchSize = val
-		}
+	errs = append(errs, config.applyEnv()...)
+	return finish(config, errs)
+}
+
+func finish(config *Config, errs []error) (*Config, error) {
+	if err := config.Validate(); err != nil {
+		errs = append(errs, err)
 	}
-	if v := os.Getenv("LOGFLUX_MAX_RETRIES"); v != "" {
-		if val, err := strconv.Atoi(v); err == nil {
-			config.MaxRetries = val
-		}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
 	}
-	if v := os.Getenv("LOGFLUX_INITIAL_DELAY"); v != "" {
-		if val, err := strconv.Atoi(v); err == nil {
-			config.InitialDelay = val
-		}
+	return config, nil
+}
+
+// Validate checks the configuration and returns every problem found, joined
+// into a single error, or nil.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.APIKey == "" {
+		errs = append(errs, fmt.Errorf("api_key is required (set LOGFLUX_API_KEY or api_key in the config file)"))
+	} else if err := ValidateAPIKey(c.APIKey); err != nil {
+		errs = append(errs, err)
 	}
-	if v := os.Getenv("LOGFLUX_MAX_DELAY"); v != "" {
-		if val, err := strconv.Atoi(v); err == nil {
-			config.MaxDelay = val
+
+	nonNegative := []struct {
+		name  string
+		value int
+	}{
+		{"queue_size", c.QueueSize},
+		{"flush_interval", c.FlushInterval},
+		{"batch_size", c.BatchSize},
+		{"max_retries", c.MaxRetries},
+		{"initial_delay", c.InitialDelay},
+		{"max_delay", c.MaxDelay},
+		{"http_timeout", c.HTTPTimeout},
+		{"worker_count", c.WorkerCount},
+		{"max_breadcrumbs", c.MaxBreadcrumbs},
+		{"discovery_refresh_interval", c.DiscoveryRefreshInterval},
+		{"discovery_cache_ttl", c.DiscoveryCacheTTL},
+		{"failover_cooldown", c.FailoverCooldown},
+		{"max_idle_conns", c.MaxIdleConns},
+		{"max_idle_conns_per_host", c.MaxIdleConnsPerHost},
+		{"max_conns_per_host", c.MaxConnsPerHost},
+		{"idle_conn_timeout", c.IdleConnTimeout},
+	}
+	for _, f := range nonNegative {
+		if f.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative (got %d)", f.name, f.value))
 		}
 	}
-	if v := os.Getenv("LOGFLUX_BACKOFF_FACTOR"); v != "" {
-		if val, err := strconv.ParseFloat(v, 64); err == nil {
-			config.BackoffFactor = val
+	if c.QueueSize > 0 && c.BatchSize > c.QueueSize {
+		errs = append(errs, fmt.Errorf("batch_size (%d) must not exceed queue_size (%d)", c.BatchSize, c.QueueSize))
+	}
+	if c.BackoffFactor != 0 && c.BackoffFactor < 1 {
+		errs = append(errs, fmt.Errorf("backoff_factor must be at least 1 (got %g)", c.BackoffFactor))
+	}
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("sample_rate must be between 0 and 1 (got %g)", c.SampleRate))
+	}
+	if _, err := c.MinLogLevel(); err != nil {
+		errs = append(errs, fmt.Errorf("min_level: %w", err))
+	}
+	if _, err := c.LoggerLevels(); err != nil {
+		errs = append(errs, fmt.Errorf("levels: %w", err))
+	}
+	if _, err := c.ScrubOptions(); err != nil {
+		errs = append(errs, fmt.Errorf("scrub_action: %w", err))
+	}
+	if _, err := c.HistogramBounds(); err != nil {
+		errs = append(errs, fmt.Errorf("histogram_buckets: %w", err))
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("cert_file and key_file must be set together"))
+	}
+
+	urls := append([]string{c.CustomEndpointURL, c.ProxyURL}, c.FallbackEndpointURLs...)
+	for _, u := range urls {
+		if u == "" {
+			continue
 		}
+		if parsed, err := url.Parse(u); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid URL %q", u))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// MinLogLevel returns MinLevel as a models.LogLevel* value, or 0 if unset.
+func (c *Config) MinLogLevel() (int, error) {
+	if c.MinLevel == "" {
+		return 0, nil
+	}
+	return models.ParseLogLevel(c.MinLevel)
+}
+
+// LoggerLevels parses Levels ("name=level" items) into a map from logger name
+// to models.LogLevel* value. It returns nil if no levels are set.
+func (c *Config) LoggerLevels() (map[string]int, error) {
+	if len(c.Levels) == 0 {
+		return nil, nil
 	}
-	if v := os.Getenv("LOGFLUX_HTTP_TIMEOUT"); v != "" {
-		if val, err := strconv.Atoi(v); err == nil {
-			config.HTTPTimeout = val
+	levels := make(map[string]int, len(c.Levels))
+	for _, item := range c.Levels {
+		name, value, ok := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected name=level, got %q", item)
+		}
+		level, err := models.ParseLogLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
 		}
+		levels[name] = level
 	}
-	if v := os.Getenv("LOGFLUX_FAILSAFE_MODE"); v != "" {
-		if val, err := strconv.ParseBool(v); err == nil {
-			config.FailsafeMode = val
+	return levels, nil
+}
+
+// HistogramBounds parses HistogramBuckets into ascending bucket upper
+// bounds. It returns nil if no buckets are set.
+func (c *Config) HistogramBounds() ([]float64, error) {
+	if len(c.HistogramBuckets) == 0 {
+		return nil, nil
+	}
+	bounds := make([]float64, len(c.HistogramBuckets))
+	for i, item := range c.HistogramBuckets {
+		n, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", item)
+		}
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, fmt.Errorf("bound %q is not finite", item)
+		}
+		if i > 0 && n <= bounds[i-1] {
+			return nil, fmt.Errorf("bounds must be ascending (%g after %g)", n, bounds[i-1])
 		}
+		bounds[i] = n
 	}
-	if v := os.Getenv("LOGFLUX_WORKER_COUNT"); v != "" {
-		if val, err := strconv.Atoi(v); err == nil {
-			config.WorkerCount = val
+	return bounds, nil
+}
+
+// ScrubOptions returns the scrubber settings, or nil if scrubbing is not
+// enabled. Setting ScrubKeys, ScrubIPs or ScrubAction implies Scrub.
+func (c *Config) ScrubOptions() (*payload.ScrubOptions, error) {
+	if !c.Scrub && len(c.ScrubKeys) == 0 && !c.ScrubIPs && c.ScrubAction == "" {
+		return nil, nil
+	}
+	action, err := payload.ParseScrubAction(c.ScrubAction)
+	if err != nil {
+		return nil, err
+	}
+	return &payload.ScrubOptions{
+		Action:   action,
+		DenyKeys: c.ScrubKeys,
+		IPs:      c.ScrubIPs,
+	}, nil
+}
+
+// applyEnv overlays every LOGFLUX_* variable that is set.
+func (c *Config) applyEnv() []error {
+	var errs []error
+	for _, f := range fields {
+		env := EnvPrefix + strings.ToUpper(f.key)
+		v, ok := os.LookupEnv(env)
+		if !ok || v == "" {
+			continue
+		}
+		if err := f.set(c, v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", env, err))
 		}
 	}
+	return errs
+}
+
+// field binds a config key to a Config struct field.
+type field struct {
+	key   string
+	index int
+	unit  time.Duration // non-zero for duration fields stored as integers
+}
+
+var (
+	fields      = configFields()
+	fieldsByKey = func() map[string]field {
+		m := make(map[string]field, len(fields))
+		for _, f := range fields {
+			m[f.key] = f
+		}
+		return m
+	}()
+)
 
-	// Compression defaults to true
-	config.EnableCompression = true
-	if v := os.Getenv("LOGFLUX_ENABLE_COMPRESSION"); v != "" {
-		if val, err := strconv.ParseBool(v); err == nil {
-			config.EnableCompression = val
+func configFields() []field {
+	t := reflect.TypeOf(Config{})
+	out := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("config")
+		if tag == "" {
+			continue
+		}
+		key, opt, _ := strings.Cut(tag, ",")
+		f := field{key: key, index: i}
+		switch opt {
+		case "seconds":
+			f.unit = time.Second
+		case "milliseconds":
+			f.unit = time.Millisecond
 		}
+		out = append(out, f)
 	}
+	return out
+}
 
-	if v := os.Getenv("LOGFLUX_DEBUG"); v != "" {
-		if val, err := strconv.ParseBool(v); err == nil {
-			config.Debug = val
+// set parses raw and stores it in the field. Lists are comma-separated.
+func (f field) set(c *Config, raw string) error {
+	v := reflect.ValueOf(c).Elem().Field(f.index)
+	raw = strings.TrimSpace(raw)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := parseInt(raw, f.unit)
+		if err != nil {
+			return err
 		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		var list []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
 	}
+	return nil
+}
 
-	if config.APIKey == "" {
-		return nil, fmt.Errorf("LOGFLUX_API_KEY environment variable is required")
+// setFileValue stores a value read from a config file. Strings and list
+// items are stored as given, so they may contain commas.
+func (f field) setFileValue(c *Config, raw fileValue) error {
+	v := reflect.ValueOf(c).Elem().Field(f.index)
+	if !raw.list {
+		if v.Kind() == reflect.String {
+			// Keep whitespace from quoted strings; plain ones are already trimmed.
+			v.SetString(raw.text)
+			return nil
+		}
+		return f.set(c, raw.text)
 	}
-	if err := ValidateAPIKey(config.APIKey); err != nil {
-		return nil, err
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("expected a single value, not a list")
 	}
+	var items []string
+	for _, item := range raw.items {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	v.Set(reflect.ValueOf(items))
+	return nil
+}
 
-	return config, nil
+// parseInt parses an integer, or a duration string converted to unit when
+// unit is non-zero.
+func parseInt(raw string, unit time.Duration) (int, error) {
+	if n, err := strconv.Atoi(raw); err == nil {
+		return n, nil
+	}
+	if unit != 0 {
+		if d, err := time.ParseDuration(raw); err == nil {
+			return int(d / unit), nil
+		}
+		return 0, fmt.Errorf("invalid duration %q", raw)
+	}
+	return 0, fmt.Errorf("invalid integer %q", raw)
 }
@@ -0,0 +1,316 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxConfigFileSize bounds how much of a config file is read.
+const maxConfigFileSize = 1 << 20 // 1 MiB
+
+// fileValue is a raw value read from a config file: a scalar, or the items
+// of a list. List items are kept apart so that they may contain commas.
+type fileValue struct {
+	text  string
+	items []string
+	list  bool
+}
+
+func scalar(s string) fileValue     { return fileValue{text: s} }
+func list(items []string) fileValue { return fileValue{items: items, list: true} }
+
+// readConfigFile reads a flat config document into key -> raw value pairs.
+//
+// SDK config is a flat set of scalars and string lists, so YAML and TOML are
+// parsed with a small built-in reader for that subset (key/value pairs,
+// quoted strings, inline and block lists, comments) rather than pulling
+// third-party parsers into every application that imports the SDK.
+func readConfigFile(path string) (map[string]fileValue, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if info.Size() > maxConfigFileSize {
+		return nil, fmt.Errorf("config file %s is too large (%d bytes)", path, info.Size())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var values map[string]fileValue
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		values, err = parseJSON(data)
+	case ".yaml", ".yml":
+		values, err = parseYAML(data)
+	case ".toml":
+		values, err = parseTOML(data)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q (use .yaml, .yml, .json or .toml)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func parseJSON(data []byte) (map[string]fileValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	values := make(map[string]fileValue, len(doc))
+	for key, v := range doc {
+		fv, err := jsonValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if fv != nil {
+			values[key] = *fv
+		}
+	}
+	return values, nil
+}
+
+// jsonValue converts a JSON value to a raw config value. Null yields nil.
+func jsonValue(v any) (*fileValue, error) {
+	var fv fileValue
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		fv = scalar(v)
+	case json.Number:
+		fv = scalar(v.String())
+	case bool:
+		fv = scalar(fmt.Sprint(v))
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("lists may only contain strings")
+			}
+			items = append(items, str)
+		}
+		fv = list(items)
+	default:
+		return nil, fmt.Errorf("nested objects are not supported")
+	}
+	return &fv, nil
+}
+
+func parseYAML(data []byte) (map[string]fileValue, error) {
+	values := make(map[string]fileValue)
+	var listKey string // key whose block list ("- item") is being read
+	var items []string
+
+	flush := func() {
+		if listKey != "" {
+			values[listKey] = list(items)
+			listKey, items = "", nil
+		}
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		raw := stripComment(sc.Text())
+		line := strings.TrimSpace(raw)
+		if line == "" || line == "---" {
+			continue
+		}
+		if strings.HasPrefix(line, "- ") || line == "-" {
+			if listKey == "" {
+				return nil, fmt.Errorf("line %d: list item without a key", lineNo)
+			}
+			item, err := unquote(strings.TrimSpace(strings.TrimPrefix(line, "-")))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			items = append(items, item)
+			continue
+		}
+		flush()
+		if raw != strings.TrimLeft(raw, " \t") {
+			return nil, fmt.Errorf("line %d: nested values are not supported", lineNo)
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNo)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch {
+		case value == "":
+			listKey = key
+		case value == "~" || value == "null":
+			// Explicit null: leave the default.
+		default:
+			fv, err := parseValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			values[key] = fv
+		}
+	}
+	flush()
+	return values, sc.Err()
+}
+
+func parseTOML(data []byte) (map[string]fileValue, error) {
+	values := make(map[string]fileValue)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := strings.TrimSpace(stripComment(sc.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			return nil, fmt.Errorf("line %d: tables are not supported", lineNo)
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key = value\"", lineNo)
+		}
+		key, err := unquote(strings.TrimSpace(key))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		fv, err := parseValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		values[key] = fv
+	}
+	return values, sc.Err()
+}
+
+// parseValue parses an inline list or a scalar.
+func parseValue(value string) (fileValue, error) {
+	if !strings.HasPrefix(value, "[") {
+		s, err := unquote(value)
+		return scalar(s), err
+	}
+	items, err := parseInlineList(value)
+	return list(items), err
+}
+
+// parseInlineList parses `["a", "b"]` into its items. Commas inside quoted
+// items do not separate items; a trailing comma is allowed.
+func parseInlineList(value string) ([]string, error) {
+	var parts []string
+	start := 1
+	for i := 1; i < len(value); i++ {
+		switch c := value[i]; {
+		case (c == '"' || c == '\'') && strings.TrimSpace(value[start:i]) == "":
+			end := closingQuote(value[i:])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in list %s", value)
+			}
+			i += end
+		case c == ',':
+			parts = append(parts, value[start:i])
+			start = i + 1
+		case c == '[':
+			return nil, fmt.Errorf("nested lists are not supported")
+		case c == ']':
+			if rest := strings.TrimSpace(value[i+1:]); rest != "" {
+				return nil, fmt.Errorf("unexpected %q after list", rest)
+			}
+			if last := strings.TrimSpace(value[start:i]); last != "" || len(parts) == 0 {
+				parts = append(parts, value[start:i])
+			}
+			items := make([]string, 0, len(parts))
+			for _, p := range parts {
+				p = strings.TrimSpace(p)
+				if p == "" {
+					if len(parts) == 1 {
+						return nil, nil // []
+					}
+					return nil, fmt.Errorf("empty item in list %s", value)
+				}
+				item, err := unquote(p)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			return items, nil
+		}
+	}
+	return nil, fmt.Errorf("unterminated list")
+}
+
+// stripComment removes a # comment that is not inside quotes. As in YAML, a
+// # only starts a comment at the start of a line or after whitespace.
+func stripComment(line string) string {
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquote returns the value of a plain, single- or double-quoted scalar.
+// Double-quoted strings honor backslash escapes (\", \\, \n, \t, \uXXXX);
+// single-quoted strings are literal, with ” standing for one quote as in
+// YAML.
+func unquote(s string) (string, error) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return s, nil
+	}
+	end := closingQuote(s)
+	if end < 0 {
+		return "", fmt.Errorf("unterminated string %s", s)
+	}
+	if end != len(s)-1 {
+		return "", fmt.Errorf("unexpected %q after string", s[end+1:])
+	}
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:end], "''", "'"), nil
+	}
+	v, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid escape in string %s", s)
+	}
+	return v, nil
+}
+
+// closingQuote returns the index of the quote that closes the string s
+// starts with, or -1 if it is unterminated.
+func closingQuote(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q && q == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == q:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,357 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// clearEnv unsets every LOGFLUX_* variable for the duration of the test.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, f := range fields {
+		t.Setenv(EnvPrefix+strings.ToUpper(f.key), "")
+	}
+}
+
+var wantFromFile = Config{
+	APIKey:               "eu-lf_filekey",
+	Source:               "billing",
+	Release:              "1.4.2",
+	CustomEndpointURL:    "https://ingest.example.com",
+	FallbackEndpointURLs: []string{"https://a.example.com", "https://b.example.com"},
+	FlushInterval:        90,
+	InitialDelay:         250,
+	SampleRate:           0.25,
+	MaxBreadcrumbs:       50,
+	EnableCompression:    false,
+	Debug:                true,
+}
+
+func TestLoadConfigFile_Formats(t *testing.T) {
+	files := map[string]string{
+		"logflux.yaml": `
+# LogFlux SDK
+api_key: "eu-lf_filekey"
+source: billing
+release: '1.4.2'
+custom_endpoint_url: https://ingest.example.com # primary
+fallback_endpoint_urls:
+  - https://a.example.com
+  - https://b.example.com
+flush_interval: 1m30s
+initial_delay: 250
+sample_rate: 0.25
+max_breadcrumbs: 50
+enable_compression: false
+debug: true
+`,
+		"logflux.json": `{
+  "api_key": "eu-lf_filekey",
+  "source": "billing",
+  "release": "1.4.2",
+  "custom_endpoint_url": "https://ingest.example.com",
+  "fallback_endpoint_urls": ["https://a.example.com", "https://b.example.com"],
+  "flush_interval": 90,
+  "initial_delay": "250ms",
+  "sample_rate": 0.25,
+  "max_breadcrumbs": 50,
+  "enable_compression": false,
+  "debug": true
+}`,
+		"logflux.toml": `
+# LogFlux SDK
+api_key = "eu-lf_filekey"
+source = "billing"
+release = "1.4.2"
+custom_endpoint_url = "https://ingest.example.com"
+fallback_endpoint_urls = ["https://a.example.com", "https://b.example.com"]
+flush_interval = "90s"
+initial_delay = 250
+sample_rate = 0.25
+max_breadcrumbs = 50
+enable_compression = false
+debug = true
+`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			cfg, err := LoadConfigFile(writeConfigFile(t, name, content))
+			if err != nil {
+				t.Fatalf("LoadConfigFile: %v", err)
+			}
+			if !reflect.DeepEqual(*cfg, wantFromFile) {
+				t.Fatalf("got %+v\nwant %+v", *cfg, wantFromFile)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFile_EnvOverridesFile(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, "logflux.yaml", "api_key: eu-lf_filekey\nsource: from-file\nqueue_size: 10\n")
+	t.Setenv("LOGFLUX_SOURCE", "from-env")
+
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile: %v", err)
+	}
+	if cfg.Source != "from-env" {
+		t.Errorf("Source = %q, want env value", cfg.Source)
+	}
+	if cfg.QueueSize != 10 {
+		t.Errorf("QueueSize = %d, want file value", cfg.QueueSize)
+	}
+	if !cfg.EnableCompression {
+		t.Error("EnableCompression should default to true")
+	}
+}
+
+func TestLoadConfigFile_ReportsAllErrors(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, "logflux.yaml", `
+api_key: us-nope
+queue_size: lots
+sample_rate: 2
+colour: blue
+`)
+	t.Setenv("LOGFLUX_DEBUG", "sometimes")
+
+	_, err := LoadConfigFile(path)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"queue_size", "sample_rate", `unknown key "colour"`, "LOGFLUX_DEBUG", "API key"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadConfigFile_UnsupportedExtension(t *testing.T) {
+	if _, err := LoadConfigFile(writeConfigFile(t, "logflux.ini", "api_key=x")); err == nil {
+		t.Fatal("expected error for .ini")
+	}
+}
+
+func TestLoadConfigFile_NestedYAMLRejected(t *testing.T) {
+	path := writeConfigFile(t, "logflux.yaml", "logflux:\n  api_key: eu-lf_x\n")
+	if _, err := LoadConfigFile(path); err == nil {
+		t.Fatal("expected error for nested YAML")
+	}
+}
+
+func TestLoadConfigFromEnv_NewFields(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("LOGFLUX_API_KEY", "eu-lf_testkey123")
+	t.Setenv("LOGFLUX_SOURCE", "api")
+	t.Setenv("LOGFLUX_RELEASE", "2.0.0")
+	t.Setenv("LOGFLUX_SAMPLE_RATE", "0.5")
+	t.Setenv("LOGFLUX_CUSTOM_ENDPOINT_URL", "https://ingest.example.com")
+	t.Setenv("LOGFLUX_MAX_BREADCRUMBS", "20")
+	t.Setenv("LOGFLUX_FALLBACK_ENDPOINT_URLS", "https://a.example.com, https://b.example.com")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv: %v", err)
+	}
+	if cfg.Source != "api" || cfg.Release != "2.0.0" || cfg.SampleRate != 0.5 ||
+		cfg.CustomEndpointURL != "https://ingest.example.com" || cfg.MaxBreadcrumbs != 20 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if len(cfg.FallbackEndpointURLs) != 2 || cfg.FallbackEndpointURLs[1] != "https://b.example.com" {
+		t.Fatalf("FallbackEndpointURLs = %v", cfg.FallbackEndpointURLs)
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidValue(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("LOGFLUX_API_KEY", "eu-lf_testkey123")
+	t.Setenv("LOGFLUX_QUEUE_SIZE", "big")
+	if _, err := LoadConfigFromEnv(); err == nil || !strings.Contains(err.Error(), "LOGFLUX_QUEUE_SIZE") {
+		t.Fatalf("expected LOGFLUX_QUEUE_SIZE error, got %v", err)
+	}
+}
+
+func TestValidate_JoinsErrors(t *testing.T) {
+	cfg := &Config{
+		APIKey:        "eu-lf_testkey123",
+		QueueSize:     10,
+		BatchSize:     20,
+		BackoffFactor: 0.5,
+		CertFile:      "client.pem",
+		ProxyURL:      "not a url",
+	}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) || len(joined.Unwrap()) != 4 {
+		t.Fatalf("expected 4 joined errors, got %v", err)
+	}
+}
+
+func TestLoadConfigFromEnv_Levels(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("LOGFLUX_API_KEY", "eu-lf_testkey123")
+	t.Setenv("LOGFLUX_MIN_LEVEL", "info")
+	t.Setenv("LOGFLUX_LEVELS", "db=debug, http=warn")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv: %v", err)
+	}
+	if min, _ := cfg.MinLogLevel(); min != 7 {
+		t.Errorf("MinLogLevel = %d, want 7", min)
+	}
+	levels, _ := cfg.LoggerLevels()
+	if want := map[string]int{"db": 8, "http": 5}; !reflect.DeepEqual(levels, want) {
+		t.Errorf("LoggerLevels = %v, want %v", levels, want)
+	}
+
+	t.Setenv("LOGFLUX_LEVELS", "db=verbose,http")
+	if _, err := LoadConfigFromEnv(); err == nil || !strings.Contains(err.Error(), "levels") {
+		t.Fatalf("expected levels error, got %v", err)
+	}
+}
+
+func TestLoadConfigFromEnv_Scrub(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("LOGFLUX_API_KEY", "eu-lf_testkey123")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv: %v", err)
+	}
+	if opts, _ := cfg.ScrubOptions(); opts != nil {
+		t.Errorf("scrubbing should be off by default, got %+v", opts)
+	}
+
+	t.Setenv("LOGFLUX_SCRUB_KEYS", "ssn, tax_id")
+	t.Setenv("LOGFLUX_SCRUB_ACTION", "hash")
+	cfg, err = LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv: %v", err)
+	}
+	opts, _ := cfg.ScrubOptions()
+	if opts == nil || opts.Action != payload.ScrubHash || !reflect.DeepEqual(opts.DenyKeys, []string{"ssn", "tax_id"}) {
+		t.Errorf("ScrubOptions = %+v", opts)
+	}
+
+	t.Setenv("LOGFLUX_SCRUB_ACTION", "shred")
+	if _, err := LoadConfigFromEnv(); err == nil || !strings.Contains(err.Error(), "scrub_action") {
+		t.Fatalf("expected scrub_action error, got %v", err)
+	}
+}
+
+func TestLoadConfigFromEnv_HistogramBuckets(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("LOGFLUX_API_KEY", "eu-lf_testkey123")
+	t.Setenv("LOGFLUX_HISTOGRAM_BUCKETS", "10, 50, 100.5")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv: %v", err)
+	}
+	if bounds, _ := cfg.HistogramBounds(); !reflect.DeepEqual(bounds, []float64{10, 50, 100.5}) {
+		t.Errorf("HistogramBounds = %v", bounds)
+	}
+
+	for _, bad := range []string{"50,10", "10,Inf", "NaN"} {
+		t.Setenv("LOGFLUX_HISTOGRAM_BUCKETS", bad)
+		if _, err := LoadConfigFromEnv(); err == nil || !strings.Contains(err.Error(), "histogram_buckets") {
+			t.Fatalf("%q: expected histogram_buckets error, got %v", bad, err)
+		}
+	}
+}
+
+func TestLoadConfigFile_QuotedStrings(t *testing.T) {
+	files := map[string]string{
+		"logflux.yaml": `
+api_key: eu-lf_filekey
+source: "a\"b\n"
+release: 'it''s # not a comment'
+in_app_prefixes: ["a,b", 'c', d,]
+scrub_keys:
+  - "x,y"
+  - z
+`,
+		"logflux.toml": `
+api_key = "eu-lf_filekey"
+source = "a\"b\n"
+release = "it's # not a comment"
+in_app_prefixes = ["a,b", 'c', "d",]
+scrub_keys = ["x,y", "z"]
+`,
+		"logflux.json": `{
+  "api_key": "eu-lf_filekey",
+  "source": "a\"b\n",
+  "release": "it's # not a comment",
+  "in_app_prefixes": ["a,b", "c", "d"],
+  "scrub_keys": ["x,y", "z"]
+}`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			cfg, err := LoadConfigFile(writeConfigFile(t, name, content))
+			if err != nil {
+				t.Fatalf("LoadConfigFile: %v", err)
+			}
+			if cfg.Source != "a\"b\n" || cfg.Release != "it's # not a comment" {
+				t.Errorf("Source = %q, Release = %q", cfg.Source, cfg.Release)
+			}
+			if want := []string{"a,b", "c", "d"}; !reflect.DeepEqual(cfg.InAppPrefixes, want) {
+				t.Errorf("InAppPrefixes = %q, want %q", cfg.InAppPrefixes, want)
+			}
+			if want := []string{"x,y", "z"}; !reflect.DeepEqual(cfg.ScrubKeys, want) {
+				t.Errorf("ScrubKeys = %q, want %q", cfg.ScrubKeys, want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFile_UnparsableValues(t *testing.T) {
+	cases := map[string]string{
+		"unterminated string":  `source: "billing`,
+		"text after string":    `source: "billing" api`,
+		"invalid escape":       `source: "bill\qing"`,
+		"unterminated list":    `in_app_prefixes: ["a", "b"`,
+		"unterminated in list": `in_app_prefixes: ["a, b]`,
+		"nested list":          `in_app_prefixes: [["a"]]`,
+		"empty item":           `in_app_prefixes: [a,,b]`,
+		"text after list":      `in_app_prefixes: [a] b`,
+		"list for scalar":      `source: [a, b]`,
+		"block list item":      "scrub_keys:\n  - \"x",
+	}
+	for name, line := range cases {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			path := writeConfigFile(t, "logflux.yaml", "api_key: eu-lf_filekey\n"+line+"\n")
+			if _, err := LoadConfigFile(path); err == nil {
+				t.Fatalf("expected error for %q", line)
+			}
+		})
+	}
+	clearEnv(t)
+	path := writeConfigFile(t, "logflux.toml", "api_key = \"eu-lf_filekey\"\nsource = \"a\\x\"\n")
+	if _, err := LoadConfigFile(path); err == nil {
+		t.Fatal("expected error for an invalid TOML escape")
+	}
+}
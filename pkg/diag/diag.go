@@ -0,0 +1,51 @@
+// Package diag provides the SDK's internal diagnostic logger.
+//
+// Diagnostics describe what the SDK itself is doing (discovery, handshake,
+// retries, drops, rate-limit pauses) and are never sent to LogFlux. They are
+// off by default; enable them with Options.Debug or LOGFLUX_DEBUG, or supply
+// a Logger such as an *slog.Logger.
+package diag
+
+import (
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Logger receives diagnostic events as a message plus alternating key/value
+// pairs. *slog.Logger satisfies it.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// Nop discards all events.
+var Nop Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+// New returns a Logger writing text records at debug level and above to w.
+func New(w io.Writer) Logger {
+	h := slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(h).With("component", "logflux-sdk")
+}
+
+// NewStderr returns a Logger writing to standard error.
+func NewStderr() Logger {
+	return New(os.Stderr)
+}
+
+// OrNop returns l, or Nop when l is nil.
+func OrNop(l Logger) Logger {
+	if l == nil {
+		return Nop
+	}
+	return l
+}
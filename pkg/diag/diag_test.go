@@ -0,0 +1,30 @@
+package diag
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew_WritesStructuredRecords(t *testing.T) {
+	var buf bytes.Buffer
+	New(&buf).Debug("handshake completed", "endpoint", "https://ingest.example.com")
+	out := buf.String()
+	for _, want := range []string{"level=DEBUG", `msg="handshake completed"`, "endpoint=https://ingest.example.com", "component=logflux-sdk"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q missing %q", out, want)
+		}
+	}
+}
+
+func TestOrNop(t *testing.T) {
+	if OrNop(nil) != Nop {
+		t.Error("nil logger should map to Nop")
+	}
+	var l Logger = slog.Default()
+	if OrNop(l) != l {
+		t.Error("non-nil logger should be returned as-is")
+	}
+	Nop.Error("discarded", "k", "v") // must not panic
+}
@@ -0,0 +1,200 @@
+package discovery
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Default discovery cache lifetimes.
+const (
+	DefaultCacheTTL      = 24 * time.Hour
+	DefaultCacheMaxStale = 7 * 24 * time.Hour
+)
+
+// maxCacheFileSize bounds how much of a cache file is read.
+const maxCacheFileSize = 64 << 10 // 64 KiB
+
+// FileCache persists discovery results on disk so process start-up can skip
+// the discovery round trip, and still start when discovery is unreachable.
+//
+// Entries younger than TTL are used as-is. Entries older than TTL but within
+// TTL+MaxStale are used immediately and revalidated in the background.
+// Older entries are only used when live discovery fails.
+type FileCache struct {
+	Dir      string
+	TTL      time.Duration
+	MaxStale time.Duration
+}
+
+// cacheRecord is the on-disk format. The API key itself is never written;
+// files are keyed by a hash of it.
+type cacheRecord struct {
+	StoredAt  time.Time     `json:"stored_at"`
+	Endpoints *EndpointInfo `json:"endpoints"`
+}
+
+// NewFileCache creates a cache in dir. An empty dir uses the user cache
+// directory (e.g. ~/.cache/logflux). Zero durations use the defaults.
+func NewFileCache(dir string, ttl, maxStale time.Duration) (*FileCache, error) {
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("no cache directory available: %w", err)
+		}
+		dir = filepath.Join(base, "logflux")
+	}
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	if maxStale <= 0 {
+		maxStale = DefaultCacheMaxStale
+	}
+	return &FileCache{Dir: dir, TTL: ttl, MaxStale: maxStale}, nil
+}
+
+// Load returns the cached endpoints for apiKey and the age of the entry.
+func (c *FileCache) Load(apiKey string) (*EndpointInfo, time.Duration, bool) {
+	f, err := os.Open(c.path(apiKey))
+	if err != nil {
+		return nil, 0, false
+	}
+	defer f.Close()
+
+	var rec cacheRecord
+	if err := json.NewDecoder(io.LimitReader(f, maxCacheFileSize)).Decode(&rec); err != nil {
+		return nil, 0, false
+	}
+	if rec.Endpoints == nil || rec.Endpoints.BaseURL == "" {
+		return nil, 0, false
+	}
+	return rec.Endpoints, time.Since(rec.StoredAt), true
+}
+
+// Store writes endpoints for apiKey. The file is replaced atomically and is
+// readable only by the current user.
+func (c *FileCache) Store(apiKey string, info *EndpointInfo) error {
+	if info == nil {
+		return nil
+	}
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.Marshal(cacheRecord{StoredAt: time.Now().UTC(), Endpoints: info})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.Dir, ".discovery-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(apiKey))
+}
+
+// Delete removes the entry for apiKey, if any.
+func (c *FileCache) Delete(apiKey string) error {
+	if err := os.Remove(c.path(apiKey)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *FileCache) path(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return filepath.Join(c.Dir, "discovery-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// DiscoverEndpointsCached discovers endpoints, consulting the configured
+// cache first. The returned bool reports whether the result came from a stale
+// cache entry; callers should then revalidate in the background, e.g. with
+// RefreshPool. Without a cache this is equivalent to DiscoverEndpoints.
+func (d *DiscoveryClient) DiscoverEndpointsCached(ctx context.Context) (*EndpointInfo, bool, error) {
+	if d.cache == nil {
+		info, err := d.DiscoverEndpoints(ctx, "")
+		return info, false, err
+	}
+
+	cached, age, ok := d.cache.Load(d.apiKey)
+	if ok && age < d.cache.TTL {
+		d.logger().Debug("discovery cache hit", "ingestor", cached.BaseURL, "age", age.Round(time.Second))
+		return d.servedFromCache(cached), false, nil
+	}
+	if ok && age < d.cache.TTL+d.cache.MaxStale {
+		d.logger().Debug("discovery cache stale, revalidating", "ingestor", cached.BaseURL, "age", age.Round(time.Second))
+		return d.servedFromCache(cached), true, nil
+	}
+
+	info, err := d.DiscoverEndpoints(ctx, "")
+	if err != nil {
+		if ok {
+			// Offline-tolerant: an old answer beats no answer.
+			d.logger().Warn("discovery failed, using expired cache entry", "ingestor", cached.BaseURL, "age", age.Round(time.Second), "error", err)
+			return d.servedFromCache(cached), true, nil
+		}
+		return nil, false, err
+	}
+	d.storeLive(info)
+	return info, false, nil
+}
+
+// servedFromCache records info as the result taken from the cache.
+func (d *DiscoveryClient) servedFromCache(info *EndpointInfo) *EndpointInfo {
+	d.cacheMu.Lock()
+	d.fromCache = info
+	d.cacheMu.Unlock()
+	return info
+}
+
+// storeLive caches a live discovery result.
+func (d *DiscoveryClient) storeLive(info *EndpointInfo) {
+	d.cacheMu.Lock()
+	d.fromCache = nil
+	d.cacheMu.Unlock()
+	if err := d.cache.Store(d.apiKey, info); err != nil {
+		d.logger().Debug("discovery cache write failed", "error", err)
+	}
+}
+
+// IsCached reports whether info was served by DiscoverEndpointsCached from
+// the cache rather than by live discovery, and has not been superseded by a
+// live result since.
+func (d *DiscoveryClient) IsCached(info *EndpointInfo) bool {
+	if info == nil {
+		return false
+	}
+	d.cacheMu.Lock()
+	defer d.cacheMu.Unlock()
+	return info == d.fromCache
+}
+
+// Rediscover runs live discovery and caches the result. It is used when
+// cached endpoints turn out to be unreachable, e.g. because the ingestor was
+// decommissioned within the cache TTL. If discovery fails the cache entry is
+// kept: it is still the last known-good answer for the next start-up.
+func (d *DiscoveryClient) Rediscover(ctx context.Context) (*EndpointInfo, error) {
+	info, err := d.DiscoverEndpoints(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if d.cache != nil {
+		d.storeLive(info)
+	}
+	return info, nil
+}
@@ -0,0 +1,211 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeCacheRecord stores info with a backdated timestamp.
+func writeCacheRecord(t *testing.T, c *FileCache, apiKey string, info *EndpointInfo, age time.Duration) {
+	t.Helper()
+	data, err := json.Marshal(cacheRecord{StoredAt: time.Now().Add(-age), Endpoints: info})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.path(apiKey), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newCountingDiscoveryServer(t *testing.T, hits *atomic.Int32, status int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ingestor_url": "https://live"})
+	}))
+}
+
+func TestFileCache_StoreLoadRoundTrip(t *testing.T) {
+	c, err := NewFileCache(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := c.Load("k"); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+	if err := c.Store("k", &EndpointInfo{BaseURL: "https://cached", Region: "eu"}); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	info, age, ok := c.Load("k")
+	if !ok || info.BaseURL != "https://cached" || info.Region != "eu" {
+		t.Fatalf("unexpected load result: %+v ok=%v", info, ok)
+	}
+	if age > time.Minute {
+		t.Errorf("unexpected age %v", age)
+	}
+
+	st, err := os.Stat(c.path("k"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Mode().Perm() != 0o600 {
+		t.Errorf("expected 0600 permissions, got %v", st.Mode().Perm())
+	}
+	if _, _, ok := c.Load("other-key"); ok {
+		t.Error("entries must be keyed by API key")
+	}
+}
+
+func TestDiscoverEndpointsCached_FreshSkipsNetwork(t *testing.T) {
+	var hits atomic.Int32
+	srv := newCountingDiscoveryServer(t, &hits, http.StatusOK)
+	defer srv.Close()
+
+	cache, _ := NewFileCache(t.TempDir(), time.Hour, time.Hour)
+	writeCacheRecord(t, cache, "k", &EndpointInfo{BaseURL: "https://cached"}, time.Minute)
+
+	d := NewDiscoveryClient(DiscoveryConfig{APIKey: "k", Timeout: time.Second, Cache: cache})
+	d.baseURL = srv.URL
+
+	info, stale, err := d.DiscoverEndpointsCached(context.Background())
+	if err != nil || stale || info.BaseURL != "https://cached" {
+		t.Fatalf("expected fresh cached result, got %+v stale=%v err=%v", info, stale, err)
+	}
+	if hits.Load() != 0 {
+		t.Errorf("fresh cache hit must not call discovery, got %d calls", hits.Load())
+	}
+}
+
+func TestDiscoverEndpointsCached_StaleIsServedAndFlagged(t *testing.T) {
+	var hits atomic.Int32
+	srv := newCountingDiscoveryServer(t, &hits, http.StatusOK)
+	defer srv.Close()
+
+	cache, _ := NewFileCache(t.TempDir(), time.Hour, time.Hour)
+	writeCacheRecord(t, cache, "k", &EndpointInfo{BaseURL: "https://cached"}, 90*time.Minute)
+
+	d := NewDiscoveryClient(DiscoveryConfig{APIKey: "k", Timeout: time.Second, Cache: cache})
+	d.baseURL = srv.URL
+
+	info, stale, err := d.DiscoverEndpointsCached(context.Background())
+	if err != nil || !stale || info.BaseURL != "https://cached" {
+		t.Fatalf("expected stale cached result, got %+v stale=%v err=%v", info, stale, err)
+	}
+
+	// https://live is not reachable, so revalidation rejects it and the
+	// cached entry is left alone.
+	pool := NewEndpointPool(info, nil)
+	if err := d.RefreshPool(context.Background(), pool); err == nil {
+		t.Fatal("expected unreachable rediscovered endpoint to be rejected")
+	}
+	if got, _, _ := cache.Load("k"); got.BaseURL != "https://cached" {
+		t.Errorf("rejected refresh must not overwrite cache, got %s", got.BaseURL)
+	}
+}
+
+func TestDiscoverEndpointsCached_ExpiredFallsBackWhenOffline(t *testing.T) {
+	var hits atomic.Int32
+	srv := newCountingDiscoveryServer(t, &hits, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	cache, _ := NewFileCache(t.TempDir(), time.Hour, time.Hour)
+	writeCacheRecord(t, cache, "k", &EndpointInfo{BaseURL: "https://cached"}, 72*time.Hour)
+
+	d := NewDiscoveryClient(DiscoveryConfig{APIKey: "k", Timeout: time.Second, Cache: cache})
+	d.baseURL = srv.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	info, stale, err := d.DiscoverEndpointsCached(ctx)
+	if err != nil {
+		t.Fatalf("expected offline fallback to cached entry, got %v", err)
+	}
+	if !stale || info.BaseURL != "https://cached" {
+		t.Fatalf("unexpected result %+v stale=%v", info, stale)
+	}
+	if hits.Load() == 0 {
+		t.Error("expired entry should trigger live discovery first")
+	}
+}
+
+func TestDiscoverEndpointsCached_MissStoresResult(t *testing.T) {
+	var hits atomic.Int32
+	srv := newCountingDiscoveryServer(t, &hits, http.StatusOK)
+	defer srv.Close()
+
+	cache, _ := NewFileCache(t.TempDir(), time.Hour, time.Hour)
+	d := NewDiscoveryClient(DiscoveryConfig{APIKey: "k", Timeout: time.Second, Cache: cache})
+	d.baseURL = srv.URL
+
+	info, stale, err := d.DiscoverEndpointsCached(context.Background())
+	if err != nil || stale || info.BaseURL != "https://live" {
+		t.Fatalf("unexpected result %+v stale=%v err=%v", info, stale, err)
+	}
+	if got, _, ok := cache.Load("k"); !ok || got.BaseURL != "https://live" {
+		t.Errorf("expected live result to be cached, got %+v", got)
+	}
+	if d.IsCached(info) {
+		t.Error("a live result must not be reported as cached")
+	}
+}
+
+func TestRediscover_ReplacesCachedEntry(t *testing.T) {
+	var hits atomic.Int32
+	srv := newCountingDiscoveryServer(t, &hits, http.StatusOK)
+	defer srv.Close()
+
+	cache, _ := NewFileCache(t.TempDir(), time.Hour, time.Hour)
+	writeCacheRecord(t, cache, "k", &EndpointInfo{BaseURL: "https://cached"}, time.Minute)
+
+	d := NewDiscoveryClient(DiscoveryConfig{APIKey: "k", Timeout: time.Second, Cache: cache})
+	d.baseURL = srv.URL
+
+	cached, _, _ := d.DiscoverEndpointsCached(context.Background())
+	if !d.IsCached(cached) {
+		t.Fatal("expected the cached result to be reported as cached")
+	}
+	info, err := d.Rediscover(context.Background())
+	if err != nil || info.BaseURL != "https://live" {
+		t.Fatalf("Rediscover = %+v, %v", info, err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("expected one live discovery, got %d", hits.Load())
+	}
+	if got, _, ok := cache.Load("k"); !ok || got.BaseURL != "https://live" {
+		t.Errorf("cache holds %+v, want the rediscovered endpoints", got)
+	}
+	if d.IsCached(cached) {
+		t.Error("the old result must no longer be reported as cached")
+	}
+}
+
+func TestRediscover_FailureKeepsEntry(t *testing.T) {
+	var hits atomic.Int32
+	srv := newCountingDiscoveryServer(t, &hits, http.StatusInternalServerError)
+	defer srv.Close()
+
+	cache, _ := NewFileCache(t.TempDir(), time.Hour, time.Hour)
+	writeCacheRecord(t, cache, "k", &EndpointInfo{BaseURL: "https://cached"}, time.Minute)
+
+	d := NewDiscoveryClient(DiscoveryConfig{APIKey: "k", Timeout: time.Second, Cache: cache})
+	d.baseURL = srv.URL
+	if _, err := d.Rediscover(context.Background()); err == nil {
+		t.Fatal("expected discovery error")
+	}
+	if got, _, ok := cache.Load("k"); !ok || got.BaseURL != "https://cached" {
+		t.Errorf("cache holds %+v, want the last known-good endpoints", got)
+	}
+}
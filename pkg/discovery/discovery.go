@@ -7,9 +7,11 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/api"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/diag"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/sdkversion"
 )
 
@@ -41,6 +43,7 @@ func StaticDiscoveryURL(region string) string {
 // EndpointInfo represents information about discovered endpoints
 type EndpointInfo struct {
 	BaseURL      string            `json:"base_url"` // Base ingestor URL
+	FallbackURLs []string          `json:"fallback_urls,omitempty"`
 	Region       string            `json:"region,omitempty"`
 	Capabilities []string          `json:"capabilities,omitempty"`
 	RateLimit    *RateLimitInfo    `json:"rate_limit,omitempty"`
@@ -86,6 +89,11 @@ type DiscoveryClient struct {
 	apiKey     string
 	baseURL    string
 	timeout    time.Duration
+	cache      *FileCache
+	log        diag.Logger
+
+	cacheMu   sync.Mutex
+	fromCache *EndpointInfo // last result DiscoverEndpointsCached took from the cache
 }
 
 // DiscoveryConfig holds configuration for the discovery client
@@ -93,6 +101,8 @@ type DiscoveryConfig struct {
 	APIKey     string
 	Timeout    time.Duration
 	HTTPClient *http.Client
+	Cache      *FileCache // Optional on-disk cache of discovery results
+	Logger     diag.Logger
 }
 
 // NewDiscoveryClient creates a new endpoint discovery client
@@ -104,7 +114,10 @@ func NewDiscoveryClient(config DiscoveryConfig) *DiscoveryClient {
 		}
 	}
 	if httpClient.Timeout == 0 {
-		httpClient.Timeout = 10 * time.Second
+		// Copy rather than mutate a caller-supplied client.
+		withTimeout := *httpClient
+		withTimeout.Timeout = 10 * time.Second
+		httpClient = &withTimeout
 	}
 
 	// Use API gateway per spec as primary discovery base. We'll try regional gateways as fallbacks.
@@ -115,9 +128,17 @@ func NewDiscoveryClient(config DiscoveryConfig) *DiscoveryClient {
 		apiKey:     config.APIKey,
 		baseURL:    baseURL,
 		timeout:    httpClient.Timeout,
+		cache:      config.Cache,
+		log:        diag.OrNop(config.Logger),
 	}
 }
 
+// logger returns the diagnostic logger; clients built as struct literals
+// have none.
+func (d *DiscoveryClient) logger() diag.Logger {
+	return diag.OrNop(d.log)
+}
+
 // DiscoverEndpoints discovers LogFlux regional endpoints.
 // If the API key has a region prefix (e.g. "eu-lf_..."), it first tries the
 // static, unauthenticated discovery endpoint at discover.{region}.logflux.io.
@@ -128,9 +149,11 @@ func (d *DiscoveryClient) DiscoverEndpoints(ctx context.Context, identifier stri
 	if region != "" {
 		endpoints, err := d.tryStaticDiscovery(ctx, region)
 		if err == nil {
+			d.logger().Debug("discovery succeeded", "method", "static", "region", region, "ingestor", endpoints.BaseURL)
 			return endpoints, nil
 		}
 		// Static discovery failed, fall through to authenticated discovery
+		d.logger().Debug("static discovery failed", "region", region, "error", err)
 	}
 
 	// Authenticated discovery: try configured baseURL first (for tests/overrides),
@@ -146,11 +169,14 @@ func (d *DiscoveryClient) DiscoverEndpoints(ctx context.Context, identifier stri
 	for _, url := range discoveryURLs {
 		endpoints, err := d.tryDiscoveryURL(ctx, url)
 		if err == nil {
+			d.logger().Debug("discovery succeeded", "method", "gateway", "url", url, "ingestor", endpoints.BaseURL)
 			return endpoints, nil
 		}
+		d.logger().Debug("discovery URL failed", "url", url, "error", err)
 		lastErr = err
 	}
 
+	d.logger().Warn("discovery failed", "error", lastErr)
 	return nil, fmt.Errorf("all discovery URLs failed, last error: %w", lastErr)
 }
 
@@ -186,9 +212,10 @@ func (d *DiscoveryClient) tryStaticDiscovery(ctx context.Context, region string)
 		Version   string `json:"version"`
 		Region    string `json:"region"`
 		Endpoints struct {
-			BackendURL   string `json:"backend_url"`
-			IngestorURL  string `json:"ingestor_url"`
-			DashboardURL string `json:"dashboard_url"`
+			BackendURL           string   `json:"backend_url"`
+			IngestorURL          string   `json:"ingestor_url"`
+			FallbackIngestorURLs []string `json:"fallback_ingestor_urls"`
+			DashboardURL         string   `json:"dashboard_url"`
 		} `json:"endpoints"`
 		UpdatedAt string `json:"updated_at"`
 	}
@@ -202,8 +229,9 @@ func (d *DiscoveryClient) tryStaticDiscovery(ctx context.Context, region string)
 	}
 
 	return &EndpointInfo{
-		BaseURL: staticResp.Endpoints.IngestorURL,
-		Region:  staticResp.Region,
+		BaseURL:      staticResp.Endpoints.IngestorURL,
+		FallbackURLs: staticResp.Endpoints.FallbackIngestorURLs,
+		Region:       staticResp.Region,
 		Metadata: map[string]string{
 			"backend_url":   staticResp.Endpoints.BackendURL,
 			"dashboard_url": staticResp.Endpoints.DashboardURL,
@@ -247,11 +275,12 @@ func (d *DiscoveryClient) tryDiscoveryURL(ctx context.Context, discoveryURL stri
 
 	// Spec format
 	var specResp struct {
-		DataResidency string   `json:"data_residency"`
-		BackendURL    string   `json:"backend_url"`
-		IngestorURL   string   `json:"ingestor_url"`
-		Environment   string   `json:"environment"`
-		Features      []string `json:"features"`
+		DataResidency        string   `json:"data_residency"`
+		BackendURL           string   `json:"backend_url"`
+		IngestorURL          string   `json:"ingestor_url"`
+		FallbackIngestorURLs []string `json:"fallback_ingestor_urls"`
+		Environment          string   `json:"environment"`
+		Features             []string `json:"features"`
 	}
 	if err := json.Unmarshal(bodyBytes, &specResp); err == nil && (specResp.IngestorURL != "" || specResp.BackendURL != "") {
 		if specResp.IngestorURL == "" {
@@ -259,6 +288,7 @@ func (d *DiscoveryClient) tryDiscoveryURL(ctx context.Context, discoveryURL stri
 		}
 		return &EndpointInfo{
 			BaseURL:      specResp.IngestorURL,
+			FallbackURLs: specResp.FallbackIngestorURLs,
 			Region:       specResp.DataResidency,
 			Capabilities: specResp.Features,
 			Metadata: map[string]string{
@@ -335,6 +365,28 @@ func (d *DiscoveryClient) RefreshEndpoints(ctx context.Context, identifier strin
 	return d.DiscoverEndpoints(ctx, identifier)
 }
 
+// RefreshPool re-discovers endpoints and applies the result to pool.
+// When the discovered primary changes, the new primary is validated before it
+// is adopted so a bad discovery response cannot take the client offline.
+func (d *DiscoveryClient) RefreshPool(ctx context.Context, pool *EndpointPool) error {
+	info, err := d.RefreshEndpoints(ctx, "")
+	if err != nil {
+		return err
+	}
+	if current := pool.Primary(); current == nil || current.BaseURL != info.BaseURL {
+		if err := d.ValidateEndpoints(ctx, info); err != nil {
+			d.logger().Warn("rediscovered endpoint rejected", "ingestor", info.BaseURL, "error", err)
+			return fmt.Errorf("rediscovered endpoint rejected: %w", err)
+		}
+		d.logger().Info("primary endpoint changed", "ingestor", info.BaseURL)
+	}
+	pool.Update(info)
+	if d.cache != nil {
+		_ = d.cache.Store(d.apiKey, info)
+	}
+	return nil
+}
+
 // GetDiscoveryURL returns the base discovery URL being used
 func (d *DiscoveryClient) GetDiscoveryURL() string {
 	return d.baseURL
@@ -0,0 +1,200 @@
+package discovery
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultFailoverCooldown is how long a failed endpoint is skipped before it
+// is tried again.
+const DefaultFailoverCooldown = 30 * time.Second
+
+// EndpointPool holds an ordered list of ingest endpoints (primary first,
+// then fallbacks) and tracks their health for failover.
+//
+// Current always returns the highest-priority endpoint that is not cooling
+// down, so traffic fails back to the primary once its cooldown expires.
+type EndpointPool struct {
+	mu          sync.RWMutex
+	discovered  []*EndpointInfo
+	configured  []*EndpointInfo
+	unhealthy   map[string]time.Time // base URL -> retry-after time
+	cooldown    time.Duration
+	refreshedAt time.Time
+}
+
+// NewEndpointPool creates a pool from a discovered (or custom) primary endpoint
+// and a list of statically configured fallback base URLs.
+func NewEndpointPool(primary *EndpointInfo, fallbackURLs []string) *EndpointPool {
+	p := &EndpointPool{
+		unhealthy: make(map[string]time.Time),
+		cooldown:  DefaultFailoverCooldown,
+	}
+	for _, u := range fallbackURLs {
+		u = normalizeBaseURL(u)
+		if u != "" {
+			p.configured = append(p.configured, &EndpointInfo{BaseURL: u})
+		}
+	}
+	p.discovered = expandEndpoints(primary)
+	p.refreshedAt = time.Now()
+	return p
+}
+
+// SetCooldown sets how long a failed endpoint is skipped. Zero or negative
+// values reset it to DefaultFailoverCooldown.
+func (p *EndpointPool) SetCooldown(d time.Duration) {
+	if d <= 0 {
+		d = DefaultFailoverCooldown
+	}
+	p.mu.Lock()
+	p.cooldown = d
+	p.mu.Unlock()
+}
+
+// Current returns the endpoint that should receive traffic right now.
+// If every endpoint is cooling down, the one that recovers first is returned.
+func (p *EndpointPool) Current() *EndpointInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	now := time.Now()
+	var soonest *EndpointInfo
+	var soonestAt time.Time
+	for _, ep := range p.allLocked() {
+		until, failed := p.unhealthy[ep.BaseURL]
+		if !failed || !now.Before(until) {
+			return ep
+		}
+		if soonest == nil || until.Before(soonestAt) {
+			soonest, soonestAt = ep, until
+		}
+	}
+	return soonest
+}
+
+// All returns a copy of the endpoint list in priority order.
+func (p *EndpointPool) All() []*EndpointInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.allLocked()
+}
+
+// Len returns the number of distinct endpoints in the pool.
+func (p *EndpointPool) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.allLocked())
+}
+
+// MarkFailed puts an endpoint into cooldown. It returns the endpoint that
+// should be used next and whether that is a different, healthy endpoint.
+func (p *EndpointPool) MarkFailed(baseURL string) (*EndpointInfo, bool) {
+	p.mu.Lock()
+	p.unhealthy[baseURL] = time.Now().Add(p.cooldown)
+	p.mu.Unlock()
+
+	next := p.Current()
+	return next, next != nil && next.BaseURL != baseURL && p.IsHealthy(next.BaseURL)
+}
+
+// MarkHealthy clears any cooldown recorded for an endpoint.
+func (p *EndpointPool) MarkHealthy(baseURL string) {
+	p.mu.Lock()
+	delete(p.unhealthy, baseURL)
+	p.mu.Unlock()
+}
+
+// IsHealthy reports whether an endpoint is currently outside its cooldown.
+func (p *EndpointPool) IsHealthy(baseURL string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	until, failed := p.unhealthy[baseURL]
+	return !failed || !time.Now().Before(until)
+}
+
+// Update replaces the discovered endpoints with a fresh discovery result.
+// Configured fallbacks are kept, and health state is preserved for endpoints
+// that are still present.
+func (p *EndpointPool) Update(primary *EndpointInfo) {
+	if primary == nil || primary.BaseURL == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.discovered = expandEndpoints(primary)
+	p.refreshedAt = time.Now()
+
+	keep := make(map[string]bool)
+	for _, ep := range p.allLocked() {
+		keep[ep.BaseURL] = true
+	}
+	for u := range p.unhealthy {
+		if !keep[u] {
+			delete(p.unhealthy, u)
+		}
+	}
+}
+
+// RefreshedAt returns when the discovered endpoints were last set.
+func (p *EndpointPool) RefreshedAt() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.refreshedAt
+}
+
+// Primary returns the first discovered (or custom) endpoint.
+func (p *EndpointPool) Primary() *EndpointInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.discovered) > 0 {
+		return p.discovered[0]
+	}
+	if len(p.configured) > 0 {
+		return p.configured[0]
+	}
+	return nil
+}
+
+// allLocked returns discovered endpoints followed by configured fallbacks,
+// de-duplicated by base URL. Must be called with p.mu held.
+func (p *EndpointPool) allLocked() []*EndpointInfo {
+	seen := make(map[string]bool, len(p.discovered)+len(p.configured))
+	out := make([]*EndpointInfo, 0, len(p.discovered)+len(p.configured))
+	for _, list := range [][]*EndpointInfo{p.discovered, p.configured} {
+		for _, ep := range list {
+			if seen[ep.BaseURL] {
+				continue
+			}
+			seen[ep.BaseURL] = true
+			out = append(out, ep)
+		}
+	}
+	return out
+}
+
+// expandEndpoints turns a discovery result into the primary endpoint plus one
+// entry per advertised fallback URL.
+func expandEndpoints(primary *EndpointInfo) []*EndpointInfo {
+	if primary == nil || primary.BaseURL == "" {
+		return nil
+	}
+	out := []*EndpointInfo{primary}
+	for _, u := range primary.FallbackURLs {
+		u = normalizeBaseURL(u)
+		if u == "" || u == primary.BaseURL {
+			continue
+		}
+		out = append(out, &EndpointInfo{
+			BaseURL:      u,
+			Region:       primary.Region,
+			Capabilities: primary.Capabilities,
+		})
+	}
+	return out
+}
+
+func normalizeBaseURL(u string) string {
+	return strings.TrimRight(strings.TrimSpace(u), "/")
+}
@@ -0,0 +1,120 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEndpointPool_OrderAndDedup(t *testing.T) {
+	primary := &EndpointInfo{
+		BaseURL:      "https://a.example",
+		FallbackURLs: []string{"https://b.example/", "https://a.example"},
+	}
+	pool := NewEndpointPool(primary, []string{"https://c.example", "https://b.example"})
+
+	all := pool.All()
+	want := []string{"https://a.example", "https://b.example", "https://c.example"}
+	if len(all) != len(want) {
+		t.Fatalf("expected %d endpoints, got %d", len(want), len(all))
+	}
+	for i, ep := range all {
+		if ep.BaseURL != want[i] {
+			t.Errorf("endpoint %d: expected %s, got %s", i, want[i], ep.BaseURL)
+		}
+	}
+	if pool.Current().BaseURL != "https://a.example" {
+		t.Errorf("expected primary to be current, got %s", pool.Current().BaseURL)
+	}
+}
+
+func TestEndpointPool_FailoverAndFailback(t *testing.T) {
+	pool := NewEndpointPool(&EndpointInfo{BaseURL: "https://a.example"}, []string{"https://b.example"})
+	pool.SetCooldown(50 * time.Millisecond)
+
+	next, switched := pool.MarkFailed("https://a.example")
+	if !switched || next.BaseURL != "https://b.example" {
+		t.Fatalf("expected switch to fallback, got %v switched=%v", next, switched)
+	}
+	if pool.IsHealthy("https://a.example") {
+		t.Error("primary should be cooling down")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if pool.Current().BaseURL != "https://a.example" {
+		t.Errorf("expected fail-back to primary after cooldown, got %s", pool.Current().BaseURL)
+	}
+}
+
+func TestEndpointPool_AllFailedReturnsSoonestRecovery(t *testing.T) {
+	pool := NewEndpointPool(&EndpointInfo{BaseURL: "https://a.example"}, []string{"https://b.example"})
+	pool.MarkFailed("https://a.example")
+	next, switched := pool.MarkFailed("https://b.example")
+	if switched {
+		t.Error("expected no switch when every endpoint is down")
+	}
+	if next == nil || next.BaseURL != "https://a.example" {
+		t.Errorf("expected endpoint that failed first, got %v", next)
+	}
+}
+
+func TestEndpointPool_UpdateKeepsConfiguredFallbacks(t *testing.T) {
+	pool := NewEndpointPool(&EndpointInfo{BaseURL: "https://a.example"}, []string{"https://c.example"})
+	pool.MarkFailed("https://a.example")
+
+	pool.Update(&EndpointInfo{BaseURL: "https://b.example"})
+
+	all := pool.All()
+	if len(all) != 2 || all[0].BaseURL != "https://b.example" || all[1].BaseURL != "https://c.example" {
+		t.Fatalf("unexpected endpoints after update: %+v", all)
+	}
+	if !pool.IsHealthy("https://a.example") {
+		t.Error("health state for removed endpoint should be dropped")
+	}
+}
+
+func TestRefreshPool_ValidatesNewPrimary(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	var ingestorURL atomic.Value
+	ingestorURL.Store(healthy.URL)
+	disc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"ingestor_url":           ingestorURL.Load().(string),
+			"fallback_ingestor_urls": []string{"https://fallback.example"},
+		})
+	}))
+	defer disc.Close()
+
+	d := NewDiscoveryClient(DiscoveryConfig{APIKey: "lf_legacy", Timeout: time.Second})
+	d.baseURL = disc.URL
+
+	pool := NewEndpointPool(&EndpointInfo{BaseURL: "https://old.example"}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := d.RefreshPool(ctx, pool); err != nil {
+		t.Fatalf("RefreshPool: %v", err)
+	}
+	if pool.Current().BaseURL != healthy.URL {
+		t.Errorf("expected new primary %s, got %s", healthy.URL, pool.Current().BaseURL)
+	}
+	if pool.Len() != 2 {
+		t.Errorf("expected advertised fallback to be added, got %d endpoints", pool.Len())
+	}
+
+	// A rediscovered primary that fails validation is not adopted.
+	ingestorURL.Store("http://127.0.0.1:1")
+	if err := d.RefreshPool(ctx, pool); err == nil {
+		t.Fatal("expected unreachable primary to be rejected")
+	}
+	if pool.Current().BaseURL != healthy.URL {
+		t.Errorf("expected previous primary to be kept, got %s", pool.Current().BaseURL)
+	}
+}
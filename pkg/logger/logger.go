@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
@@ -91,11 +92,13 @@ func (l *Logger) Debugf(format string, args ...interface{}) error {
 	return l.Debug(fmt.Sprintf(format, args...))
 }
 
-// Fatal sends a fatal level log message and exits with status 1.
+// Fatal sends a fatal level log message and exits with status 1 through
+// client.Exit. The client sends synchronously, so the message is delivered
+// (or has failed) before the process ends.
 func (l *Logger) Fatal(message string) error {
 	err := l.client.Fatal(l.formatMessage(message))
-	os.Exit(1)
-	return err // unreachable, kept for interface compliance
+	client.Exit(1)
+	return err // reached only if the exit function returns
 }
 
 // Fatalf sends a formatted fatal level log message and exits with status 1.
@@ -122,9 +125,11 @@ func (l *Logger) Close() error {
 // AsyncLogger provides asynchronous logging capabilities.
 // Close() must be called to stop the background worker and flush remaining entries.
 type AsyncLogger struct {
-	logger  *Logger
-	logChan chan logEntry
-	done    chan struct{}
+	logger   *Logger
+	logChan  chan logEntry
+	done     chan struct{}
+	stopped  chan struct{} // closed when the worker has drained and exited
+	stopOnce sync.Once
 }
 
 type logEntry struct {
@@ -139,6 +144,7 @@ func NewAsyncLogger(logger *Logger, bufferSize int) *AsyncLogger {
 		logger:  logger,
 		logChan: make(chan logEntry, bufferSize),
 		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
 	}
 
 	go al.worker()
@@ -147,6 +153,7 @@ func NewAsyncLogger(logger *Logger, bufferSize int) *AsyncLogger {
 
 // worker processes log entries asynchronously
 func (al *AsyncLogger) worker() {
+	defer close(al.stopped)
 	for {
 		select {
 		case entry := <-al.logChan:
@@ -237,23 +244,38 @@ func (al *AsyncLogger) Debugf(format string, args ...interface{}) {
 	al.Debug(fmt.Sprintf(format, args...))
 }
 
-// Fatal sends a fatal level log message asynchronously and exits.
+// Fatal sends the buffered messages, waiting up to client.FatalFlushTimeout,
+// then sends a fatal level log message synchronously and exits with status 1.
 func (al *AsyncLogger) Fatal(message string) {
-	// Fatal is synchronous — must send before exit
+	al.stop(client.FatalFlushTimeout)
 	_ = al.logger.Fatal(message)
 }
 
-// Fatalf sends a formatted fatal level log message asynchronously and exits.
+// Fatalf is like Fatal with a formatted message.
 func (al *AsyncLogger) Fatalf(format string, args ...interface{}) {
 	al.Fatal(fmt.Sprintf(format, args...))
 }
 
 // Close closes the async logger and waits for all messages to be sent.
 func (al *AsyncLogger) Close() error {
-	close(al.done)
+	al.stop(0)
 	return al.logger.Close()
 }
 
+// stop stops the worker and waits for it to drain the buffer, at most
+// timeout if it is positive.
+func (al *AsyncLogger) stop(timeout time.Duration) {
+	al.stopOnce.Do(func() { close(al.done) })
+	if timeout <= 0 {
+		<-al.stopped
+		return
+	}
+	select {
+	case <-al.stopped:
+	case <-time.After(timeout):
+	}
+}
+
 // SetupGlobalLogger sets up a global logger that can be used throughout the application
 func SetupGlobalLogger(node, prefix string) error {
 	logger, err := NewLoggerFromEnv(node, prefix)
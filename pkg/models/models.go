@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Log level constants (syslog severity 1-8)
 const (
@@ -14,6 +19,33 @@ const (
 	LogLevelDebug     = 8
 )
 
+// ParseLogLevel parses a level name (debug, info, notice, warn or warning,
+// error, critical, alert, emergency; case-insensitive) or a number 1-8.
+func ParseLogLevel(s string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "emergency", "emerg":
+		return LogLevelEmergency, nil
+	case "alert":
+		return LogLevelAlert, nil
+	case "critical", "crit", "fatal":
+		return LogLevelCritical, nil
+	case "error", "err":
+		return LogLevelError, nil
+	case "warning", "warn":
+		return LogLevelWarning, nil
+	case "notice":
+		return LogLevelNotice, nil
+	case "info":
+		return LogLevelInfo, nil
+	case "debug":
+		return LogLevelDebug, nil
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil && n >= LogLevelEmergency && n <= LogLevelDebug {
+		return n, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q", s)
+}
+
 // Entry type constants
 const (
 	EntryTypeLog              = 1
@@ -52,6 +84,29 @@ func EntryTypeCategory(entryType int) string {
 	}
 }
 
+// EntryTypeName returns the payload type name of an entry type, e.g.
+// "metric" for EntryTypeMetric.
+func EntryTypeName(entryType int) string {
+	switch entryType {
+	case EntryTypeLog:
+		return "log"
+	case EntryTypeMetric:
+		return "metric"
+	case EntryTypeTrace:
+		return "trace"
+	case EntryTypeEvent:
+		return "event"
+	case EntryTypeAudit:
+		return "audit"
+	case EntryTypeTelemetry:
+		return "telemetry"
+	case EntryTypeTelemetryManaged:
+		return "telemetry_managed"
+	default:
+		return "type_" + strconv.Itoa(entryType)
+	}
+}
+
 // EntryTypeRequiresEncryption returns true if the entry type needs E2E encryption.
 func EntryTypeRequiresEncryption(entryType int) bool {
 	return entryType >= 1 && entryType <= 6
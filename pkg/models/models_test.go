@@ -117,6 +117,15 @@ func TestEntryTypeRequiresEncryption(t *testing.T) {
 	}
 }
 
+func TestEntryTypeName(t *testing.T) {
+	if EntryTypeName(EntryTypeMetric) != "metric" || EntryTypeName(EntryTypeTelemetryManaged) != "telemetry_managed" {
+		t.Fatalf("unexpected entry type names")
+	}
+	if EntryTypeName(42) != "type_42" {
+		t.Fatalf("unknown type name = %q", EntryTypeName(42))
+	}
+}
+
 func TestDefaultPayloadType(t *testing.T) {
 	if DefaultPayloadType(EntryTypeLog) != PayloadTypeAES256GCMGzipJSON {
 		t.Fatalf("default for log should be AES256GCMGzipJSON")
@@ -125,3 +134,29 @@ func TestDefaultPayloadType(t *testing.T) {
 		t.Fatalf("default for TelemetryManaged should be GzipJSON")
 	}
 }
+
+func TestParseLogLevel(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int
+	}{
+		{"debug", LogLevelDebug},
+		{"INFO", LogLevelInfo},
+		{" warn ", LogLevelWarning},
+		{"warning", LogLevelWarning},
+		{"error", LogLevelError},
+		{"fatal", LogLevelCritical},
+		{"1", LogLevelEmergency},
+		{"8", LogLevelDebug},
+	}
+	for _, c := range cases {
+		if got, err := ParseLogLevel(c.in); err != nil || got != c.want {
+			t.Fatalf("ParseLogLevel(%q) = %d, %v; want %d", c.in, got, err, c.want)
+		}
+	}
+	for _, in := range []string{"", "verbose", "0", "9"} {
+		if _, err := ParseLogLevel(in); err == nil {
+			t.Fatalf("ParseLogLevel(%q): expected error", in)
+		}
+	}
+}
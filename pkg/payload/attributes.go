@@ -0,0 +1,277 @@
+package payload
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Attributes holds typed attribute values keyed by name. Values are stored
+// as one of the v2 schema types so they serialize to native JSON and can be
+// aggregated and range-queried server-side:
+//
+//	string, int64, float64, bool, []string, []int64, []float64
+//
+// Use Set (or NewAttributes) to add arbitrary Go values; they are converted
+// with AttributeValue. Nested maps are flattened under dotted keys.
+type Attributes map[string]any
+
+// maxAttributeDepth bounds how deeply nested maps are flattened. Deeper
+// values are stored as their string form.
+const maxAttributeDepth = 8
+
+// NewAttributes returns Attributes holding kv, which alternates keys and
+// values: NewAttributes("status", 200, "cached", true).
+// A trailing key without a value is ignored.
+func NewAttributes(kv ...any) Attributes {
+	a := make(Attributes, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		a.Set(fmt.Sprint(kv[i]), kv[i+1])
+	}
+	return a
+}
+
+// AttributesFromStrings converts string-only attributes (such as Fields)
+// to Attributes. It returns nil for an empty map.
+func AttributesFromStrings(m map[string]string) Attributes {
+	if len(m) == 0 {
+		return nil
+	}
+	a := make(Attributes, len(m))
+	for k, v := range m {
+		a[k] = v
+	}
+	return a
+}
+
+// Set stores value under key, converting it with AttributeValue. Maps are
+// flattened: Set("http", map[string]any{"status": 200}) stores "http.status".
+// A nil value removes key.
+func (a Attributes) Set(key string, value any) {
+	a.set(key, value, 0)
+}
+
+func (a Attributes) set(key string, value any, depth int) {
+	if value == nil {
+		delete(a, key)
+		return
+	}
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			delete(a, key)
+			return
+		}
+		if isStringer(rv.Interface()) {
+			break
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String && !isStringer(rv.Interface()) {
+		if depth >= maxAttributeDepth {
+			a[key] = fmt.Sprint(rv.Interface())
+			return
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			a.set(key+"."+iter.Key().String(), iter.Value().Interface(), depth+1)
+		}
+		return
+	}
+	a[key] = AttributeValue(rv.Interface())
+}
+
+// Merge copies every value of other into a, replacing existing keys.
+func (a Attributes) Merge(other Attributes) {
+	for k, v := range other {
+		a[k] = v
+	}
+}
+
+// Clone returns a copy of a. Slice values are shared; they are never
+// modified after being stored.
+func (a Attributes) Clone() Attributes {
+	if a == nil {
+		return nil
+	}
+	c := make(Attributes, len(a))
+	for k, v := range a {
+		c[k] = v
+	}
+	return c
+}
+
+// Strings returns the attributes with every value in string form, for
+// consumers that only accept string maps (e.g. breadcrumb data).
+func (a Attributes) Strings() map[string]string {
+	if len(a) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(a))
+	for k, v := range a {
+		m[k] = attributeString(v)
+	}
+	return m
+}
+
+// AttributeValue converts v to a v2 attribute type:
+//
+//   - signed and unsigned integers become int64 (uint64 values above
+//     math.MaxInt64 become strings)
+//   - float32 becomes float64; NaN and ±Inf become strings
+//   - time.Duration becomes float64 milliseconds
+//   - time.Time becomes an RFC 3339 string
+//   - errors and fmt.Stringers become their string form
+//   - slices of strings, integers or floats become []string, []int64 or
+//     []float64; other slices become []string
+//   - anything else becomes its fmt.Sprint form
+func AttributeValue(v any) any {
+	switch v := v.(type) {
+	case string, bool, int64:
+		return v
+	case float64:
+		return finiteFloat(v)
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float32:
+		return finiteFloat(float64(v))
+	case time.Duration:
+		return float64(v) / float64(time.Millisecond)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case []string:
+		return append([]string(nil), v...)
+	case []int64:
+		return append([]int64(nil), v...)
+	case []float64:
+		out := make([]float64, len(v))
+		copy(out, v)
+		for _, f := range out {
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return sliceStrings(reflect.ValueOf(v))
+			}
+		}
+		return out
+	case nil:
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return int64(u)
+		}
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return finiteFloat(rv.Float())
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.String:
+		return rv.String()
+	case reflect.Slice, reflect.Array:
+		return sliceValue(rv)
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return AttributeValue(rv.Elem().Interface())
+	}
+	return fmt.Sprint(v)
+}
+
+// sliceValue converts a slice to []string, []int64 or []float64 depending on
+// its elements. Integers mixed with floats become []float64.
+func sliceValue(rv reflect.Value) any {
+	n := rv.Len()
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		return string(rv.Bytes()) // []byte is text, not a number list
+	}
+	elems := make([]any, n)
+	allInt, allNum := true, true
+	for i := 0; i < n; i++ {
+		e := AttributeValue(rv.Index(i).Interface())
+		elems[i] = e
+		switch e.(type) {
+		case int64:
+		case float64:
+			allInt = false
+		default:
+			allInt, allNum = false, false
+		}
+	}
+	switch {
+	case n == 0:
+		return []string{}
+	case allInt:
+		out := make([]int64, n)
+		for i, e := range elems {
+			out[i] = e.(int64)
+		}
+		return out
+	case allNum:
+		out := make([]float64, n)
+		for i, e := range elems {
+			if x, ok := e.(int64); ok {
+				out[i] = float64(x)
+			} else {
+				out[i] = e.(float64)
+			}
+		}
+		return out
+	}
+	out := make([]string, n)
+	for i, e := range elems {
+		out[i] = attributeString(e)
+	}
+	return out
+}
+
+func sliceStrings(rv reflect.Value) []string {
+	out := make([]string, rv.Len())
+	for i := range out {
+		out[i] = attributeString(AttributeValue(rv.Index(i).Interface()))
+	}
+	return out
+}
+
+// finiteFloat returns f, or its string form if JSON cannot represent it.
+func finiteFloat(f float64) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return f
+}
+
+// attributeString renders an attribute value as text.
+func attributeString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
+
+func isStringer(v any) bool {
+	switch v.(type) {
+	case fmt.Stringer, error:
+		return true
+	}
+	return false
+}
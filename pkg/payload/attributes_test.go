@@ -0,0 +1,131 @@
+package payload
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testStringer struct{ s string }
+
+func (t testStringer) String() string { return t.s }
+
+func TestAttributeValue(t *testing.T) {
+	n := 7
+	cases := []struct {
+		name string
+		in   any
+		want any
+	}{
+		{"string", "x", "x"},
+		{"int", 42, int64(42)},
+		{"int8", int8(-3), int64(-3)},
+		{"uint16", uint16(9), int64(9)},
+		{"huge uint64", uint64(math.MaxUint64), "18446744073709551615"},
+		{"float32", float32(1.5), 1.5},
+		{"NaN", math.NaN(), "NaN"},
+		{"Inf", math.Inf(1), "+Inf"},
+		{"bool", true, true},
+		{"duration", 1500 * time.Microsecond, 1.5},
+		{"time", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "2024-01-02T03:04:05Z"},
+		{"error", errors.New("boom"), "boom"},
+		{"stringer", testStringer{"s"}, "s"},
+		{"pointer", &n, int64(7)},
+		{"strings", []string{"a", "b"}, []string{"a", "b"}},
+		{"ints", []int{1, 2}, []int64{1, 2}},
+		{"mixed numbers", []any{1, 2.5}, []float64{1, 2.5}},
+		{"mixed", []any{"a", 1, true}, []string{"a", "1", "true"}},
+		{"bytes", []byte("raw"), "raw"},
+		{"struct", struct{ A int }{1}, "{1}"},
+	}
+	for _, c := range cases {
+		if got := AttributeValue(c.in); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: AttributeValue(%v) = %#v, want %#v", c.name, c.in, got, c.want)
+		}
+	}
+}
+
+func TestAttributes_SetFlattensMaps(t *testing.T) {
+	a := NewAttributes(
+		"http", map[string]any{
+			"status": 200,
+			"req":    map[string]string{"method": "GET"},
+		},
+		"cached", false,
+	)
+	want := Attributes{"http.status": int64(200), "http.req.method": "GET", "cached": false}
+	if !reflect.DeepEqual(a, want) {
+		t.Fatalf("got %#v, want %#v", a, want)
+	}
+
+	a.Set("cached", nil)
+	if _, ok := a["cached"]; ok {
+		t.Error("Set(key, nil) should remove the key")
+	}
+}
+
+func TestAttributes_SetDepthLimit(t *testing.T) {
+	nested := map[string]any{"leaf": 1}
+	for i := 0; i < maxAttributeDepth+2; i++ {
+		nested = map[string]any{"n": nested}
+	}
+	a := make(Attributes)
+	a.Set("root", nested)
+	if len(a) != 1 {
+		t.Fatalf("expected one truncated key, got %v", a)
+	}
+	for k, v := range a {
+		if _, ok := v.(string); !ok {
+			t.Errorf("%s: value beyond max depth should be a string, got %T", k, v)
+		}
+	}
+}
+
+func TestAttributes_JSONTypes(t *testing.T) {
+	p := NewLog("api", "request", 7)
+	p.SetAttr("status", 200)
+	p.SetAttr("latency", 12*time.Millisecond)
+	p.SetAttr("cached", true)
+	p.SetAttr("tags", []string{"a", "b"})
+
+	data, err := Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m struct {
+		Attributes map[string]any `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"status": 200.0, "latency": 12.0, "cached": true, "tags": []any{"a", "b"}}
+	if !reflect.DeepEqual(m.Attributes, want) {
+		t.Errorf("attributes = %#v, want %#v", m.Attributes, want)
+	}
+}
+
+func TestAttributes_Strings(t *testing.T) {
+	a := Attributes{"n": int64(3), "f": 0.5, "b": true, "s": "x"}
+	want := map[string]string{"n": "3", "f": "0.5", "b": "true", "s": "x"}
+	if got := a.Strings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Strings() = %v, want %v", got, want)
+	}
+	if AttributesFromStrings(nil) != nil {
+		t.Error("AttributesFromStrings(nil) should be nil")
+	}
+}
+
+func TestPayload_GetAttributesAndGetAttrs(t *testing.T) {
+	p := NewLog("svc", "hello", 6)
+	p.SetAttr("n", 3)
+	p.SetAttr("s", "x")
+	if got, want := p.GetAttributes(), map[string]string{"n": "3", "s": "x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAttributes() = %v, want %v", got, want)
+	}
+	if got, want := p.GetAttrs(), (Attributes{"n": int64(3), "s": "x"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAttrs() = %#v, want %#v", got, want)
+	}
+}
@@ -13,56 +13,86 @@ type GlobalContext struct {
 
 var globalCtx = &GlobalContext{}
 
+// NewContext returns an empty context, for clients that must not share the
+// process-wide one (see logflux.NewHub).
+func NewContext() *GlobalContext {
+	return &GlobalContext{}
+}
+
+// DefaultContext returns the process-wide context used by Configure and
+// ApplyContext.
+func DefaultContext() *GlobalContext {
+	return globalCtx
+}
+
 // Configure sets the global context. Called by logflux.Init().
 func Configure(source, environment, release string) {
-	globalCtx.mu.Lock()
-	defer globalCtx.mu.Unlock()
-	globalCtx.source = source
-	globalCtx.environment = environment
-	globalCtx.release = release
-	globalCtx.defaultMeta = nil
+	globalCtx.Configure(source, environment, release)
+}
+
+// Configure sets the source, environment and release attached by Apply.
+func (g *GlobalContext) Configure(source, environment, release string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.source = source
+	g.environment = environment
+	g.release = release
+	g.defaultMeta = nil
 	if environment != "" || release != "" {
-		globalCtx.defaultMeta = make(map[string]string)
+		g.defaultMeta = make(map[string]string)
 		if environment != "" {
-			globalCtx.defaultMeta["environment"] = environment
+			g.defaultMeta["environment"] = environment
 		}
 		if release != "" {
-			globalCtx.defaultMeta["release"] = release
+			g.defaultMeta["release"] = release
 		}
 	}
 }
 
 // GetSource returns the configured source (for use in payload constructors).
 func GetSource() string {
-	globalCtx.mu.RLock()
-	defer globalCtx.mu.RUnlock()
-	return globalCtx.source
+	return globalCtx.Source()
 }
 
-// ApplyContext fills in source and meta from global context if not already set.
-func ApplyContext(c interface {
+// Source returns the configured source.
+func (g *GlobalContext) Source() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.source
+}
+
+// contextTarget is implemented by every payload type.
+type contextTarget interface {
 	SetSource(string)
 	SetMeta(map[string]string)
 	getSource() string
 	getMeta() map[string]string
-}) {
-	globalCtx.mu.RLock()
-	defer globalCtx.mu.RUnlock()
+}
+
+// ApplyContext fills in source and meta from global context if not already set.
+func ApplyContext(c contextTarget) {
+	globalCtx.Apply(c)
+}
+
+// Apply fills in source and meta from g if not already set on c.
+func (g *GlobalContext) Apply(c contextTarget) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 
-	if c.getSource() == "" && globalCtx.source != "" {
-		c.SetSource(globalCtx.source)
+	if c.getSource() == "" && g.source != "" {
+		c.SetSource(g.source)
 	}
-	if globalCtx.defaultMeta != nil {
+	if g.defaultMeta != nil {
 		existing := c.getMeta()
 		if existing == nil {
-			merged := make(map[string]string, len(globalCtx.defaultMeta))
-			for k, v := range globalCtx.defaultMeta {
+			merged := make(map[string]string, len(g.defaultMeta))
+			for k, v := range g.defaultMeta {
 				merged[k] = v
 			}
 			c.SetMeta(merged)
 		} else {
 			// Don't overwrite user-set meta keys
-			for k, v := range globalCtx.defaultMeta {
+			for k, v := range g.defaultMeta {
 				if _, exists := existing[k]; !exists {
 					existing[k] = v
 				}
@@ -1,8 +1,8 @@
 package payload
 
 import (
-	"errors"
 	"fmt"
+	"reflect"
 	"runtime"
 	"strings"
 )
@@ -13,51 +13,95 @@ type ErrorPayload struct {
 	Message       string        `json:"message"`
 	Logger        string        `json:"logger,omitempty"`
 	ErrorType     string        `json:"error_type,omitempty"`
+	Fingerprint   string        `json:"fingerprint,omitempty"`
+	GoroutineID   uint64        `json:"goroutine_id,omitempty"`
+	Occurrences   int           `json:"occurrences,omitempty"` // collapsed repeats it stands for; 0 if sent as it happened
 	ErrorChain    []ChainedError `json:"error_chain,omitempty"`
 	StackTrace    []StackFrame  `json:"stack_trace,omitempty"`
 	Breadcrumbs   []Breadcrumb  `json:"breadcrumbs,omitempty"`
 }
 
-// ChainedError represents one error in an unwrapped chain.
+// ChainedError represents one error in an unwrapped chain. Errors joined
+// with errors.Join or several %w verbs form a tree, which is listed
+// depth-first; Depth is the distance from the captured error.
 type ChainedError struct {
 	Type    string `json:"type"`
 	Message string `json:"message"`
+	Depth   int    `json:"depth,omitempty"`
 }
 
-// StackFrame represents a single frame in a stack trace.
+// StackFrame represents a single frame in a stack trace. File is trimmed to
+// an import-path-qualified name (see StackOptions for in-app detection and
+// source context).
 type StackFrame struct {
-	Function string `json:"function"`
-	File     string `json:"file"`
-	Line     int    `json:"line"`
+	Function    string   `json:"function"`
+	File        string   `json:"file"`
+	Line        int      `json:"line"`
+	InApp       bool     `json:"in_app,omitempty"`
+	PreContext  []string `json:"pre_context,omitempty"`
+	ContextLine string   `json:"context_line,omitempty"`
+	PostContext []string `json:"post_context,omitempty"`
 }
 
 // NewErrorPayload creates an error payload from a Go error with auto stack trace
 // and error chain unwrapping (follows errors.Unwrap up to 10 levels).
+// If an error in the chain recorded the stack where it was created (see
+// ErrorStack), that stack is used instead of the caller's.
 func NewErrorPayload(source string, err error) *ErrorPayload {
+	return newErrorPayload(source, err, getStackOptions())
+}
+
+// NewErrorPayload is the package-level NewErrorPayload with o's stack
+// capture settings.
+func (o *StackOptions) NewErrorPayload(source string, err error) *ErrorPayload {
+	return newErrorPayload(source, err, o.resolve())
+}
+
+func newErrorPayload(source string, err error, opts *StackOptions) *ErrorPayload {
 	p := &ErrorPayload{
-		common:    newCommon("log", source, 4), // error level
-		Message:   err.Error(),
-		ErrorType: errorTypeName(err),
+		common:      newCommon("log", source, 4), // error level
+		Message:     err.Error(),
+		ErrorType:   errorTypeName(err),
+		GoroutineID: GoroutineID(),
 	}
 	p.ErrorChain = unwrapErrorChain(err)
-	p.StackTrace = captureStackTrace(3) // skip captureStackTrace, NewErrorPayload, caller
+	p.StackTrace = errorStack(err, opts)
+	if p.StackTrace == nil {
+		p.StackTrace = captureStackTrace(2, opts) // skip newErrorPayload, NewErrorPayload
+	}
+	p.Fingerprint = ErrorFingerprint(err, p.ErrorType, p.StackTrace)
 	return p
 }
 
 // NewErrorPayloadWithMessage creates an error payload with a custom message.
 func NewErrorPayloadWithMessage(source string, err error, message string) *ErrorPayload {
+	return newErrorPayloadWithMessage(source, err, message, getStackOptions())
+}
+
+// NewErrorPayloadWithMessage is the package-level NewErrorPayloadWithMessage
+// with o's stack capture settings.
+func (o *StackOptions) NewErrorPayloadWithMessage(source string, err error, message string) *ErrorPayload {
+	return newErrorPayloadWithMessage(source, err, message, o.resolve())
+}
+
+func newErrorPayloadWithMessage(source string, err error, message string, opts *StackOptions) *ErrorPayload {
 	p := &ErrorPayload{
-		common:    newCommon("log", source, 4),
-		Message:   message,
-		ErrorType: errorTypeName(err),
+		common:      newCommon("log", source, 4),
+		Message:     message,
+		ErrorType:   errorTypeName(err),
+		GoroutineID: GoroutineID(),
 	}
 	if err != nil {
 		if p.Attributes == nil {
-			p.Attributes = make(map[string]string)
+			p.Attributes = make(Attributes)
 		}
 		p.Attributes["error"] = err.Error()
 	}
-	p.StackTrace = captureStackTrace(3)
+	p.StackTrace = errorStack(err, opts)
+	if p.StackTrace == nil {
+		p.StackTrace = captureStackTrace(2, opts)
+	}
+	p.Fingerprint = ErrorFingerprint(err, p.ErrorType, p.StackTrace)
 	return p
 }
 
@@ -69,16 +113,82 @@ func (p *ErrorPayload) WithBreadcrumbs(ring *BreadcrumbRing) *ErrorPayload {
 	return p
 }
 
-// captureStackTrace captures the current goroutine's stack trace.
-func captureStackTrace(skip int) []StackFrame {
-	var frames []StackFrame
-	pcs := make([]uintptr, 32)
-	n := runtime.Callers(skip, pcs)
-	if n == 0 {
+// captureStackTrace captures the current goroutine's stack trace. skip 0
+// starts at the caller of captureStackTrace.
+func captureStackTrace(skip int, opts *StackOptions) []StackFrame {
+	return framesFromPCs(callerPCs(skip+1, opts.MaxFrames), opts)
+}
+
+// stackTracer is implemented by errors that record the program counters of
+// the stack where they were created (see logflux.NewErrorf).
+type stackTracer interface {
+	StackTrace() []uintptr
+}
+
+// ErrorStack returns the stack recorded when err, or the innermost error in
+// its chain that has one, was created. It recognizes a StackTrace method
+// returning a slice of program counters, which covers github.com/pkg/errors
+// and similar packages. It returns nil if no error carries a stack.
+func ErrorStack(err error) []StackFrame {
+	return errorStack(err, getStackOptions())
+}
+
+func errorStack(err error, opts *StackOptions) []StackFrame {
+	var pcs []uintptr
+	for i := 0; i < 10 && err != nil; i++ {
+		if s := errorPCs(err); len(s) > 0 {
+			pcs = s // keep going: the innermost stack is closest to the cause
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			if errs := u.Unwrap(); len(errs) > 0 {
+				err = errs[0]
+			} else {
+				err = nil
+			}
+		default:
+			err = nil
+		}
+	}
+	if pcs == nil {
 		return nil
 	}
+	return framesFromPCs(pcs, opts)
+}
 
-	runtimeFrames := runtime.CallersFrames(pcs[:n])
+// errorPCs returns the program counters recorded by err itself, if any.
+func errorPCs(err error) []uintptr {
+	if st, ok := err.(stackTracer); ok {
+		return st.StackTrace()
+	}
+	// pkg/errors-style: StackTrace() returns a named slice of uintptr-based
+	// frames, which cannot be asserted without importing the package.
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	out := m.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	v := m.Call(nil)[0]
+	pcs := make([]uintptr, v.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(v.Index(i).Uint())
+	}
+	return pcs
+}
+
+// framesFromPCs resolves program counters from runtime.Callers to frames,
+// skipping runtime internals and keeping at most StackOptions.MaxFrames.
+func framesFromPCs(pcs []uintptr, opts *StackOptions) []StackFrame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	var frames []StackFrame
+	runtimeFrames := runtime.CallersFrames(pcs)
 	for {
 		frame, more := runtimeFrames.Next()
 		// Skip runtime internals
@@ -88,33 +198,45 @@ func captureStackTrace(skip int) []StackFrame {
 			}
 			continue
 		}
-		frames = append(frames, StackFrame{
-			Function: frame.Function,
-			File:     frame.File,
-			Line:     frame.Line,
-		})
-		if !more || len(frames) >= 20 {
+		frames = append(frames, newStackFrame(frame, opts))
+		if !more || len(frames) >= opts.MaxFrames {
 			break
 		}
 	}
 	return frames
 }
 
-// unwrapErrorChain follows errors.Unwrap() up to 10 levels deep.
+// maxChainErrors bounds the number of entries in an error chain.
+const maxChainErrors = 50
+
+// unwrapErrorChain walks the error tree depth-first, following both
+// Unwrap() error and Unwrap() []error, up to 10 levels deep.
 // Only populated if the chain has more than one error.
 func unwrapErrorChain(err error) []ChainedError {
 	if err == nil {
 		return nil
 	}
 	var chain []ChainedError
-	current := err
-	for i := 0; i < 10 && current != nil; i++ {
+	var walk func(e error, depth int)
+	walk = func(e error, depth int) {
+		if e == nil || depth >= 10 || len(chain) >= maxChainErrors {
+			return
+		}
 		chain = append(chain, ChainedError{
-			Type:    errorTypeName(current),
-			Message: current.Error(),
+			Type:    errorTypeName(e),
+			Message: e.Error(),
+			Depth:   depth,
 		})
-		current = errors.Unwrap(current)
+		switch u := e.(type) {
+		case interface{ Unwrap() error }:
+			walk(u.Unwrap(), depth+1)
+		case interface{ Unwrap() []error }:
+			for _, child := range u.Unwrap() {
+				walk(child, depth+1)
+			}
+		}
 	}
+	walk(err, 0)
 	// Only include chain if there's more than one error (otherwise redundant with top-level fields)
 	if len(chain) <= 1 {
 		return nil
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -172,3 +174,64 @@ func TestErrorChain_Serialization(t *testing.T) {
 		t.Errorf("expected 2-item chain in JSON, got %d", len(chain))
 	}
 }
+
+func TestUnwrapErrorChain_Tree(t *testing.T) {
+	a, b, c := errors.New("a"), errors.New("b"), errors.New("c")
+	err := fmt.Errorf("top: %w", errors.Join(a, fmt.Errorf("mid: %w and %w", b, c)))
+
+	chain := unwrapErrorChain(err)
+	want := []struct {
+		msg   string
+		depth int
+	}{
+		{err.Error(), 0},
+		{"a\nmid: b and c", 1},
+		{"a", 2},
+		{"mid: b and c", 2},
+		{"b", 3},
+		{"c", 3},
+	}
+	if len(chain) != len(want) {
+		t.Fatalf("chain has %d entries, want %d: %#v", len(chain), len(want), chain)
+	}
+	for i, w := range want {
+		if chain[i].Message != w.msg || chain[i].Depth != w.depth {
+			t.Errorf("entry %d = %q depth %d, want %q depth %d", i, chain[i].Message, chain[i].Depth, w.msg, w.depth)
+		}
+	}
+}
+
+// pkgFrame and pkgStack mimic github.com/pkg/errors' Frame and StackTrace.
+type pkgFrame uintptr
+type pkgStack []pkgFrame
+
+type pkgError struct {
+	msg   string
+	stack pkgStack
+}
+
+func (e *pkgError) Error() string        { return e.msg }
+func (e *pkgError) StackTrace() pkgStack { return e.stack }
+
+func newPkgError(msg string) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	stack := make(pkgStack, n)
+	for i, pc := range pcs[:n] {
+		stack[i] = pkgFrame(pc)
+	}
+	return &pkgError{msg: msg, stack: stack}
+}
+
+func createdHere() error { return newPkgError("boom") }
+
+func TestNewErrorPayload_UsesCreationStack(t *testing.T) {
+	err := fmt.Errorf("handler: %w", createdHere())
+	p := NewErrorPayload("svc", err)
+	if len(p.StackTrace) == 0 || !strings.HasSuffix(p.StackTrace[0].Function, ".createdHere") {
+		t.Fatalf("first frame = %+v, want createdHere", p.StackTrace)
+	}
+	if ErrorStack(errors.New("plain")) != nil {
+		t.Error("errors without a recorded stack should return nil")
+	}
+}
@@ -0,0 +1,87 @@
+package payload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Fingerprinter is implemented by errors that choose their own grouping
+// fingerprint. Errors anywhere in the wrapped chain are consulted.
+type Fingerprinter interface {
+	Fingerprint() string
+}
+
+// sdkModule is the import path prefix of this SDK and its integrations.
+const sdkModule = "github.com/logflux-io/logflux-go-sdk/"
+
+// fingerprintFrames bounds how many frames contribute to a fingerprint.
+const fingerprintFrames = 10
+
+var (
+	closureSuffix = regexp.MustCompile(`(\.func\d+|\.gowrap\d+|\.\d+)+$`)
+	digitRuns     = regexp.MustCompile(`\d+`)
+)
+
+// ErrorFingerprint returns the grouping fingerprint for err: the value of a
+// Fingerprinter in its chain if there is one, otherwise ComputeFingerprint
+// of the error type and stack.
+func ErrorFingerprint(err error, errType string, frames []StackFrame) string {
+	var fp Fingerprinter
+	if err != nil && errors.As(err, &fp) {
+		if s := fp.Fingerprint(); s != "" {
+			return s
+		}
+	}
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	}
+	return ComputeFingerprint(errType, msg, frames)
+}
+
+// ComputeFingerprint hashes the error type with the normalized function
+// names of the application's own stack frames. Standard library, runtime
+// and SDK frames are ignored, as are line numbers and closure suffixes, so
+// the fingerprint survives unrelated edits and different call sites inside
+// the SDK. If no application frames remain, all frames are used; if there
+// are no frames at all, the message with digits normalized stands in.
+func ComputeFingerprint(errType, message string, frames []StackFrame) string {
+	h := sha256.New()
+	h.Write([]byte(errType))
+	n := 0
+	for _, f := range frames {
+		if n < fingerprintFrames && (f.InApp || isAppFunction(f.Function)) {
+			h.Write([]byte{'\n'})
+			h.Write([]byte(normalizeFunction(f.Function)))
+			n++
+		}
+	}
+	if n == 0 {
+		for _, f := range frames {
+			if n < fingerprintFrames && !strings.HasPrefix(f.Function, "runtime.") {
+				h.Write([]byte{'\n'})
+				h.Write([]byte(normalizeFunction(f.Function)))
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		h.Write([]byte{'\n'})
+		h.Write([]byte(digitRuns.ReplaceAllString(message, "0")))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// normalizeFunction strips closure and generic instantiation details that
+// vary between builds.
+func normalizeFunction(fn string) string {
+	if i := strings.IndexByte(fn, '['); i >= 0 {
+		if j := strings.LastIndexByte(fn, ']'); j > i {
+			fn = fn[:i] + fn[j+1:]
+		}
+	}
+	return closureSuffix.ReplaceAllString(fn, "")
+}
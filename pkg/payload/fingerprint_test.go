@@ -0,0 +1,76 @@
+package payload
+
+import (
+	"fmt"
+	"testing"
+)
+
+type fingerprintedError struct{}
+
+func (fingerprintedError) Error() string       { return "custom" }
+func (fingerprintedError) Fingerprint() string { return "db-timeout" }
+
+type fingerprintlessError struct{}
+
+func (fingerprintlessError) Error() string { return "user 1 not found" }
+
+func captureAt(err error) *ErrorPayload { return NewErrorPayload("", err) }
+
+func TestErrorFingerprint_StableAcrossMessages(t *testing.T) {
+	var fps []string
+	for i := 0; i < 3; i++ {
+		// Same type, same call site, different message.
+		fps = append(fps, captureAt(fmt.Errorf("user %d not found", i)).Fingerprint)
+	}
+	if fps[0] == "" || fps[0] != fps[1] || fps[1] != fps[2] {
+		t.Errorf("fingerprints differ for repeats: %v", fps)
+	}
+
+	other := NewErrorPayload("", fmt.Errorf("user 1 not found"))
+	if other.Fingerprint == fps[0] {
+		t.Error("different call site should change the fingerprint")
+	}
+	typed := captureAt(fingerprintlessError{})
+	if typed.Fingerprint == fps[0] {
+		t.Error("different error type should change the fingerprint")
+	}
+}
+
+func TestErrorFingerprint_Override(t *testing.T) {
+	p := NewErrorPayload("", fmt.Errorf("query: %w", fingerprintedError{}))
+	if p.Fingerprint != "db-timeout" {
+		t.Errorf("Fingerprint = %q, want the wrapped error's override", p.Fingerprint)
+	}
+}
+
+func TestComputeFingerprint_Frames(t *testing.T) {
+	app := []StackFrame{
+		{Function: "github.com/acme/shop/orders.(*Service).Place.func1"},
+		{Function: "net/http.HandlerFunc.ServeHTTP"},
+		{Function: "github.com/logflux-io/logflux-go-sdk/v3.(*Hub).CaptureError"},
+		{Function: "main.main"},
+	}
+	moved := []StackFrame{
+		{Function: "github.com/logflux-io/logflux-go-sdk/v3.CaptureError"},
+		{Function: "github.com/acme/shop/orders.(*Service).Place.func2", Line: 99},
+		{Function: "main.main", Line: 12},
+	}
+	if ComputeFingerprint("T", "a", app) != ComputeFingerprint("T", "b", moved) {
+		t.Error("SDK/stdlib frames, closure numbers and lines should not affect the fingerprint")
+	}
+	if ComputeFingerprint("T", "id 1", nil) != ComputeFingerprint("T", "id 2", nil) {
+		t.Error("without frames, digits in the message should be normalized")
+	}
+
+	for fn, want := range map[string]bool{
+		"main.run":               true,
+		"github.com/acme/x.F":    true,
+		"net/http.(*conn).serve": false,
+		"fmt.Errorf":             false,
+		"github.com/logflux-io/logflux-go-sdk/v3/gin.Middleware": false,
+	} {
+		if got := isAppFunction(fn); got != want {
+			t.Errorf("isAppFunction(%q) = %v, want %v", fn, got, want)
+		}
+	}
+}
@@ -4,6 +4,9 @@ package payload
 
 import (
 	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
 	"time"
 )
 
@@ -14,7 +17,7 @@ type common struct {
 	Source     string            `json:"source"`
 	Level      int               `json:"level,omitempty"`
 	Ts         string            `json:"ts,omitempty"`
-	Attributes map[string]string `json:"attributes,omitempty"`
+	Attributes Attributes        `json:"attributes,omitempty"`
 	Meta       map[string]string `json:"meta,omitempty"`
 }
 
@@ -35,6 +38,8 @@ type Log struct {
 	common
 	Message string `json:"message"`
 	Logger  string `json:"logger,omitempty"`
+	TraceID string `json:"trace_id,omitempty"`
+	SpanID  string `json:"span_id,omitempty"`
 }
 
 // NewLog creates a log payload.
@@ -54,6 +59,48 @@ type Metric struct {
 	Value float64 `json:"value"`
 	Unit  string  `json:"unit,omitempty"`
 	Kind  string  `json:"kind,omitempty"`
+
+	// Summary is set on histograms and on distributions aggregated over an
+	// interval; Value then holds their mean.
+	Summary *Summary `json:"summary,omitempty"`
+
+	// Histogram holds the bucket counts of a histogram metric.
+	Histogram *Histogram `json:"histogram,omitempty"`
+}
+
+// Summary describes the values of a distribution or histogram. Quantiles
+// are keyed "p50", "p90", "p95" and "p99".
+type Summary struct {
+	Count     uint64             `json:"count"`
+	Sum       float64            `json:"sum"`
+	Min       float64            `json:"min"`
+	Max       float64            `json:"max"`
+	Quantiles map[string]float64 `json:"quantiles,omitempty"`
+}
+
+// QuantileKey formats quantile q in [0, 1] as a Summary.Quantiles key, e.g.
+// 0.99 as "p99" and 0.999 as "p99.9".
+func QuantileKey(q float64) string {
+	return "p" + strconv.FormatFloat(math.Round(q*1e4)/1e2, 'f', -1, 64)
+}
+
+// Histogram holds per-bucket (not cumulative) counts: Counts[i] is the
+// number of values in (Bounds[i-1], Bounds[i]], and the last count, at index
+// len(Bounds), holds the values above every bound.
+type Histogram struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []uint64  `json:"counts"`
+}
+
+// NewHistogramBuckets returns an empty histogram with the given ascending
+// upper bounds.
+func NewHistogramBuckets(bounds []float64) *Histogram {
+	return &Histogram{Bounds: bounds, Counts: make([]uint64, len(bounds)+1)}
+}
+
+// Observe counts v in its bucket.
+func (h *Histogram) Observe(v float64) {
+	h.Counts[sort.SearchFloat64s(h.Bounds, v)]++
 }
 
 // NewCounter creates a counter metric.
@@ -88,6 +135,25 @@ func NewDistribution(source, name string, value float64, unit string) *Metric {
 	}
 }
 
+// NewHistogram creates a histogram metric holding the single value v,
+// counted in buckets with the given ascending upper bounds.
+func NewHistogram(source, name string, v float64, unit string, bounds []float64) *Metric {
+	h := NewHistogramBuckets(bounds)
+	h.Observe(v)
+	return &Metric{
+		common:    newCommon("metric", source, 7),
+		Name:      name,
+		Value:     v,
+		Unit:      unit,
+		Kind:      "histogram",
+		Histogram: h,
+		Summary: &Summary{
+			Count: 1, Sum: v, Min: v, Max: v,
+			Quantiles: map[string]float64{"p50": v, "p90": v, "p95": v, "p99": v},
+		},
+	}
+}
+
 // --- Type 3: Trace ---
 
 // Trace represents a v2 trace span payload.
@@ -102,6 +168,25 @@ type Trace struct {
 	EndTime      string `json:"end_time"`
 	DurationMs   int64  `json:"duration_ms,omitempty"`
 	Status       string `json:"status,omitempty"`
+
+	StatusMessage string      `json:"status_message,omitempty"`
+	Kind          string      `json:"kind,omitempty"` // server, client, producer, consumer or internal
+	Events        []SpanEvent `json:"events,omitempty"`
+	Links         []SpanLink  `json:"links,omitempty"`
+}
+
+// SpanEvent is a timestamped event recorded during a span.
+type SpanEvent struct {
+	Name       string     `json:"name"`
+	Ts         string     `json:"ts"`
+	Attributes Attributes `json:"attributes,omitempty"`
+}
+
+// SpanLink points from a span to a span of another, or the same, trace.
+type SpanLink struct {
+	TraceID    string     `json:"trace_id"`
+	SpanID     string     `json:"span_id"`
+	Attributes Attributes `json:"attributes,omitempty"`
 }
 
 // NewTrace creates a trace span payload.
@@ -187,14 +272,27 @@ func NewTelemetry(source, deviceID string, readings []Reading) *Telemetry {
 
 // --- Fluent setters (all types via embedded common) ---
 
-func (c *common) SetAttributes(attrs map[string]string)    { c.Attributes = attrs }
+func (c *common) SetAttributes(attrs map[string]string)    { c.Attributes = AttributesFromStrings(attrs) }
+func (c *common) SetAttrs(attrs Attributes)                 { c.Attributes = attrs }
 func (c *common) SetMeta(meta map[string]string)           { c.Meta = meta }
 func (c *common) SetLevel(level int)                       { c.Level = level }
 func (c *common) SetSource(source string)                  { c.Source = source }
 func (c *common) SetTimestamp(ts time.Time)                { c.Ts = ts.UTC().Format(time.RFC3339Nano) }
 
-// Exported getter for scope integration.
-func (c *common) GetAttributes() map[string]string { return c.Attributes }
+// SetAttr sets a single typed attribute (see Attributes.Set).
+func (c *common) SetAttr(key string, value any) {
+	if c.Attributes == nil {
+		c.Attributes = make(Attributes)
+	}
+	c.Attributes.Set(key, value)
+}
+
+// GetAttributes returns the attributes with every value in string form.
+// Use GetAttrs for the typed values.
+func (c *common) GetAttributes() map[string]string { return c.Attributes.Strings() }
+
+// GetAttrs returns the typed attributes (exported for scope integration).
+func (c *common) GetAttrs() Attributes { return c.Attributes }
 
 // --- Serialization ---
 
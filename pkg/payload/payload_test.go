@@ -60,6 +60,37 @@ func TestMetric_Counter(t *testing.T) {
 	}
 }
 
+func TestMetric_Histogram(t *testing.T) {
+	p := NewHistogram("api", "request.duration", 30, "ms", []float64{10, 25, 50})
+	data, _ := Marshal(p)
+
+	var m struct {
+		Kind      string     `json:"kind"`
+		Unit      string     `json:"unit"`
+		Summary   *Summary   `json:"summary"`
+		Histogram *Histogram `json:"histogram"`
+	}
+	json.Unmarshal(data, &m)
+
+	if m.Kind != "histogram" || m.Unit != "ms" {
+		t.Errorf("kind = %q, unit = %q", m.Kind, m.Unit)
+	}
+	if m.Histogram == nil || len(m.Histogram.Counts) != 4 || m.Histogram.Counts[2] != 1 {
+		t.Errorf("histogram = %+v", m.Histogram)
+	}
+	if m.Summary == nil || m.Summary.Count != 1 || m.Summary.Sum != 30 || m.Summary.Quantiles["p99"] != 30 {
+		t.Errorf("summary = %+v", m.Summary)
+	}
+
+	h := NewHistogramBuckets([]float64{1, 2})
+	for _, v := range []float64{0.5, 1, 1.5, 3} {
+		h.Observe(v)
+	}
+	if h.Counts[0] != 2 || h.Counts[1] != 1 || h.Counts[2] != 1 {
+		t.Errorf("counts = %v", h.Counts)
+	}
+}
+
 func TestTrace_Serialization(t *testing.T) {
 	start := time.Date(2026, 3, 15, 14, 30, 45, 0, time.UTC)
 	end := start.Add(143 * time.Millisecond)
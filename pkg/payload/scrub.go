@@ -0,0 +1,382 @@
+package payload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// ScrubAction selects how a Scrubber replaces sensitive data.
+type ScrubAction int
+
+const (
+	// ScrubMask replaces the value with RedactedValue.
+	ScrubMask ScrubAction = iota
+	// ScrubHash replaces the value with a salted SHA-256 prefix
+	// ("sha256:…"), so equal values can still be correlated.
+	ScrubHash
+	// ScrubRemove deletes denylisted attributes and cuts pattern matches
+	// out of text.
+	ScrubRemove
+)
+
+// String returns the action name as accepted by ParseScrubAction.
+func (a ScrubAction) String() string {
+	switch a {
+	case ScrubHash:
+		return "hash"
+	case ScrubRemove:
+		return "remove"
+	}
+	return "mask"
+}
+
+// ParseScrubAction parses "mask", "hash" or "remove". An empty string
+// selects ScrubMask.
+func ParseScrubAction(s string) (ScrubAction, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "mask":
+		return ScrubMask, nil
+	case "hash":
+		return ScrubHash, nil
+	case "remove":
+		return ScrubRemove, nil
+	}
+	return 0, fmt.Errorf("unknown scrub action %q (want mask, hash or remove)", s)
+}
+
+// ScrubRule finds sensitive substrings in text values.
+type ScrubRule struct {
+	Name string
+	// Pattern locates candidates. If it has a capturing group, only the
+	// first group is replaced (e.g. the token after "Bearer ").
+	Pattern *regexp.Regexp
+	// Validate, if set, must return true for a candidate to be scrubbed
+	// (e.g. a Luhn check for card numbers).
+	Validate func(match string) bool
+	Action   ScrubAction
+}
+
+// ScrubOptions configures a Scrubber.
+type ScrubOptions struct {
+	// Action is applied by the default rules and to denylisted keys.
+	Action ScrubAction
+	// Rules are applied in addition to the defaults.
+	Rules []ScrubRule
+	// DenyKeys are attribute keys whose values are always scrubbed, in
+	// addition to DefaultDenyKeys. Matching is case-insensitive, ignores
+	// '_' and '-', and matches anywhere in the key, so "password" also
+	// covers "db.Password" and "user_password_hash".
+	DenyKeys []string
+	// IPs also scrubs IPv4 and IPv6 addresses.
+	IPs bool
+	// DisableDefaults drops the default rules and deny keys, leaving only
+	// Rules and DenyKeys.
+	DisableDefaults bool
+	// HashSalt is prepended to values before hashing with ScrubHash.
+	HashSalt string
+}
+
+// DefaultDenyKeys are the attribute keys scrubbed by default.
+var DefaultDenyKeys = []string{
+	"password", "passwd", "secret", "authorization", "api_key",
+	"access_token", "refresh_token", "private_key", "cookie", "session_id",
+}
+
+var (
+	emailPattern     = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	cardPattern      = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+	bearerPattern    = regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`)
+	awsKeyIDPattern  = regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)
+	awsSecretPattern = regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`)
+	apiKeyPattern    = regexp.MustCompile(`\b(?:[a-z]{2,}-)?lf_[A-Za-z0-9_\-]{8,}`)
+	ipv4Pattern      = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`)
+	ipv6Pattern      = regexp.MustCompile(`(?i)(?:[0-9a-f]{0,4}:){2,7}[0-9a-f]{0,4}`)
+)
+
+// DefaultScrubRules returns the built-in rules: email addresses, card
+// numbers passing a Luhn check, bearer tokens, AWS access key IDs and
+// secret keys, and LogFlux API keys. With ips, IPv4 and IPv6 addresses are
+// included.
+func DefaultScrubRules(action ScrubAction, ips bool) []ScrubRule {
+	rules := []ScrubRule{
+		{Name: "email", Pattern: emailPattern, Action: action},
+		{Name: "credit_card", Pattern: cardPattern, Validate: luhnValid, Action: action},
+		{Name: "bearer_token", Pattern: bearerPattern, Action: action},
+		{Name: "aws_access_key_id", Pattern: awsKeyIDPattern, Action: action},
+		{Name: "aws_secret_key", Pattern: awsSecretPattern, Action: action},
+		{Name: "logflux_api_key", Pattern: apiKeyPattern, Action: action},
+	}
+	if ips {
+		rules = append(rules,
+			ScrubRule{Name: "ipv4", Pattern: ipv4Pattern, Action: action},
+			ScrubRule{Name: "ipv6", Pattern: ipv6Pattern, Validate: isIPv6, Action: action},
+		)
+	}
+	return rules
+}
+
+// Scrubber removes PII and secrets from payloads. It never modifies maps
+// or slices it did not create, so attributes shared with the caller or the
+// breadcrumb buffer are copied before being changed. A Scrubber is
+// immutable and safe for concurrent use.
+type Scrubber struct {
+	rules     []ScrubRule
+	denyKeys  []string // normalized
+	keyAction ScrubAction
+	salt      string
+}
+
+// NewScrubber creates a Scrubber from opts.
+func NewScrubber(opts ScrubOptions) *Scrubber {
+	s := &Scrubber{keyAction: opts.Action, salt: opts.HashSalt}
+	keys := opts.DenyKeys
+	if !opts.DisableDefaults {
+		s.rules = DefaultScrubRules(opts.Action, opts.IPs)
+		keys = append(append([]string(nil), DefaultDenyKeys...), keys...)
+	}
+	s.rules = append(s.rules, opts.Rules...)
+	for _, k := range keys {
+		if k = normalizeKey(k); k != "" {
+			s.denyKeys = append(s.denyKeys, k)
+		}
+	}
+	return s
+}
+
+// Scrub scrubs a payload in place and returns the number of values it
+// redacted. It covers messages, attributes, error chain messages and
+// breadcrumb messages and data. Audit identity fields (actor, resource)
+// are left untouched. Unknown types are ignored.
+func (s *Scrubber) Scrub(p any) int {
+	if s == nil {
+		return 0
+	}
+	var n int
+	switch p := p.(type) {
+	case *Log:
+		p.Message = s.text(p.Message, &n)
+		p.Attributes = s.attributes(p.Attributes, &n)
+	case *ErrorPayload:
+		p.Message = s.text(p.Message, &n)
+		p.Attributes = s.attributes(p.Attributes, &n)
+		if len(p.ErrorChain) > 0 {
+			chain := make([]ChainedError, len(p.ErrorChain))
+			for i, e := range p.ErrorChain {
+				e.Message = s.text(e.Message, &n)
+				chain[i] = e
+			}
+			p.ErrorChain = chain
+		}
+		p.Breadcrumbs = s.breadcrumbs(p.Breadcrumbs, &n)
+	case *Metric:
+		p.Attributes = s.attributes(p.Attributes, &n)
+	case *Event:
+		p.Attributes = s.attributes(p.Attributes, &n)
+	case *Audit:
+		p.Attributes = s.attributes(p.Attributes, &n)
+	case *Trace:
+		p.Attributes = s.attributes(p.Attributes, &n)
+	case *Telemetry:
+		p.Attributes = s.attributes(p.Attributes, &n)
+	}
+	return n
+}
+
+// String scrubs a single text value and reports whether it changed.
+func (s *Scrubber) String(v string) (string, bool) {
+	var n int
+	v = s.text(v, &n)
+	return v, n > 0
+}
+
+// Attributes returns a scrubbed copy of a (or a itself if nothing matched)
+// and the number of values redacted.
+func (s *Scrubber) Attributes(a Attributes) (Attributes, int) {
+	var n int
+	return s.attributes(a, &n), n
+}
+
+func (s *Scrubber) attributes(a Attributes, n *int) Attributes {
+	var out Attributes // copy made on first change
+	set := func(k string, v any, remove bool) {
+		if out == nil {
+			out = a.Clone()
+		}
+		if remove {
+			delete(out, k)
+		} else {
+			out[k] = v
+		}
+		*n++
+	}
+	for k, v := range a {
+		if s.deniedKey(k) {
+			switch s.keyAction {
+			case ScrubRemove:
+				set(k, nil, true)
+			case ScrubHash:
+				set(k, s.hash(attributeString(v)), false)
+			default:
+				set(k, RedactedValue, false)
+			}
+			continue
+		}
+		switch v := v.(type) {
+		case string:
+			if sv, changed := s.String(v); changed {
+				set(k, sv, false)
+			}
+		case []string:
+			var cp []string
+			for i, e := range v {
+				if se, changed := s.String(e); changed {
+					if cp == nil {
+						cp = append([]string(nil), v...)
+					}
+					cp[i] = se
+				}
+			}
+			if cp != nil {
+				set(k, cp, false)
+			}
+		}
+	}
+	if out == nil {
+		return a
+	}
+	return out
+}
+
+func (s *Scrubber) breadcrumbs(crumbs []Breadcrumb, n *int) []Breadcrumb {
+	if len(crumbs) == 0 {
+		return crumbs
+	}
+	out := make([]Breadcrumb, len(crumbs))
+	for i, b := range crumbs {
+		b.Message = s.text(b.Message, n)
+		if len(b.Data) > 0 {
+			before := *n
+			if attrs := s.attributes(AttributesFromStrings(b.Data), n); *n != before {
+				b.Data = attrs.Strings()
+			}
+		}
+		out[i] = b
+	}
+	return out
+}
+
+// text applies every rule to v, counting one redaction if it changed.
+func (s *Scrubber) text(v string, n *int) string {
+	if v == "" {
+		return v
+	}
+	orig := v
+	for _, r := range s.rules {
+		if r.Pattern != nil {
+			v = s.applyRule(r, v)
+		}
+	}
+	if v != orig {
+		*n++
+	}
+	return v
+}
+
+func (s *Scrubber) applyRule(r ScrubRule, v string) string {
+	matches := r.Pattern.FindAllStringSubmatchIndex(v, -1)
+	if matches == nil {
+		return v
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		match := v[start:end]
+		if r.Validate != nil && !r.Validate(match) {
+			continue
+		}
+		b.WriteString(v[last:start])
+		switch r.Action {
+		case ScrubHash:
+			b.WriteString(s.hash(match))
+		case ScrubRemove:
+		default:
+			b.WriteString(RedactedValue)
+		}
+		last = end
+	}
+	if last == 0 {
+		return v
+	}
+	b.WriteString(v[last:])
+	return b.String()
+}
+
+func (s *Scrubber) deniedKey(key string) bool {
+	if len(s.denyKeys) == 0 {
+		return false
+	}
+	k := normalizeKey(key)
+	for _, d := range s.denyKeys {
+		if strings.Contains(k, d) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scrubber) hash(v string) string {
+	sum := sha256.Sum256([]byte(s.salt + v))
+	return "sha256:" + hex.EncodeToString(sum[:12])
+}
+
+// normalizeKey lower-cases key and drops '_' and '-' so "API-Key",
+// "api_key" and "apikey" compare equal.
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' {
+			return -1
+		}
+		if 'A' <= r && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, key)
+}
+
+// luhnValid reports whether the digits of s (13 to 19 of them, ignoring
+// spaces and dashes) pass the Luhn checksum.
+func luhnValid(s string) bool {
+	var sum, count int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if count%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		count++
+	}
+	return count >= 13 && count <= 19 && sum%10 == 0
+}
+
+func isIPv6(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && strings.Contains(s, ":")
+}
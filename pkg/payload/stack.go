@@ -0,0 +1,311 @@
+package payload
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// StackOptions controls how stack traces are captured. The process-wide
+// settings are replaced with SetStackOptions; the methods on *StackOptions
+// capture with a given set instead (a nil *StackOptions uses the
+// process-wide one).
+type StackOptions struct {
+	// MaxFrames is the most frames kept per stack (default: 20).
+	MaxFrames int
+	// ContextLines is how many source lines around each in-app frame are
+	// included when the source file is readable (default: 0, off).
+	ContextLines int
+	// InAppPrefixes are extra package path prefixes treated as application
+	// code, in addition to the main module.
+	InAppPrefixes []string
+}
+
+const (
+	defaultMaxFrames = 20
+	maxContextLines  = 10
+	maxLineLength    = 200
+	maxSourceFiles   = 64
+	maxSourceSize    = 1 << 20
+
+	// minRecordedFrames is how many frames CallerPCs records at least, so
+	// that errors created before a hub with a larger MaxFrames captures
+	// them are not cut short.
+	minRecordedFrames = 64
+)
+
+var stackOpts atomic.Pointer[StackOptions]
+
+// SetStackOptions replaces the process-wide stack capture settings. Zero
+// fields select the defaults.
+func SetStackOptions(o StackOptions) {
+	stackOpts.Store(o.withDefaults())
+}
+
+// withDefaults returns a copy of o with the defaults and bounds applied.
+func (o StackOptions) withDefaults() *StackOptions {
+	if o.MaxFrames <= 0 {
+		o.MaxFrames = defaultMaxFrames
+	}
+	if o.ContextLines < 0 {
+		o.ContextLines = 0
+	}
+	if o.ContextLines > maxContextLines {
+		o.ContextLines = maxContextLines
+	}
+	o.InAppPrefixes = append([]string(nil), o.InAppPrefixes...)
+	return &o
+}
+
+// resolve returns o with the defaults applied, or the process-wide options
+// when o is nil.
+func (o *StackOptions) resolve() *StackOptions {
+	if o == nil {
+		return getStackOptions()
+	}
+	if o.MaxFrames > 0 && o.ContextLines >= 0 && o.ContextLines <= maxContextLines {
+		return o
+	}
+	return o.withDefaults()
+}
+
+func getStackOptions() *StackOptions {
+	if o := stackOpts.Load(); o != nil {
+		return o
+	}
+	return &StackOptions{MaxFrames: defaultMaxFrames}
+}
+
+// CallerPCs records the program counters of the calling goroutine's stack.
+// skip 0 starts at the caller of CallerPCs. It records enough frames for
+// the process-wide MaxFrames, and at least 64, once runtime frames are
+// dropped.
+func CallerPCs(skip int) []uintptr {
+	return callerPCs(skip+1, max(getStackOptions().MaxFrames, minRecordedFrames))
+}
+
+// callerPCs records up to maxFrames non-runtime frames; skip 0 starts at
+// the caller of callerPCs.
+func callerPCs(skip, maxFrames int) []uintptr {
+	pcs := make([]uintptr, maxFrames+16)
+	n := runtime.Callers(skip+2, pcs)
+	return pcs[:n]
+}
+
+// mainModule is the main module path from the build info, or "" when it is
+// unknown or this SDK itself (its tests and examples).
+var mainModule = sync.OnceValue(func() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok || strings.HasPrefix(bi.Main.Path+"/", sdkModule) {
+		return ""
+	}
+	return bi.Main.Path
+})
+
+// packagePath returns the import path of a fully qualified function name:
+// "github.com/acme/shop/orders.(*Service).Place" -> "github.com/acme/shop/orders".
+func packagePath(fn string) string {
+	slash := strings.LastIndexByte(fn, '/')
+	if dot := strings.IndexByte(fn[slash+1:], '.'); dot >= 0 {
+		return fn[:slash+1+dot]
+	}
+	return fn
+}
+
+// isAppFunction reports whether a fully qualified function name belongs to
+// application code: package main, the main module or an InAppPrefixes
+// entry. SDK frames never are. When the main module is unknown, any
+// package outside the standard library counts.
+func isAppFunction(fn string) bool {
+	return getStackOptions().isApp(fn)
+}
+
+// isApp is isAppFunction with o's InAppPrefixes.
+func (o *StackOptions) isApp(fn string) bool {
+	if fn == "" || strings.HasPrefix(fn, sdkModule) {
+		return false
+	}
+	pkg := packagePath(fn)
+	if pkg == "main" {
+		return true
+	}
+	prefixes := o.InAppPrefixes
+	if m := mainModule(); m != "" {
+		prefixes = append([]string{m}, prefixes...)
+	} else if len(prefixes) == 0 {
+		// Standard library import paths have no '.' in their first element.
+		first, _, _ := strings.Cut(pkg, "/")
+		return strings.Contains(first, ".")
+	}
+	for _, p := range prefixes {
+		if pkg == p || strings.HasPrefix(pkg, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleRoot is the directory of the main module's sources, learned from
+// the first in-app frame whose location reveals it.
+var moduleRoot atomic.Pointer[string]
+
+// trimFile shortens an absolute source path to an import-path-qualified
+// one: "/root/go/pkg/mod/github.com/x/y@v1.2.0/z.go" becomes
+// "github.com/x/y@v1.2.0/z.go", "/usr/local/go/src/net/http/server.go"
+// becomes "net/http/server.go", and files of the main module become
+// "<module path>/<path in module>".
+func trimFile(file, fn string) string {
+	if i := strings.Index(file, "/pkg/mod/"); i >= 0 {
+		return file[i+len("/pkg/mod/"):]
+	}
+	dir, base := path.Split(file)
+	dir = strings.TrimSuffix(dir, "/")
+	pkg := packagePath(fn)
+	if pkg != "main" && strings.HasSuffix(dir, "/"+pkg) {
+		return pkg + "/" + base // GOROOT, GOPATH or vendor layout
+	}
+	m := mainModule()
+	if m == "" {
+		return file
+	}
+	if pkg != "main" && (pkg == m || strings.HasPrefix(pkg, m+"/")) {
+		rel := strings.TrimPrefix(pkg, m)
+		if strings.HasSuffix(dir, rel) {
+			root := strings.TrimSuffix(dir, rel)
+			moduleRoot.Store(&root)
+			return pkg + "/" + base
+		}
+	}
+	if root := moduleRoot.Load(); root != nil && strings.HasPrefix(file, *root+"/") {
+		return m + strings.TrimPrefix(file, *root)
+	}
+	return file
+}
+
+// GoroutineID returns the ID of the calling goroutine, or 0 if it cannot
+// be determined.
+func GoroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	// The trace starts with "goroutine 123 [running]:".
+	fields := bytes.Fields(buf[:n])
+	if len(fields) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(string(fields[1]), 10, 64)
+	return id
+}
+
+// sourceCache holds the lines of recently read source files. A nil entry
+// records a file that could not be read.
+var sourceCache = struct {
+	sync.Mutex
+	files map[string][]string
+}{files: make(map[string][]string)}
+
+func sourceLines(file string) []string {
+	sourceCache.Lock()
+	defer sourceCache.Unlock()
+	if lines, ok := sourceCache.files[file]; ok {
+		return lines
+	}
+	if len(sourceCache.files) >= maxSourceFiles {
+		for k := range sourceCache.files {
+			delete(sourceCache.files, k)
+			break
+		}
+	}
+	var lines []string
+	if info, err := os.Stat(file); err == nil && info.Size() <= maxSourceSize {
+		if f, err := os.Open(file); err == nil {
+			sc := bufio.NewScanner(f)
+			for sc.Scan() {
+				line := sc.Text()
+				if len(line) > maxLineLength {
+					line = line[:maxLineLength]
+				}
+				lines = append(lines, line)
+			}
+			f.Close()
+		}
+	}
+	sourceCache.files[file] = lines
+	return lines
+}
+
+// addSourceContext fills the context fields of f from the source file at
+// abs, if it is readable.
+func addSourceContext(f *StackFrame, abs string, n int) {
+	lines := sourceLines(abs)
+	i := f.Line - 1
+	if i < 0 || i >= len(lines) {
+		return
+	}
+	start, end := max(0, i-n), min(len(lines), i+n+1)
+	f.PreContext = append([]string(nil), lines[start:i]...)
+	f.ContextLine = lines[i]
+	f.PostContext = append([]string(nil), lines[i+1:end]...)
+}
+
+// newStackFrame converts a resolved runtime frame.
+func newStackFrame(frame runtime.Frame, opts *StackOptions) StackFrame {
+	f := StackFrame{
+		Function: frame.Function,
+		File:     trimFile(frame.File, frame.Function),
+		Line:     frame.Line,
+		InApp:    opts.isApp(frame.Function),
+	}
+	if f.InApp && opts.ContextLines > 0 {
+		addSourceContext(&f, frame.File, opts.ContextLines)
+	}
+	return f
+}
+
+// PanicStack returns the stack of the panicking goroutine when called from
+// a deferred function during a panic: the frames below the innermost
+// runtime.gopanic, starting at the function that panicked. Outside a panic
+// it returns the caller's stack.
+func PanicStack() []StackFrame {
+	return panicStack(getStackOptions())
+}
+
+// PanicStack is the package-level PanicStack with o's settings.
+func (o *StackOptions) PanicStack() []StackFrame {
+	return panicStack(o.resolve())
+}
+
+func panicStack(opts *StackOptions) []StackFrame {
+	pcs := make([]uintptr, opts.MaxFrames+64) // room for the recovery frames
+	n := runtime.Callers(2, pcs)
+	var all []runtime.Frame
+	start := 0
+	it := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := it.Next()
+		all = append(all, frame)
+		if frame.Function == "runtime.gopanic" {
+			start = len(all)
+		}
+		if !more {
+			break
+		}
+	}
+	var frames []StackFrame
+	for _, frame := range all[start:] {
+		if strings.HasPrefix(frame.Function, "runtime.") {
+			continue
+		}
+		frames = append(frames, newStackFrame(frame, opts))
+		if len(frames) >= opts.MaxFrames {
+			break
+		}
+	}
+	return frames
+}
@@ -0,0 +1,141 @@
+package payload
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPackagePath(t *testing.T) {
+	for fn, want := range map[string]string{
+		"github.com/acme/shop/orders.(*Service).Place.func1": "github.com/acme/shop/orders",
+		"net/http.(*conn).serve":                             "net/http",
+		"main.main":                                          "main",
+		"fmt.Errorf":                                         "fmt",
+	} {
+		if got := packagePath(fn); got != want {
+			t.Errorf("packagePath(%q) = %q, want %q", fn, got, want)
+		}
+	}
+}
+
+func withMainModule(t *testing.T, m string) {
+	t.Helper()
+	prev := mainModule
+	mainModule = func() string { return m }
+	t.Cleanup(func() {
+		mainModule = prev
+		moduleRoot.Store(nil)
+		SetStackOptions(StackOptions{})
+	})
+}
+
+func TestInAppAndTrimFile(t *testing.T) {
+	withMainModule(t, "github.com/acme/shop")
+	SetStackOptions(StackOptions{InAppPrefixes: []string{"github.com/acme/lib"}})
+
+	for fn, want := range map[string]bool{
+		"github.com/acme/shop/orders.Place":                    true,
+		"github.com/acme/lib/retry.Do":                         true,
+		"main.main":                                            true,
+		"github.com/go-chi/chi/v5.(*Mux).ServeHTTP":            false,
+		"net/http.HandlerFunc.ServeHTTP":                       false,
+		"github.com/logflux-io/logflux-go-sdk/v3.CaptureError": false,
+	} {
+		if got := isAppFunction(fn); got != want {
+			t.Errorf("isAppFunction(%q) = %v, want %v", fn, got, want)
+		}
+	}
+
+	cases := []struct{ file, fn, want string }{
+		{"/root/go/pkg/mod/github.com/go-chi/chi/v5@v5.0.12/mux.go", "github.com/go-chi/chi/v5.(*Mux).ServeHTTP", "github.com/go-chi/chi/v5@v5.0.12/mux.go"},
+		{"/usr/local/go/src/net/http/server.go", "net/http.HandlerFunc.ServeHTTP", "net/http/server.go"},
+		{"/home/dev/shop/orders/place.go", "github.com/acme/shop/orders.Place", "github.com/acme/shop/orders/place.go"},
+		// The module root learned above also places package main.
+		{"/home/dev/shop/cmd/api/main.go", "main.main", "github.com/acme/shop/cmd/api/main.go"},
+		{"/elsewhere/x.go", "main.other", "/elsewhere/x.go"},
+	}
+	for _, c := range cases {
+		if got := trimFile(c.file, c.fn); got != c.want {
+			t.Errorf("trimFile(%q) = %q, want %q", c.file, got, c.want)
+		}
+	}
+}
+
+func deepError(n int) *ErrorPayload {
+	if n == 0 {
+		return NewErrorPayload("", errors.New("deep"))
+	}
+	return deepError(n - 1)
+}
+
+func TestStackOptions_MaxFrames(t *testing.T) {
+	t.Cleanup(func() { SetStackOptions(StackOptions{}) })
+	if got := len(deepError(30).StackTrace); got != defaultMaxFrames {
+		t.Errorf("default frames = %d, want %d", got, defaultMaxFrames)
+	}
+	SetStackOptions(StackOptions{MaxFrames: 5})
+	if got := len(deepError(30).StackTrace); got != 5 {
+		t.Errorf("frames = %d, want 5", got)
+	}
+	SetStackOptions(StackOptions{MaxFrames: 40})
+	if got := len(deepError(30).StackTrace); got <= defaultMaxFrames {
+		t.Errorf("frames = %d, want more than %d", got, defaultMaxFrames)
+	}
+}
+
+func TestAddSourceContext(t *testing.T) {
+	_, file, line, _ := runtime.Caller(0)
+	f := StackFrame{Line: line}
+	addSourceContext(&f, file, 2)
+	if !strings.Contains(f.ContextLine, "runtime.Caller(0)") || len(f.PreContext) != 2 || len(f.PostContext) != 2 {
+		t.Errorf("context = %q / %q / %q", f.PreContext, f.ContextLine, f.PostContext)
+	}
+
+	missing := StackFrame{Line: 3}
+	addSourceContext(&missing, "/nonexistent/file.go", 2)
+	if missing.ContextLine != "" {
+		t.Error("unreadable files should add no context")
+	}
+}
+
+func TestGoroutineID(t *testing.T) {
+	id := GoroutineID()
+	other := make(chan uint64)
+	go func() { other <- GoroutineID() }()
+	if o := <-other; id == 0 || o == 0 || o == id {
+		t.Errorf("goroutine IDs %d and %d", id, o)
+	}
+	if p := NewErrorPayload("", errors.New("x")); p.GoroutineID != id {
+		t.Errorf("payload goroutine = %d, want %d", p.GoroutineID, id)
+	}
+}
+
+func TestStackOptions_Explicit(t *testing.T) {
+	t.Cleanup(func() { SetStackOptions(StackOptions{}) })
+	SetStackOptions(StackOptions{MaxFrames: 1})
+
+	err := errors.New("boom")
+	o := &StackOptions{MaxFrames: 2, InAppPrefixes: []string{"testing"}}
+	p := o.NewErrorPayload("", err)
+	if len(p.StackTrace) != 2 || !strings.HasSuffix(p.StackTrace[0].Function, ".TestStackOptions_Explicit") {
+		t.Fatalf("explicit options: %d frames starting at %q", len(p.StackTrace), p.StackTrace[0].Function)
+	}
+	if !p.StackTrace[1].InApp {
+		t.Errorf("frame %q should be in app via InAppPrefixes", p.StackTrace[1].Function)
+	}
+	if got := len(o.NewErrorPayloadWithMessage("", err, "msg").StackTrace); got != 2 {
+		t.Errorf("explicit options with message: %d frames", got)
+	}
+
+	// The process-wide settings are unchanged and used by a nil receiver.
+	for _, p := range []*ErrorPayload{NewErrorPayload("", err), (*StackOptions)(nil).NewErrorPayload("", err)} {
+		if len(p.StackTrace) != 1 || !strings.HasSuffix(p.StackTrace[0].Function, ".TestStackOptions_Explicit") {
+			t.Errorf("process-wide options: %d frames starting at %q", len(p.StackTrace), p.StackTrace[0].Function)
+		}
+	}
+	if isAppFunction("testing.tRunner") {
+		t.Error("explicit InAppPrefixes leaked into the process-wide settings")
+	}
+}
@@ -0,0 +1,232 @@
+package payload
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// RedactedValue replaces the value of fields tagged `logflux:",redact"`.
+const RedactedValue = "[REDACTED]"
+
+// maxStructAttributes bounds how many keys StructAttributes produces for a
+// single value. Fields beyond the limit are dropped.
+const maxStructAttributes = 128
+
+var (
+	stringerType      = reflect.TypeOf((*interface{ String() string })(nil)).Elem()
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	structEncoders sync.Map // reflect.Type -> *structEncoder
+)
+
+// structEncoder holds the precomputed field list of a struct type.
+type structEncoder struct {
+	fields []structField
+}
+
+type structField struct {
+	index     int
+	name      string // attribute key segment; empty for inlined embeds
+	omitEmpty bool
+	redact    bool
+	inline    bool // embedded struct without a tag name
+}
+
+// StructAttributes converts the exported fields of a struct (or pointer to
+// struct) to Attributes. Fields are configured with the `logflux` tag:
+//
+//	type User struct {
+//	    ID       string `logflux:"id"`
+//	    Email    string `logflux:"email,redact"`
+//	    Plan     string `logflux:",omitempty"`
+//	    internal string // unexported: skipped
+//	    Cache    *Cache `logflux:"-"`
+//	}
+//
+// Untagged fields use the snake_case form of their Go name. Nested structs
+// are flattened under dotted keys ("address.city"); embedded structs without
+// a tag name are inlined like encoding/json does. Values implementing
+// fmt.Stringer, error or encoding.TextMarshaler are stored as text, and
+// string-keyed maps are flattened like structs. Nesting deeper than the
+// attribute depth limit is stored as text and at most 128 keys are produced.
+//
+// A map passed directly is flattened the same way; any other value yields
+// nil. Encoders are built once per type and cached.
+func StructAttributes(v any) Attributes {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		a := make(Attributes)
+		encoderFor(rv.Type()).encode(a, "", rv, 0)
+		return a
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		a := make(Attributes, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() && len(a) < maxStructAttributes {
+			encodeValue(a, iter.Key().String(), iter.Value(), 1)
+		}
+		return a
+	}
+	return nil
+}
+
+// encoderFor returns the cached encoder for struct type t, building it on
+// first use.
+func encoderFor(t reflect.Type) *structEncoder {
+	if e, ok := structEncoders.Load(t); ok {
+		return e.(*structEncoder)
+	}
+	e := &structEncoder{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("logflux")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		f := structField{index: i, name: name}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				f.omitEmpty = true
+			case "redact":
+				f.redact = true
+			}
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct && !isTextType(sf.Type) {
+			f.inline = true
+		} else if !sf.IsExported() {
+			continue
+		}
+		if f.name == "" && !f.inline {
+			f.name = snakeCase(sf.Name)
+		}
+		e.fields = append(e.fields, f)
+	}
+	actual, _ := structEncoders.LoadOrStore(t, e)
+	return actual.(*structEncoder)
+}
+
+// encode stores the fields of rv into a under prefix.
+func (e *structEncoder) encode(a Attributes, prefix string, rv reflect.Value, depth int) {
+	for _, f := range e.fields {
+		if len(a) >= maxStructAttributes {
+			return
+		}
+		fv := rv.Field(f.index)
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+		key := prefix + f.name
+		switch {
+		case f.redact:
+			a[key] = RedactedValue
+		case f.inline:
+			d := depth
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					break
+				}
+				// Embedded pointers can form cycles (n.Node = n), so
+				// following one counts against the depth limit.
+				fv, d = fv.Elem(), d+1
+			}
+			if fv.Kind() == reflect.Struct && d <= maxAttributeDepth {
+				encoderFor(fv.Type()).encode(a, prefix, fv, d)
+			}
+		default:
+			encodeValue(a, key, fv, depth+1)
+		}
+	}
+}
+
+// encodeValue stores a single field value under key.
+func encodeValue(a Attributes, key string, fv reflect.Value, depth int) {
+	for fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return
+		}
+		if fv.Kind() == reflect.Pointer && isTextType(fv.Type()) {
+			break
+		}
+		fv = fv.Elem()
+	}
+	if !fv.CanInterface() {
+		return
+	}
+	v := fv.Interface()
+	if isStringer(v) {
+		a[key] = AttributeValue(v)
+		return
+	}
+	if tm, ok := v.(encoding.TextMarshaler); ok {
+		if text, err := tm.MarshalText(); err == nil {
+			a[key] = string(text)
+		}
+		return
+	}
+	switch {
+	case fv.Kind() == reflect.Struct:
+		if depth >= maxAttributeDepth {
+			a[key] = attributeString(AttributeValue(v))
+			return
+		}
+		encoderFor(fv.Type()).encode(a, key+".", fv, depth)
+	case fv.Kind() == reflect.Map && fv.Type().Key().Kind() == reflect.String:
+		if depth >= maxAttributeDepth {
+			a[key] = attributeString(AttributeValue(v))
+			return
+		}
+		iter := fv.MapRange()
+		for iter.Next() && len(a) < maxStructAttributes {
+			encodeValue(a, key+"."+iter.Key().String(), iter.Value(), depth+1)
+		}
+	default:
+		a[key] = AttributeValue(v)
+	}
+}
+
+// isTextType reports whether values of t render themselves as text.
+func isTextType(t reflect.Type) bool {
+	return t.Implements(stringerType) || t.Implements(errorType) || t.Implements(textMarshalerType)
+}
+
+// snakeCase converts a Go identifier to snake_case, keeping acronyms
+// together: "UserID" -> "user_id", "HTTPStatus" -> "http_status".
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					b.WriteByte('_')
+				}
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package payload
+
+import (
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testAddress struct {
+	City    string `logflux:"city"`
+	Country string `logflux:",omitempty"`
+}
+
+type testAudit struct {
+	CreatedAt time.Time
+}
+
+type testUser struct {
+	testAudit
+	UserID   string            `logflux:"id"`
+	Email    string            `logflux:"email,redact"`
+	Password string            `logflux:"-"`
+	Plan     string            `logflux:"plan,omitempty"`
+	Age      int               // untagged: snake_case name
+	HTTPRole testStringer      // Stringer
+	IP       net.IP            // TextMarshaler via pointer-free value
+	Address  *testAddress      `logflux:"addr"`
+	Labels   map[string]string `logflux:"labels"`
+	internal string
+}
+
+func TestStructAttributes(t *testing.T) {
+	u := &testUser{
+		testAudit: testAudit{CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		UserID:    "usr_1",
+		Email:     "a@example.com",
+		Password:  "hunter2",
+		Age:       42,
+		HTTPRole:  testStringer{"admin"},
+		IP:        net.ParseIP("10.0.0.1"),
+		Address:   &testAddress{City: "Berlin"},
+		Labels:    map[string]string{"tier": "gold"},
+		internal:  "x",
+	}
+	want := Attributes{
+		"created_at":  "2024-01-02T03:04:05Z",
+		"id":          "usr_1",
+		"email":       RedactedValue,
+		"age":         int64(42),
+		"http_role":   "admin",
+		"ip":          "10.0.0.1",
+		"addr.city":   "Berlin",
+		"labels.tier": "gold",
+	}
+	if got := StructAttributes(u); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %#v\nwant %#v", got, want)
+	}
+
+	// The cached encoder is reused for subsequent values of the same type.
+	if got := StructAttributes(testUser{UserID: "usr_2", Plan: "pro"}); got["plan"] != "pro" || got["id"] != "usr_2" {
+		t.Errorf("second encode = %#v", got)
+	}
+	if StructAttributes(42) != nil || StructAttributes((*testUser)(nil)) != nil {
+		t.Error("non-struct values should yield nil")
+	}
+}
+
+type testNode struct {
+	Name string    `logflux:"name"`
+	Next *testNode `logflux:"next"`
+}
+
+// testEmbeddedNode embeds a pointer to its own type.
+type testEmbeddedNode struct {
+	*testEmbeddedNode
+	X int
+}
+
+func TestStructAttributes_Limits(t *testing.T) {
+	// A cycle is cut off at the depth limit instead of recursing forever.
+	n := &testNode{Name: "loop"}
+	n.Next = n
+	a := StructAttributes(n)
+	if len(a) == 0 || len(a) > maxAttributeDepth+1 {
+		t.Fatalf("cyclic value produced %d keys: %v", len(a), a)
+	}
+
+	// So is a cycle through an embedded pointer, which is inlined; the
+	// outermost field wins, as with encoding/json.
+	e := &testEmbeddedNode{X: 1}
+	e.testEmbeddedNode = e
+	if got := StructAttributes(e); !reflect.DeepEqual(got, Attributes{"x": int64(1)}) {
+		t.Fatalf("embedded cycle = %#v", got)
+	}
+
+	wide := make(map[string]int, maxStructAttributes*2)
+	for i := 0; i < maxStructAttributes*2; i++ {
+		wide["k"+strconv.Itoa(i)] = i
+	}
+	if got := StructAttributes(struct{ M map[string]int }{wide}); len(got) != maxStructAttributes {
+		t.Errorf("wide map produced %d keys", len(got))
+	}
+	if got := StructAttributes(wide); len(got) != maxStructAttributes {
+		t.Errorf("map produced %d keys, want %d", len(got), maxStructAttributes)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"ID":         "id",
+		"UserID":     "user_id",
+		"HTTPStatus": "http_status",
+		"Retry2Back": "retry2_back",
+		"name":       "name",
+	}
+	for in, want := range cases {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
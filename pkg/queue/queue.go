@@ -19,8 +19,20 @@ type LogEntry struct {
 	SearchTokens []string
 	Retries      int
 	CreatedAt    time.Time
+
+	selfTelemetry bool
+}
+
+// WithSelfTelemetry returns a copy of e marked as the SDK's own statistics,
+// which the client counts apart from application entries.
+func (e LogEntry) WithSelfTelemetry() LogEntry {
+	e.selfTelemetry = true
+	return e
 }
 
+// SelfTelemetry reports whether e was marked with WithSelfTelemetry.
+func (e LogEntry) SelfTelemetry() bool { return e.selfTelemetry }
+
 // Queue is a thread-safe in-memory FIFO queue.
 type Queue struct {
 	items    []LogEntry
@@ -29,6 +41,7 @@ type Queue struct {
 	notEmpty chan struct{}
 	closeCh  chan struct{} // closed exactly once to signal shutdown
 	closed   bool
+	inFlight int // entries taken with Take and not yet released with Done
 }
 
 func NewQueue(maxSize int) *Queue {
@@ -62,6 +75,12 @@ func (q *Queue) Enqueue(entry LogEntry) bool {
 func (q *Queue) Dequeue() *LogEntry {
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	return q.dequeueLocked(false)
+}
+
+// dequeueLocked pops the oldest entry, counting it as in flight when track
+// is set. Must be called with q.mu held.
+func (q *Queue) dequeueLocked(track bool) *LogEntry {
 	if len(q.items) == 0 {
 		return nil
 	}
@@ -69,6 +88,9 @@ func (q *Queue) Dequeue() *LogEntry {
 	q.items[0] = LogEntry{} // clear reference to allow GC
 	q.items = q.items[1:]
 	q.compactLocked()
+	if track {
+		q.inFlight++
+	}
 	return &entry
 }
 
@@ -106,16 +128,45 @@ func (q *Queue) compactLocked() {
 // DequeueWithContext blocks until an entry is available or ctx is cancelled.
 // Returns nil when the context is cancelled or the queue is closed and empty.
 func (q *Queue) DequeueWithContext(ctx context.Context) *LogEntry {
+	return q.dequeueWait(ctx, false)
+}
+
+// Take blocks like DequeueWithContext, but the returned entry is counted as
+// in flight in the same step that removes it, so Idle never sees it as
+// neither queued nor in flight. The caller must call Done once the entry
+// (and anything batched with it) has been sent or dropped.
+func (q *Queue) Take(ctx context.Context) *LogEntry {
+	return q.dequeueWait(ctx, true)
+}
+
+// Done releases an entry returned by Take.
+func (q *Queue) Done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.inFlight > 0 {
+		q.inFlight--
+	}
+}
+
+// Idle reports whether the queue is empty and no entry returned by Take is
+// still awaiting Done.
+func (q *Queue) Idle() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return len(q.items) == 0 && q.inFlight == 0
+}
+
+func (q *Queue) dequeueWait(ctx context.Context, track bool) *LogEntry {
 	for {
-		if entry := q.Dequeue(); entry != nil {
+		q.mu.Lock()
+		entry := q.dequeueLocked(track)
+		closed := q.closed
+		q.mu.Unlock()
+		if entry != nil {
 			return entry
 		}
-		// Check if closed and empty
-		q.mu.RLock()
-		closed := q.closed
-		empty := len(q.items) == 0
-		q.mu.RUnlock()
-		if closed && empty {
+		// Closed and empty
+		if closed {
 			return nil
 		}
 		select {
@@ -123,10 +174,9 @@ func (q *Queue) DequeueWithContext(ctx context.Context) *LogEntry {
 			return nil
 		case <-q.closeCh:
 			// Queue was closed; drain remaining items
-			if entry := q.Dequeue(); entry != nil {
-				return entry
-			}
-			return nil
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			return q.dequeueLocked(track)
 		case <-q.notEmpty:
 			continue
 		}
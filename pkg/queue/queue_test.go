@@ -2,6 +2,8 @@ package queue
 
 import (
 	"context"
+	"runtime"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -66,3 +68,76 @@ func TestQueue_DequeueWithContext(t *testing.T) {
 		t.Fatalf("expected queued item, got %#v", e2)
 	}
 }
+
+func TestQueue_TakeCountsInFlight(t *testing.T) {
+	q := NewQueue(4)
+	if !q.Idle() {
+		t.Fatalf("new queue should be idle")
+	}
+	q.Enqueue(LogEntry{ID: "1"})
+
+	// The entry leaves the queue and becomes in flight in one step: there is
+	// no point at which the queue is empty and nothing is in flight.
+	e := q.Take(context.Background())
+	if e == nil || e.ID != "1" {
+		t.Fatalf("unexpected take: %#v", e)
+	}
+	if !q.IsEmpty() {
+		t.Fatalf("expected empty queue after take")
+	}
+	if q.Idle() {
+		t.Fatalf("queue reported idle while a taken entry is unsettled")
+	}
+	q.Done()
+	if !q.Idle() {
+		t.Fatalf("expected idle after done")
+	}
+
+	// Plain dequeues are not tracked.
+	q.Enqueue(LogEntry{ID: "2"})
+	if q.Dequeue() == nil || !q.Idle() {
+		t.Fatalf("untracked dequeue should leave the queue idle")
+	}
+}
+
+func TestQueue_IdleNeverSeesUnsettledTake(t *testing.T) {
+	q := NewQueue(1000)
+	const n = 500
+	var settled atomic.Int64
+	go func() {
+		for i := 0; i < n; i++ {
+			if q.Take(context.Background()) == nil {
+				return
+			}
+			settled.Add(1)
+			q.Done()
+		}
+	}()
+	for i := 0; i < n; i++ {
+		q.Enqueue(LogEntry{ID: "x"})
+		// Wait for idle the way Flush does; every entry enqueued so far
+		// must be settled by the time it is reported.
+		deadline := time.Now().Add(5 * time.Second)
+		for !q.Idle() {
+			if time.Now().After(deadline) {
+				t.Fatalf("queue never became idle")
+			}
+			runtime.Gosched()
+		}
+		if got := settled.Load(); got != int64(i+1) {
+			t.Fatalf("idle reported with %d of %d entries settled", got, i+1)
+		}
+	}
+}
+
+func TestLogEntry_WithSelfTelemetry(t *testing.T) {
+	q := NewQueue(2)
+	q.Enqueue(LogEntry{ID: "app"})
+	q.Enqueue(LogEntry{ID: "sdk"}.WithSelfTelemetry())
+	if e := q.Dequeue(); e == nil || e.SelfTelemetry() {
+		t.Fatalf("application entry marked as self-telemetry: %#v", e)
+	}
+	if e := q.Dequeue(); e == nil || !e.SelfTelemetry() {
+		t.Fatalf("self-telemetry mark lost in the queue: %#v", e)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/diag"
 )
 
 // HTTPError represents an HTTP error with status code and retry information.
@@ -94,9 +96,11 @@ type RetryFunc func() error
 
 // Retryer handles retry logic with exponential backoff and jitter.
 type Retryer struct {
-	config Config
-	rng    *rand.Rand
-	mu     sync.Mutex
+	config     Config
+	rng        *rand.Rand
+	mu         sync.Mutex
+	httpClient *http.Client // transport for health checks (nil = default)
+	log        diag.Logger
 }
 
 func NewRetryer(config Config) *Retryer {
@@ -112,6 +116,7 @@ func NewRetryer(config Config) *Retryer {
 	return &Retryer{
 		config: config,
 		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		log:    diag.Nop,
 	}
 }
 
@@ -123,6 +128,9 @@ func NewRetryerWithDefaults() *Retryer {
 func (r *Retryer) Retry(ctx context.Context, fn RetryFunc) error {
 	var lastErr error
 	attempt := 0
+	r.mu.Lock()
+	log := r.log
+	r.mu.Unlock()
 
 	for {
 		if r.config.MaxRetries >= 0 && attempt > r.config.MaxRetries {
@@ -137,6 +145,7 @@ func (r *Retryer) Retry(ctx context.Context, fn RetryFunc) error {
 			lastErr = err
 
 			if !r.isRetryable(err) {
+				log.Debug("request failed, not retryable", "error", err)
 				return err
 			}
 
@@ -146,6 +155,7 @@ func (r *Retryer) Retry(ctx context.Context, fn RetryFunc) error {
 				if delay <= 0 {
 					delay = 60 * time.Second // minimum for bare 429
 				}
+				log.Info("rate limited, waiting", "retry_after", delay)
 				select {
 				case <-ctx.Done():
 					return ctx.Err()
@@ -159,6 +169,7 @@ func (r *Retryer) Retry(ctx context.Context, fn RetryFunc) error {
 			}
 
 			delay := r.calculateDelay(attempt)
+			log.Debug("request failed, retrying", "attempt", attempt+1, "max_retries", r.config.MaxRetries, "delay", delay, "error", err)
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
@@ -171,6 +182,7 @@ func (r *Retryer) Retry(ctx context.Context, fn RetryFunc) error {
 		attempt++
 	}
 
+	log.Warn("giving up after retries", "attempts", attempt, "error", lastErr)
 	return fmt.Errorf("retry failed after %d attempts: %w", attempt, lastErr)
 }
 
@@ -220,10 +232,20 @@ func (r *Retryer) isRetryable(err error) bool {
 }
 
 func (r *Retryer) waitForServerReadiness(ctx context.Context) error {
-	if !r.config.ResilientMode || r.config.HealthCheckURL == "" {
+	// The health check URL changes on endpoint failover, so read it under lock.
+	r.mu.Lock()
+	healthCheckURL := r.config.HealthCheckURL
+	r.mu.Unlock()
+	if !r.config.ResilientMode || healthCheckURL == "" {
 		return nil
 	}
 	client := &http.Client{Timeout: r.config.HealthCheckTimeout}
+	r.mu.Lock()
+	if r.httpClient != nil {
+		// Reuse the caller's transport (proxy, TLS) with the health check timeout.
+		client.Transport = r.httpClient.Transport
+	}
+	r.mu.Unlock()
 	backoff := r.config.InitialDelay
 
 	for attempt := 0; attempt < r.config.HealthCheckRetries; attempt++ {
@@ -232,7 +254,7 @@ func (r *Retryer) waitForServerReadiness(ctx context.Context) error {
 			return ctx.Err()
 		default:
 		}
-		req, err := http.NewRequestWithContext(ctx, "GET", r.config.HealthCheckURL, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", healthCheckURL, nil)
 		if err != nil {
 			return err
 		}
@@ -260,7 +282,23 @@ func (r *Retryer) waitForServerReadiness(ctx context.Context) error {
 }
 
 func (r *Retryer) SetHealthCheckURL(url string) {
+	r.mu.Lock()
 	r.config.HealthCheckURL = url
+	r.mu.Unlock()
+}
+
+// SetLogger sets the diagnostic logger for retry events.
+func (r *Retryer) SetLogger(l diag.Logger) {
+	r.mu.Lock()
+	r.log = diag.OrNop(l)
+	r.mu.Unlock()
+}
+
+// SetHTTPClient sets the client whose transport is used for health checks.
+func (r *Retryer) SetHTTPClient(c *http.Client) {
+	r.mu.Lock()
+	r.httpClient = c
+	r.mu.Unlock()
 }
 
 func (r *Retryer) EnableResilientMode(enabled bool) {
@@ -0,0 +1,91 @@
+// Package prometheus bridges metrics gathered from a prometheus.Gatherer,
+// such as prometheus.DefaultGatherer, into LogFlux:
+//
+//	bridge := logfluxprom.StartBridge(prometheus.DefaultGatherer, logflux.BridgeOptions{
+//		Interval: 30 * time.Second,
+//		Deny:     []string{"go_*", "process_*"},
+//	})
+//	defer bridge.Stop()
+//
+// It lives in its own module so that the SDK itself does not depend on
+// client_golang.
+package prometheus
+
+import (
+	"math"
+
+	"github.com/logflux-io/logflux-go-sdk/v3"
+	prom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// StartBridge starts a bridge from g into the default hub.
+func StartBridge(g prom.Gatherer, opts logflux.BridgeOptions) *logflux.Bridge {
+	return logflux.StartBridge(Source(g), opts)
+}
+
+// Source returns a MetricSource reading every metric family from g.
+// Counters, gauges, untyped metrics, histograms (including gauge
+// histograms) and summaries are converted; label names become attribute
+// keys.
+func Source(g prom.Gatherer) logflux.MetricSource {
+	return logflux.MetricSourceFunc(func() ([]logflux.MetricSample, error) {
+		families, err := g.Gather()
+		if len(families) == 0 {
+			return nil, err
+		}
+		// Gather may return partial results along with an error; send what
+		// was gathered.
+		var samples []logflux.MetricSample
+		for _, f := range families {
+			for _, m := range f.GetMetric() {
+				if s, ok := convert(f, m); ok {
+					samples = append(samples, s)
+				}
+			}
+		}
+		return samples, nil
+	})
+}
+
+func convert(f *dto.MetricFamily, m *dto.Metric) (logflux.MetricSample, bool) {
+	s := logflux.MetricSample{Name: f.GetName(), Unit: f.GetUnit()}
+	if pairs := m.GetLabel(); len(pairs) > 0 {
+		s.Labels = make(map[string]string, len(pairs))
+		for _, l := range pairs {
+			s.Labels[l.GetName()] = l.GetValue()
+		}
+	}
+	switch f.GetType() {
+	case dto.MetricType_COUNTER:
+		s.Kind, s.Value = "counter", m.GetCounter().GetValue()
+	case dto.MetricType_GAUGE:
+		s.Kind, s.Value = "gauge", m.GetGauge().GetValue()
+	case dto.MetricType_UNTYPED:
+		s.Kind, s.Value = "gauge", m.GetUntyped().GetValue()
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		h := m.GetHistogram()
+		s.Kind, s.Count, s.Sum = "histogram", h.GetSampleCount(), h.GetSampleSum()
+		if f.GetType() == dto.MetricType_GAUGE_HISTOGRAM {
+			// Not monotonic: sent as-is rather than as increases.
+			s.Kind = "gaugehistogram"
+		}
+		for _, b := range h.GetBucket() {
+			if math.IsInf(b.GetUpperBound(), 1) {
+				continue
+			}
+			s.Bounds = append(s.Bounds, b.GetUpperBound())
+			s.Cumulative = append(s.Cumulative, b.GetCumulativeCount())
+		}
+	case dto.MetricType_SUMMARY:
+		sm := m.GetSummary()
+		s.Kind, s.Count, s.Sum = "summary", sm.GetSampleCount(), sm.GetSampleSum()
+		s.Quantiles = make(map[float64]float64, len(sm.GetQuantile()))
+		for _, q := range sm.GetQuantile() {
+			s.Quantiles[q.GetQuantile()] = q.GetValue()
+		}
+	default:
+		return s, false
+	}
+	return s, true
+}
@@ -0,0 +1,59 @@
+package prometheus
+
+import (
+	"reflect"
+	"testing"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSource(t *testing.T) {
+	reg := prom.NewRegistry()
+	requests := prom.NewCounterVec(prom.CounterOpts{Name: "http_requests_total"}, []string{"code"})
+	latency := prom.NewHistogram(prom.HistogramOpts{Name: "latency_seconds", Buckets: []float64{0.1, 1}})
+	rpc := prom.NewSummary(prom.SummaryOpts{Name: "rpc_seconds", Objectives: map[float64]float64{0.5: 0.05}})
+	reg.MustRegister(requests, latency, rpc)
+	requests.WithLabelValues("200").Add(3)
+	for _, v := range []float64{0.05, 0.5, 5} {
+		latency.Observe(v)
+	}
+	rpc.Observe(2)
+
+	samples, err := Source(reg).Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	got := make(map[string]int)
+	for i, s := range samples {
+		got[s.Name] = i
+	}
+
+	c := samples[got["http_requests_total"]]
+	if c.Kind != "counter" || c.Value != 3 || c.Labels["code"] != "200" {
+		t.Errorf("counter = %+v", c)
+	}
+	h := samples[got["latency_seconds"]]
+	if h.Kind != "histogram" || h.Count != 3 || !reflect.DeepEqual(h.Bounds, []float64{0.1, 1}) ||
+		!reflect.DeepEqual(h.Cumulative, []uint64{1, 2}) {
+		t.Errorf("histogram = %+v", h)
+	}
+	s := samples[got["rpc_seconds"]]
+	if s.Kind != "summary" || s.Count != 1 || s.Quantiles[0.5] != 2 {
+		t.Errorf("summary = %+v", s)
+	}
+}
+
+func TestConvertGaugeHistogram(t *testing.T) {
+	f := &dto.MetricFamily{Name: proto.String("queue_depth"), Type: dto.MetricType_GAUGE_HISTOGRAM.Enum()}
+	m := &dto.Metric{Histogram: &dto.Histogram{
+		SampleCount: proto.Uint64(3),
+		SampleSum:   proto.Float64(12),
+		Bucket:      []*dto.Bucket{{UpperBound: proto.Float64(5), CumulativeCount: proto.Uint64(2)}},
+	}}
+	s, ok := convert(f, m)
+	if !ok || s.Kind != "gaugehistogram" || s.Count != 3 || !reflect.DeepEqual(s.Cumulative, []uint64{2}) {
+		t.Errorf("gauge histogram = %+v", s)
+	}
+}
@@ -0,0 +1,112 @@
+package logflux
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// defaultRecoverFlushTimeout bounds the flush after a recovered panic.
+const defaultRecoverFlushTimeout = 2 * time.Second
+
+// Recover captures a panic in progress. It must be deferred directly:
+//
+//	defer logflux.Recover(ctx)
+//
+// The panic value and the stack of the panicking goroutine are sent as an
+// error. If ctx carries a Scope (ContextWithScope) the error goes through
+// it with its attributes and breadcrumbs, otherwise it gets the hub's
+// breadcrumbs. A Span in ctx (ContextWithSpan) is marked as errored.
+// Queued entries are then flushed for up to RecoverFlushTimeout. With
+// RepanicOnRecover the panic resumes afterwards; otherwise it stays
+// recovered and the deferring function returns normally.
+func Recover(ctx context.Context) {
+	if rec := recover(); rec != nil {
+		if defaultHub.handlePanic(ctx, rec) {
+			panic(rec)
+		}
+	}
+}
+
+// Recover is like the package-level Recover but captures through this hub.
+func (h *Hub) Recover(ctx context.Context) {
+	if rec := recover(); rec != nil {
+		if h.handlePanic(ctx, rec) {
+			panic(rec)
+		}
+	}
+}
+
+// Go runs fn in a new goroutine, recovering and capturing a panic in it as
+// Recover does.
+func Go(ctx context.Context, fn func(ctx context.Context)) {
+	defaultHub.Go(ctx, fn)
+}
+
+// Go is like the package-level Go but captures through this hub.
+func (h *Hub) Go(ctx context.Context, fn func(ctx context.Context)) {
+	go func() {
+		defer h.Recover(ctx)
+		fn(ctx)
+	}()
+}
+
+// handlePanic captures rec and flushes. It must run on the panicking
+// goroutine, in a deferred call, so that the panic stack can be read. It
+// reports whether the panic should be resumed.
+func (h *Hub) handlePanic(ctx context.Context, rec any) bool {
+	err := panicError(rec)
+	span := SpanFromContext(ctx)
+	if span != nil {
+		span.SetError(err)
+	}
+
+	stack := h.stackOptions()
+	p := stack.NewErrorPayload("", err)
+	p.ErrorType = "panic"
+	p.StackTrace = stack.PanicStack()
+	p.Fingerprint = payload.ErrorFingerprint(err, p.ErrorType, p.StackTrace)
+	if span != nil {
+		p.SetAttributes(Fields{"trace_id": span.TraceID(), "span_id": span.SpanID()})
+	}
+
+	target := h
+	scope := ScopeFromContext(ctx)
+	if scope != nil {
+		target = scope.hub
+	}
+	if c := target.Client(); c != nil && target.sample() {
+		target.context.Apply(p)
+		var sendErr error
+		if scope != nil {
+			sendErr = scope.sendError(c, p)
+		} else {
+			sendErr = target.sendError(c, p)
+		}
+		if sendErr != nil {
+			target.logger().Warn("capturing panic failed", "error", sendErr)
+		}
+	}
+
+	target.mu.RLock()
+	timeout, repanic := target.panicFlush, target.repanic
+	target.mu.RUnlock()
+	if timeout <= 0 {
+		timeout = defaultRecoverFlushTimeout
+	}
+	if err := target.Flush(timeout); err != nil {
+		target.logger().Warn("flush after panic failed", "error", err)
+	}
+	return repanic
+}
+
+// panicError turns a recovered value into an error, wrapping error values
+// so that errors.Is and errors.As still match them.
+func panicError(rec any) error {
+	if err, ok := rec.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return fmt.Errorf("panic: %v", rec)
+}
@@ -0,0 +1,160 @@
+package logflux
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+var errBoom = errors.New("boom")
+
+func panicky() {
+	panic(errBoom)
+}
+
+func TestHub_Recover(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	var got *payload.ErrorPayload
+	// Scope entries skip the per-type hooks; read them at the transport.
+	opts.BeforeSend = func(e *models.LogEntry) *models.LogEntry {
+		got = new(payload.ErrorPayload)
+		if err := json.Unmarshal([]byte(e.Message), got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		return e
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	scope := newScope(hub)
+	scope.AddBreadcrumb("job", "started", nil)
+	span := hub.StartSpan("job", "sync")
+	ctx := ContextWithSpan(ContextWithScope(context.Background(), scope), span)
+
+	func() {
+		defer hub.Recover(ctx)
+		panicky()
+	}()
+
+	if got == nil {
+		t.Fatal("panic was not captured")
+	}
+	if got.ErrorType != "panic" || got.Message != "panic: boom" {
+		t.Errorf("type/message = %q / %q", got.ErrorType, got.Message)
+	}
+	if len(got.StackTrace) == 0 || !strings.HasSuffix(got.StackTrace[0].Function, ".panicky") {
+		t.Errorf("stack should start at the panic site: %#v", got.StackTrace)
+	}
+	if len(got.Breadcrumbs) != 1 || got.Breadcrumbs[0].Message != "started" {
+		t.Errorf("breadcrumbs = %#v", got.Breadcrumbs)
+	}
+	if got.Attributes["trace_id"] != span.TraceID() {
+		t.Errorf("attributes = %#v", got.Attributes)
+	}
+	if span.status != "error" {
+		t.Errorf("span status = %q, want error", span.status)
+	}
+}
+
+func TestHub_RecoverRepanic(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.RepanicOnRecover = true
+	captured := 0
+	opts.BeforeSendError = func(p *payload.ErrorPayload) *payload.ErrorPayload {
+		captured++
+		return p
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	var rec any
+	func() {
+		defer func() { rec = recover() }()
+		defer hub.Recover(context.Background())
+		panic("again")
+	}()
+	if rec != "again" || captured != 1 {
+		t.Errorf("recovered %v, captured %d", rec, captured)
+	}
+}
+
+func TestHub_Go(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	done := make(chan *payload.ErrorPayload, 1)
+	opts.BeforeSendError = func(p *payload.ErrorPayload) *payload.ErrorPayload {
+		done <- p
+		return p
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	hub.Go(context.Background(), func(ctx context.Context) {
+		var m map[string]int
+		m["x"] = 1 // nil map write
+	})
+	p := <-done
+	if !strings.Contains(p.Message, "nil map") {
+		t.Errorf("message = %q", p.Message)
+	}
+	if len(p.StackTrace) == 0 || !strings.Contains(p.StackTrace[0].Function, "TestHub_Go") {
+		t.Errorf("stack should start in the goroutine: %#v", p.StackTrace)
+	}
+}
+
+func TestTracingMiddleware_Panic(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	var captured *payload.ErrorPayload
+	var trace *payload.Trace
+	opts.BeforeSendError = func(p *payload.ErrorPayload) *payload.ErrorPayload {
+		captured = p
+		return p
+	}
+	opts.BeforeSendTrace = func(p *payload.Trace) *payload.Trace {
+		trace = p
+		return p
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	handler := hub.TracingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if SpanFromContext(r.Context()) == nil {
+			t.Error("span missing from request context")
+		}
+		panic("handler broke")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	if captured == nil || captured.Message != "panic: handler broke" {
+		t.Fatalf("captured = %#v", captured)
+	}
+	if trace == nil || trace.Status != "error" || captured.Attributes["trace_id"] != trace.TraceID {
+		t.Errorf("trace = %#v", trace)
+	}
+}
@@ -0,0 +1,58 @@
+package logflux
+
+import (
+	"os"
+	"os/signal"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/config"
+)
+
+// ReloadConfigFile re-reads a config file (plus LOGFLUX_* env vars) and
+// applies its runtime settings to the default hub. See Hub.ReloadConfigFile.
+func ReloadConfigFile(path string, overrides Options) error {
+	return defaultHub.ReloadConfigFile(path, overrides)
+}
+
+// ReloadOnSIGHUP reloads the default hub from path each time the process
+// receives SIGHUP. See Hub.ReloadOnSIGHUP.
+func ReloadOnSIGHUP(path string, overrides Options) (stop func()) {
+	return defaultHub.ReloadOnSIGHUP(path, overrides)
+}
+
+// ReloadConfigFile re-reads a config file with the same precedence as
+// InitFromConfigFile (file < env < overrides) and applies the settings that
+// Reconfigure supports. Connection settings such as the API key or endpoints
+// require Init. An invalid file leaves the current settings untouched.
+func (h *Hub) ReloadConfigFile(path string, overrides Options) error {
+	cfg, err := config.LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	return h.Reconfigure(mergeOptions(OptionsFromConfig(cfg), overrides))
+}
+
+// reloadOnSignal calls ReloadConfigFile each time one of sigs arrives,
+// until stop is called.
+func (h *Hub) reloadOnSignal(path string, overrides Options, sigs ...os.Signal) (stop func()) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, sigs...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-sig:
+				if err := h.ReloadConfigFile(path, overrides); err != nil {
+					h.logger().Error("config reload failed", "path", path, "error", err)
+				} else {
+					h.logger().Info("config reloaded", "path", path)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package logflux
+
+// ReloadOnSIGHUP does nothing on platforms without SIGHUP; call
+// ReloadConfigFile directly instead. The returned stop is a no-op.
+func (h *Hub) ReloadOnSIGHUP(path string, overrides Options) (stop func()) {
+	h.logger().Warn("config reload on SIGHUP is not supported on this platform", "path", path)
+	return func() {}
+}
@@ -0,0 +1,125 @@
+package logflux
+
+import (
+	"testing"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 5s")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHub_ReinitDrainsAndClosesPrevious(t *testing.T) {
+	srvA, srvB := newTestIngestor(t), newTestIngestor(t)
+	hub, err := NewHub(testOptions(srvA))
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+	first := hub.Client()
+	_ = hub.Info("queued on first client")
+
+	if err := hub.init(testOptions(srvB)); err != nil {
+		t.Fatalf("re-init: %v", err)
+	}
+	if hub.Client() == first {
+		t.Fatal("client was not replaced")
+	}
+	if got := first.GetStats(); got.EntriesSent != 1 {
+		t.Errorf("previous client sent %d entries, want 1 (drained before close)", got.EntriesSent)
+	}
+	_ = first.SendLog("after close")
+	if got := first.GetStats().EntriesQueued; got != 1 {
+		t.Errorf("previous client queued %d entries, want 1 (closed after re-init)", got)
+	}
+}
+
+func TestHub_FailedReinitKeepsPrevious(t *testing.T) {
+	srv := newTestIngestor(t)
+	hub, err := NewHub(testOptions(srv))
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+	first := hub.Client()
+
+	bad := testOptions(srv)
+	bad.APIKey = "invalid"
+	bad.Source = "replaced"
+	if err := hub.init(bad); err == nil {
+		t.Fatal("expected error for invalid API key")
+	}
+	if hub.Client() != first {
+		t.Fatal("failed re-init replaced the client")
+	}
+	if got := hub.context.Source(); got == "replaced" {
+		t.Error("failed re-init changed the payload context")
+	}
+	_ = hub.Info("still works")
+	_ = hub.Flush(2 * time.Second)
+	waitFor(t, func() bool { return srv.ingests.Load() == 1 })
+}
+
+func TestHub_Reconfigure(t *testing.T) {
+	srv := newTestIngestor(t)
+	hub, err := NewHub(testOptions(srv))
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+	c := hub.Client()
+
+	var hooked int
+	err = hub.Reconfigure(Options{
+		MinLevel: models.LogLevelWarning,
+		BeforeSendLog: func(l *payload.Log) *payload.Log {
+			hooked++
+			return l
+		},
+	})
+	if err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	if hub.Client() != c {
+		t.Fatal("Reconfigure replaced the client")
+	}
+
+	_ = hub.Debug("filtered")
+	_ = hub.Info("filtered")
+	_ = hub.Warn("sent")
+	_ = hub.Error("sent")
+	if hooked != 2 {
+		t.Errorf("hook ran %d times, want 2", hooked)
+	}
+	if got := c.GetStats().EntriesQueued; got != 2 {
+		t.Errorf("queued %d entries, want 2", got)
+	}
+
+	// Reconfigure replaces every reconfigurable field: the hook is removed.
+	if err := hub.Reconfigure(Options{}); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	_ = hub.Debug("sent")
+	if hooked != 2 {
+		t.Errorf("removed hook still ran")
+	}
+	if got := c.GetStats().EntriesQueued; got != 3 {
+		t.Errorf("queued %d entries, want 3", got)
+	}
+}
+
+func TestHub_ReconfigureUninitialized(t *testing.T) {
+	if err := (&Hub{context: payload.NewContext()}).Reconfigure(Options{}); err == nil {
+		t.Fatal("expected error for uninitialized hub")
+	}
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package logflux
+
+import "syscall"
+
+// ReloadOnSIGHUP starts a goroutine that calls ReloadConfigFile on every
+// SIGHUP. Reload failures are reported through the diagnostic logger and the
+// previous settings are kept. Call stop to stop listening.
+//
+// Usage:
+//
+//	logflux.InitFromConfigFile("/etc/app/logflux.yaml", opts)
+//	stop := logflux.ReloadOnSIGHUP("/etc/app/logflux.yaml", opts)
+//	defer stop()
+func (h *Hub) ReloadOnSIGHUP(path string, overrides Options) (stop func()) {
+	return h.reloadOnSignal(path, overrides, syscall.SIGHUP)
+}
@@ -0,0 +1,46 @@
+//go:build unix
+
+package logflux
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestHub_ReloadOnSIGHUP(t *testing.T) {
+	srv := newTestIngestor(t)
+	t.Setenv("LOGFLUX_API_KEY", "")
+	path := filepath.Join(t.TempDir(), "logflux.yaml")
+	writeConfig := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("api_key: eu-lf_testkey123\nsample_rate: 1\n")
+
+	hub, err := NewHub(testOptions(srv))
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+	stop := hub.ReloadOnSIGHUP(path, Options{})
+	defer stop()
+
+	writeConfig("api_key: eu-lf_testkey123\nsample_rate: 0.000001\n")
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+	waitFor(t, func() bool { return hub.getSampler().Rate() < 0.5 })
+
+	// An invalid file keeps the current settings.
+	writeConfig("sample_rate: 7\n")
+	if err := hub.ReloadConfigFile(path, Options{}); err == nil {
+		t.Fatal("expected error for invalid config")
+	}
+	if got := hub.getSampler().Rate(); got >= 0.5 {
+		t.Errorf("sample rate changed to %g by invalid reload", got)
+	}
+}
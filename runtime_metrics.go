@@ -0,0 +1,306 @@
+package logflux
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// RuntimeMetricsOptions configures the runtime metrics collector (see
+// Options.RuntimeMetrics).
+type RuntimeMetricsOptions struct {
+	// Interval between collections (default: 30s).
+	Interval time.Duration
+	// Allow limits the metrics sent to these names and dotted prefixes,
+	// e.g. "go.gc" or "process.open_fds". Empty sends all.
+	Allow []string
+}
+
+const defaultRuntimeMetricsInterval = 30 * time.Second
+
+// Runtime metric sources read through runtime/metrics.
+const (
+	rmGoroutines   = "/sched/goroutines:goroutines"
+	rmGoMaxProcs   = "/sched/gomaxprocs:threads"
+	rmGCPauses     = "/sched/pauses/total/gc:seconds"
+	rmSchedLatency = "/sched/latencies:seconds"
+	rmGCCPU        = "/cpu/classes/gc/total:cpu-seconds"
+)
+
+// Linux reports /proc/self/stat times in USER_HZ, which is 100 on all
+// supported architectures.
+const clockTicksPerSecond = 100
+
+// runtimeCollector periodically reads runtime/metrics, runtime.MemStats and
+// /proc/self and sends them as metrics through its hub:
+//
+//	go.goroutines, go.gomaxprocs                         gauges
+//	go.memory.heap.{alloc,inuse,objects}, go.memory.{sys,stack}, go.gc.heap_goal
+//	                                                     gauges (bytes, objects)
+//	go.memory.allocated, go.memory.mallocs               counters
+//	go.gc.count, go.gc.pause.total (ms), go.gc.cpu (s)   counters
+//	go.gc.pause.{p50,p95,p99,max} (ms)                   gauges
+//	go.sched.latency.{p50,p95,p99,max} (ms)              gauges
+//	process.cpu.time (s)                                 counter (Linux)
+//	process.memory.rss (bytes), process.open_fds         gauges (Linux)
+//	process.uptime (s)                                   gauge
+//
+// Counters carry the increase since the previous collection; the first
+// collection reports the total since the process started. Quantiles are
+// computed over the interval and omitted when it had no samples.
+type runtimeCollector struct {
+	hub      *Hub
+	interval time.Duration
+	allow    []string
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	samples []metrics.Sample
+	prev    map[string]float64  // last cumulative value per counter
+	hists   map[string][]uint64 // last cumulative bucket counts per histogram
+}
+
+func newRuntimeCollector(h *Hub, opts RuntimeMetricsOptions) *runtimeCollector {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultRuntimeMetricsInterval
+	}
+	c := &runtimeCollector{
+		hub:      h,
+		interval: interval,
+		allow:    append([]string(nil), opts.Allow...),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		prev:     make(map[string]float64),
+		hists:    make(map[string][]uint64),
+	}
+	supported := make(map[string]bool)
+	for _, d := range metrics.All() {
+		supported[d.Name] = true
+	}
+	for _, name := range []string{rmGoroutines, rmGoMaxProcs, rmGCPauses, rmSchedLatency, rmGCCPU} {
+		if supported[name] {
+			c.samples = append(c.samples, metrics.Sample{Name: name})
+		}
+	}
+	return c
+}
+
+// start runs the collection loop until close.
+func (c *runtimeCollector) start() {
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				for _, m := range c.collect() {
+					if err := c.hub.sendMetric(m); err != nil {
+						c.hub.logger().Warn("sending runtime metric failed", "metric", m.Name, "error", err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// close stops the loop and waits for it to exit. It is safe on nil.
+func (c *runtimeCollector) close() {
+	if c == nil {
+		return
+	}
+	c.stopOnce.Do(func() { close(c.stop) })
+	<-c.done
+}
+
+// allowed reports whether name passes the allowlist.
+func (c *runtimeCollector) allowed(name string) bool {
+	if len(c.allow) == 0 {
+		return true
+	}
+	for _, a := range c.allow {
+		if name == a || strings.HasPrefix(name, a+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// collect reads the current values. It is called from a single goroutine.
+func (c *runtimeCollector) collect() []*payload.Metric {
+	var out []*payload.Metric
+	gauge := func(name string, v float64, unit string) {
+		if c.allowed(name) {
+			out = append(out, payload.NewGauge("", name, v, unit))
+		}
+	}
+	counter := func(name string, total float64, unit string) {
+		delta := total - c.prev[name]
+		c.prev[name] = total
+		if delta < 0 { // reset, e.g. a process counter wrapped
+			delta = total
+		}
+		if c.allowed(name) {
+			m := payload.NewCounter("", name, delta)
+			m.Unit = unit
+			out = append(out, m)
+		}
+	}
+	quantiles := func(prefix string, h *metrics.Float64Histogram) {
+		counts := c.histDelta(prefix, h.Counts)
+		for _, q := range []struct {
+			suffix string
+			q      float64
+		}{{"p50", 0.5}, {"p95", 0.95}, {"p99", 0.99}, {"max", 1}} {
+			if v, ok := histQuantile(counts, h.Buckets, q.q); ok {
+				gauge(prefix+"."+q.suffix, v*1000, "ms")
+			}
+		}
+	}
+
+	metrics.Read(c.samples)
+	for _, s := range c.samples {
+		switch s.Name {
+		case rmGoroutines:
+			gauge("go.goroutines", float64(s.Value.Uint64()), "")
+		case rmGoMaxProcs:
+			gauge("go.gomaxprocs", float64(s.Value.Uint64()), "")
+		case rmGCCPU:
+			counter("go.gc.cpu", s.Value.Float64(), "s")
+		case rmGCPauses:
+			quantiles("go.gc.pause", s.Value.Float64Histogram())
+		case rmSchedLatency:
+			quantiles("go.sched.latency", s.Value.Float64Histogram())
+		}
+	}
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	gauge("go.memory.heap.alloc", float64(ms.HeapAlloc), "bytes")
+	gauge("go.memory.heap.inuse", float64(ms.HeapInuse), "bytes")
+	gauge("go.memory.heap.objects", float64(ms.HeapObjects), "")
+	gauge("go.memory.sys", float64(ms.Sys), "bytes")
+	gauge("go.memory.stack", float64(ms.StackInuse), "bytes")
+	gauge("go.gc.heap_goal", float64(ms.NextGC), "bytes")
+	counter("go.memory.allocated", float64(ms.TotalAlloc), "bytes")
+	counter("go.memory.mallocs", float64(ms.Mallocs), "")
+	counter("go.gc.count", float64(ms.NumGC), "")
+	counter("go.gc.pause.total", float64(ms.PauseTotalNs)/1e6, "ms")
+
+	if cpu, rss, ok := readProcStat(); ok {
+		counter("process.cpu.time", cpu, "s")
+		gauge("process.memory.rss", rss, "bytes")
+	}
+	if n, err := openFDs(); err == nil {
+		gauge("process.open_fds", float64(n), "")
+	}
+	gauge("process.uptime", time.Since(processStart).Seconds(), "s")
+	return out
+}
+
+// openFDs counts the process's open file descriptors on Linux, excluding
+// the one used to list them.
+func openFDs() (int, error) {
+	d, err := os.Open("/proc/self/fd")
+	if err != nil {
+		return 0, err
+	}
+	defer d.Close()
+	names, err := d.Readdirnames(-1)
+	if err != nil {
+		return 0, err
+	}
+	self := strconv.Itoa(int(d.Fd()))
+	n := 0
+	for _, name := range names {
+		if name != self {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// histDelta returns the bucket counts added since the previous call for
+// the histogram named key.
+func (c *runtimeCollector) histDelta(key string, counts []uint64) []uint64 {
+	prev := c.hists[key]
+	delta := make([]uint64, len(counts))
+	for i, n := range counts {
+		if i < len(prev) && n >= prev[i] {
+			delta[i] = n - prev[i]
+		} else {
+			delta[i] = n
+		}
+	}
+	c.hists[key] = append(prev[:0], counts...)
+	return delta
+}
+
+// histQuantile estimates quantile q of a runtime/metrics histogram from its
+// bucket counts, reporting the upper bound of the bucket it falls in (the
+// lower bound for the unbounded last bucket). It reports false if counts
+// are all zero.
+func histQuantile(counts []uint64, buckets []float64, q float64) (float64, bool) {
+	var total uint64
+	for _, n := range counts {
+		total += n
+	}
+	if total == 0 {
+		return 0, false
+	}
+	rank := uint64(math.Ceil(q * float64(total)))
+	if rank == 0 {
+		rank = 1
+	}
+	var seen uint64
+	for i, n := range counts {
+		seen += n
+		if seen >= rank {
+			if hi := buckets[i+1]; !math.IsInf(hi, 1) {
+				return hi, true
+			}
+			return buckets[i], true
+		}
+	}
+	return buckets[len(buckets)-1], true
+}
+
+// readProcStat returns the process CPU time in seconds and resident set
+// size in bytes from /proc/self/stat. It reports false where /proc is not
+// available.
+func readProcStat() (cpu, rss float64, ok bool) {
+	data, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return 0, 0, false
+	}
+	// The command name in parentheses may contain spaces; fields after it
+	// start with the state (field 3). utime and stime are fields 14 and 15,
+	// rss (in pages) is field 24.
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return 0, 0, false
+	}
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 22 {
+		return 0, 0, false
+	}
+	utime, err1 := strconv.ParseFloat(fields[11], 64)
+	stime, err2 := strconv.ParseFloat(fields[12], 64)
+	pages, err3 := strconv.ParseFloat(fields[21], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, 0, false
+	}
+	return (utime + stime) / clockTicksPerSecond, pages * float64(os.Getpagesize()), true
+}
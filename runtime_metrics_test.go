@@ -0,0 +1,120 @@
+package logflux
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+func TestRuntimeCollector_Collect(t *testing.T) {
+	c := newRuntimeCollector(&Hub{}, RuntimeMetricsOptions{})
+	runtime.GC()
+	first := make(map[string]*payload.Metric)
+	for _, m := range c.collect() {
+		first[m.Name] = m
+	}
+	for _, name := range []string{"go.goroutines", "go.memory.heap.alloc", "go.gc.count", "go.gc.pause.p99", "process.uptime"} {
+		if first[name] == nil {
+			t.Errorf("missing %s", name)
+		}
+	}
+	if m := first["go.gc.count"]; m != nil && (m.Kind != "counter" || m.Value < 1) {
+		t.Errorf("go.gc.count = %+v", m)
+	}
+	if m := first["go.memory.heap.alloc"]; m != nil && (m.Kind != "gauge" || m.Unit != "bytes") {
+		t.Errorf("go.memory.heap.alloc = %+v", m)
+	}
+	if runtime.GOOS == "linux" && first["process.open_fds"] == nil {
+		t.Error("missing process.open_fds on linux")
+	}
+
+}
+
+func TestRuntimeCollector_Deltas(t *testing.T) {
+	c := newRuntimeCollector(&Hub{}, RuntimeMetricsOptions{})
+	if d := c.histDelta("h", []uint64{1, 2, 3}); d[0] != 1 || d[2] != 3 {
+		t.Errorf("first delta = %v", d)
+	}
+	if d := c.histDelta("h", []uint64{1, 4, 3}); d[0] != 0 || d[1] != 2 || d[2] != 0 {
+		t.Errorf("second delta = %v", d)
+	}
+}
+
+func TestRuntimeCollector_Allow(t *testing.T) {
+	c := newRuntimeCollector(&Hub{}, RuntimeMetricsOptions{Allow: []string{"go.gc", "process.uptime"}})
+	for _, m := range c.collect() {
+		if !strings.HasPrefix(m.Name, "go.gc.") && m.Name != "process.uptime" {
+			t.Errorf("%s is not allowed", m.Name)
+		}
+	}
+}
+
+func TestHistQuantile(t *testing.T) {
+	buckets := []float64{0, 1, 2, 4, math.Inf(1)}
+	counts := []uint64{5, 3, 1, 1}
+	for q, want := range map[float64]float64{0.5: 1, 0.8: 2, 0.9: 4, 1: 4} {
+		if got, _ := histQuantile(counts, buckets, q); got != want {
+			t.Errorf("q%v = %v, want %v", q, got, want)
+		}
+	}
+	if _, ok := histQuantile(make([]uint64, 4), buckets, 0.5); ok {
+		t.Error("empty histogram should report no quantile")
+	}
+}
+
+func TestHub_RuntimeMetrics(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.RuntimeMetrics = &RuntimeMetricsOptions{Interval: 10 * time.Millisecond, Allow: []string{"go.goroutines"}}
+	got := make(chan *payload.Metric, 16)
+	opts.BeforeSendMetric = func(m *payload.Metric) *payload.Metric {
+		select {
+		case got <- m:
+		default:
+		}
+		return m
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	if m := <-got; m.Name != "go.goroutines" {
+		t.Errorf("metric = %+v", m)
+	}
+	_ = hub.Close()
+	if hub.runtime != nil {
+		t.Error("Close should stop the collector")
+	}
+}
+
+func TestOpenFDs_ExcludesListingHandle(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reads /proc/self/fd")
+	}
+	before, err := openFDs()
+	if err != nil {
+		t.Fatalf("openFDs: %v", err)
+	}
+	// os.ReadDir counts its own handle, so it sees one more.
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before != len(entries)-1 {
+		t.Errorf("openFDs = %d, want %d", before, len(entries)-1)
+	}
+
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if after, _ := openFDs(); after != before+1 {
+		t.Errorf("openFDs = %d after opening a file, want %d", after, before+1)
+	}
+}
@@ -3,13 +3,14 @@ package logflux
 import (
 	"sync"
 
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
 	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
 )
 
 // Scope provides per-request context isolation. Attributes and breadcrumbs
 // set on a scope are merged into every entry sent through it, without
-// affecting other scopes or the global state.
+// affecting other scopes or the hub's state.
 //
 // Usage:
 //
@@ -21,6 +22,7 @@ import (
 //	    scope.CaptureError(err) // includes scope attrs + breadcrumbs
 //	})
 type Scope struct {
+	hub         *Hub
 	mu          sync.RWMutex
 	attributes  Fields
 	breadcrumbs *payload.BreadcrumbRing
@@ -29,8 +31,9 @@ type Scope struct {
 }
 
 // newScope creates a scope with its own breadcrumb buffer.
-func newScope() *Scope {
+func newScope(hub *Hub) *Scope {
 	return &Scope{
+		hub:         hub,
 		attributes:  make(Fields),
 		breadcrumbs: payload.NewBreadcrumbRing(100),
 	}
@@ -39,8 +42,12 @@ func newScope() *Scope {
 // WithScope runs fn with an isolated scope. Scope attributes and breadcrumbs
 // are merged into every entry sent through the scope.
 func WithScope(fn func(scope *Scope)) {
-	scope := newScope()
-	fn(scope)
+	defaultHub.WithScope(fn)
+}
+
+// WithScope runs fn with an isolated scope that sends through this hub.
+func (h *Hub) WithScope(fn func(scope *Scope)) {
+	fn(newScope(h))
 }
 
 // --- Attribute setters ---
@@ -109,12 +116,15 @@ func (s *Scope) Critical(message string) error  { return s.Log(models.LogLevelCr
 
 // Log sends a log entry with scope attributes merged in.
 func (s *Scope) Log(level int, message string) error {
-	c := getClient()
+	if !s.hub.enabled("", level) {
+		return nil
+	}
+	c := s.hub.Client()
 	if c == nil {
 		return nil
 	}
 	p := payload.NewLog("", message, level)
-	payload.ApplyContext(p)
+	s.hub.context.Apply(p)
 	s.applyScope(p)
 
 	if level <= models.LogLevelInfo {
@@ -125,6 +135,7 @@ func (s *Scope) Log(level int, message string) error {
 		})
 	}
 
+	s.hub.scrub(p)
 	data, err := payload.Marshal(p)
 	if err != nil {
 		return err
@@ -134,15 +145,25 @@ func (s *Scope) Log(level int, message string) error {
 
 // CaptureError captures an error with scope context + breadcrumbs.
 func (s *Scope) CaptureError(err error) error {
-	c := getClient()
+	c := s.hub.Client()
 	if c == nil || err == nil {
 		return nil
 	}
-	p := payload.NewErrorPayload("", err)
-	payload.ApplyContext(p)
+	p := s.hub.stackOptions().NewErrorPayload("", err)
+	s.hub.context.Apply(p)
+	return s.sendError(c, p)
+}
+
+// sendError merges scope attributes and breadcrumbs into p and sends it
+// unless the error throttle holds it back.
+func (s *Scope) sendError(c *client.ResilientClient, p *payload.ErrorPayload) error {
 	s.applyScope(p)
 	p.WithBreadcrumbs(s.breadcrumbs)
+	if !s.hub.allowError(p) {
+		return nil
+	}
 
+	s.hub.scrub(p)
 	data, marshalErr := payload.Marshal(p)
 	if marshalErr != nil {
 		return marshalErr
@@ -152,17 +173,17 @@ func (s *Scope) CaptureError(err error) error {
 
 // Event sends an event with scope attributes.
 func (s *Scope) Event(event string, attrs Fields) error {
-	c := getClient()
+	c := s.hub.Client()
 	if c == nil {
 		return nil
 	}
 	p := payload.NewEvent("", event)
-	payload.ApplyContext(p)
+	s.hub.context.Apply(p)
 	s.applyScope(p)
 	if attrs != nil {
 		for k, v := range attrs {
 			if p.Attributes == nil {
-				p.Attributes = make(Fields)
+				p.Attributes = make(payload.Attributes)
 			}
 			p.Attributes[k] = v
 		}
@@ -174,6 +195,7 @@ func (s *Scope) Event(event string, attrs Fields) error {
 		Data:     attrs,
 	})
 
+	s.hub.scrub(p)
 	data, err := payload.Marshal(p)
 	if err != nil {
 		return err
@@ -183,8 +205,8 @@ func (s *Scope) Event(event string, attrs Fields) error {
 
 // applyScope merges scope attributes into a payload's attributes.
 func (s *Scope) applyScope(p interface {
-	SetAttributes(Fields)
-	GetAttributes() Fields
+	SetAttrs(payload.Attributes)
+	GetAttrs() payload.Attributes
 }) {
 	s.mu.RLock()
 	attrsCopy := make(Fields, len(s.attributes))
@@ -193,9 +215,9 @@ func (s *Scope) applyScope(p interface {
 	}
 	s.mu.RUnlock()
 
-	existing := p.GetAttributes()
+	existing := p.GetAttrs()
 	if existing == nil {
-		existing = make(Fields, len(attrsCopy))
+		existing = make(payload.Attributes, len(attrsCopy))
 	}
 	// Scope attributes are defaults — don't overwrite explicit ones
 	for k, v := range attrsCopy {
@@ -203,6 +225,6 @@ func (s *Scope) applyScope(p interface {
 			existing[k] = v
 		}
 	}
-	p.SetAttributes(existing)
+	p.SetAttrs(existing)
 }
 
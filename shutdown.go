@@ -0,0 +1,115 @@
+package logflux
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+)
+
+// defaultShutdownTimeout bounds the drain after a shutdown signal.
+const defaultShutdownTimeout = 5 * time.Second
+
+// SignalOptions configures HandleSignals.
+type SignalOptions struct {
+	// Signals to trap (default: SIGINT and SIGTERM).
+	Signals []os.Signal
+	// Timeout bounds how long queued entries are drained (default: 5s).
+	Timeout time.Duration
+	// Next is called with the signal once the queue is drained, e.g. to
+	// shut down HTTP servers; the hub is closed when it returns and the
+	// process is left running. When nil, the hub is closed and the process
+	// exits with status 128+signal through the function set with
+	// SetExitFunc.
+	Next func(sig os.Signal)
+}
+
+// processStart approximates the process start time for the uptime reported
+// on shutdown.
+var processStart = time.Now()
+
+// notifySignals and stopSignals are vars for testing.
+var (
+	notifySignals = signal.Notify
+	stopSignals   = signal.Stop
+)
+
+// HandleSignals traps SIGINT and SIGTERM (or opts.Signals) until ctx is
+// done. On the first signal it records a "shutdown" event with the signal
+// and process uptime, drains queued entries within opts.Timeout and then
+// calls opts.Next or exits. The signals are released as soon as one
+// arrives, so a second one terminates the process immediately.
+//
+// Usage:
+//
+//	logflux.HandleSignals(ctx, logflux.SignalOptions{})
+func HandleSignals(ctx context.Context, opts SignalOptions) {
+	defaultHub.HandleSignals(ctx, opts)
+}
+
+// HandleSignals is like the package-level HandleSignals but records the
+// event on and drains this hub.
+func (h *Hub) HandleSignals(ctx context.Context, opts SignalOptions) {
+	sigs := opts.Signals
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ch := make(chan os.Signal, 1)
+	notifySignals(ch, sigs...)
+	go func() {
+		select {
+		case <-ctx.Done():
+			stopSignals(ch)
+		case sig := <-ch:
+			stopSignals(ch)
+			h.shutdown(sig, opts)
+		}
+	}()
+}
+
+// shutdown records the shutdown event, drains the hub and hands over to
+// opts.Next or exits.
+func (h *Hub) shutdown(sig os.Signal, opts SignalOptions) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	_ = h.EventAttrs("shutdown", NewAttributes(
+		"signal", sig.String(),
+		"uptime_ms", time.Since(processStart),
+	))
+	if err := h.Flush(timeout); err != nil {
+		h.logger().Warn("drain on shutdown incomplete", "error", err)
+	}
+
+	if opts.Next != nil {
+		opts.Next(sig)
+		_ = h.Close()
+		return
+	}
+	_ = h.Close()
+	client.Exit(signalExitCode(sig))
+}
+
+// ShutdownFunc returns a function for http.Server.RegisterOnShutdown that
+// sends queued entries, waiting up to timeout, when the server starts
+// shutting down:
+//
+//	srv.RegisterOnShutdown(logflux.ShutdownFunc(5 * time.Second))
+//
+// The hub stays open for entries logged while requests finish.
+func ShutdownFunc(timeout time.Duration) func() {
+	return defaultHub.ShutdownFunc(timeout)
+}
+
+// ShutdownFunc is like the package-level ShutdownFunc but flushes this hub.
+func (h *Hub) ShutdownFunc(timeout time.Duration) func() {
+	return func() {
+		if err := h.Flush(timeout); err != nil {
+			h.logger().Warn("flush on server shutdown incomplete", "error", err)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !plan9
+
+package logflux
+
+import (
+	"os"
+	"syscall"
+)
+
+// signalExitCode is the conventional exit status for a process ended by
+// sig: 128 plus the signal number, or 1 if sig has no number.
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
@@ -0,0 +1,9 @@
+//go:build plan9
+
+package logflux
+
+import "os"
+
+// signalExitCode is 1: Plan 9 notes are strings, with no number to add
+// to 128.
+func signalExitCode(os.Signal) int { return 1 }
@@ -0,0 +1,93 @@
+package logflux
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// fakeSignals replaces signal registration and returns a function that
+// delivers a signal to the registered channel.
+func fakeSignals(t *testing.T) (send func(os.Signal), stopped func() bool) {
+	t.Helper()
+	var ch chan<- os.Signal
+	var stop atomic.Bool
+	registered := make(chan struct{})
+	notifySignals = func(c chan<- os.Signal, _ ...os.Signal) { ch = c; close(registered) }
+	stopSignals = func(chan<- os.Signal) { stop.Store(true) }
+	t.Cleanup(func() { notifySignals, stopSignals = signal.Notify, signal.Stop })
+	return func(s os.Signal) { <-registered; ch <- s }, stop.Load
+}
+
+func TestHub_HandleSignals(t *testing.T) {
+	send, _ := fakeSignals(t)
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	var event *payload.Event
+	opts.BeforeSendEvent = func(e *payload.Event) *payload.Event {
+		event = e
+		return e
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+
+	exited := make(chan int, 1)
+	prev := SetExitFunc(func(code int) { exited <- code })
+	defer SetExitFunc(prev)
+
+	hub.HandleSignals(context.Background(), SignalOptions{})
+	send(syscall.SIGTERM)
+
+	if code := <-exited; code != signalExitCode(syscall.SIGTERM) {
+		t.Errorf("exit code = %d", code)
+	}
+	if event == nil || event.EventName != "shutdown" || event.Attributes["signal"] != syscall.SIGTERM.String() {
+		t.Fatalf("event = %#v", event)
+	}
+	if _, ok := event.Attributes["uptime_ms"].(float64); !ok {
+		t.Errorf("uptime_ms = %#v", event.Attributes["uptime_ms"])
+	}
+	if srv.ingests.Load() == 0 {
+		t.Error("queue was not drained")
+	}
+}
+
+func TestHub_HandleSignalsNext(t *testing.T) {
+	send, _ := fakeSignals(t)
+	srv := newTestIngestor(t)
+	hub, err := NewHub(testOptions(srv))
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+
+	prev := SetExitFunc(func(code int) { t.Errorf("unexpected exit(%d)", code) })
+	defer SetExitFunc(prev)
+
+	got := make(chan os.Signal, 1)
+	hub.HandleSignals(context.Background(), SignalOptions{Next: func(sig os.Signal) { got <- sig }})
+	send(os.Interrupt)
+	if sig := <-got; sig != os.Interrupt {
+		t.Errorf("Next got %v", sig)
+	}
+}
+
+func TestHub_HandleSignalsContextDone(t *testing.T) {
+	_, stopped := fakeSignals(t)
+	hub, err := NewHub(testOptions(newTestIngestor(t)))
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hub.HandleSignals(ctx, SignalOptions{})
+	cancel()
+	waitFor(t, stopped)
+}
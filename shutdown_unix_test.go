@@ -0,0 +1,18 @@
+//go:build unix
+
+package logflux
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestSignalExitCode(t *testing.T) {
+	if code := signalExitCode(syscall.SIGTERM); code != 128+int(syscall.SIGTERM) {
+		t.Errorf("SIGTERM exit code = %d", code)
+	}
+	if code := signalExitCode(os.Interrupt); code != 130 {
+		t.Errorf("SIGINT exit code = %d, want 130", code)
+	}
+}
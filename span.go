@@ -22,6 +22,7 @@ import (
 //	    span.SetStatus("error")
 //	}
 type Span struct {
+	hub          *Hub
 	traceID      string
 	spanID       string
 	parentSpanID string
@@ -29,26 +30,31 @@ type Span struct {
 	name         string
 	startTime    time.Time
 	status       string
-	attributes   Fields
+	attributes   payload.Attributes
 	mu           sync.Mutex
 	ended        bool
 }
 
 // StartSpan creates and starts a new root span (generates new trace ID).
 func StartSpan(operation, name string) *Span {
-	return &Span{
-		traceID:   generateTraceID(),
-		spanID:    generateSpanID(),
-		operation: operation,
-		name:      name,
-		startTime: time.Now(),
-		status:    "ok",
-	}
+	return defaultHub.StartSpan(operation, name)
 }
 
 // StartSpanWithTraceID creates a root span with a specific trace ID.
 func StartSpanWithTraceID(traceID, operation, name string) *Span {
+	return defaultHub.StartSpanWithTraceID(traceID, operation, name)
+}
+
+// StartSpan creates a root span that is sent through this hub.
+func (h *Hub) StartSpan(operation, name string) *Span {
+	return h.StartSpanWithTraceID(generateTraceID(), operation, name)
+}
+
+// StartSpanWithTraceID creates a root span with a specific trace ID that is
+// sent through this hub.
+func (h *Hub) StartSpanWithTraceID(traceID, operation, name string) *Span {
 	return &Span{
+		hub:       h,
 		traceID:   traceID,
 		spanID:    generateSpanID(),
 		operation: operation,
@@ -61,6 +67,7 @@ func StartSpanWithTraceID(traceID, operation, name string) *Span {
 // StartChild creates a child span under this span (same trace ID).
 func (s *Span) StartChild(operation, name string) *Span {
 	return &Span{
+		hub:          s.hub,
 		traceID:      s.traceID,
 		spanID:       generateSpanID(),
 		parentSpanID: s.spanID,
@@ -83,7 +90,11 @@ func (s *Span) End() error {
 	attrs := s.attributes
 	s.mu.Unlock()
 
-	c := getClient()
+	hub := s.hub
+	if hub == nil {
+		hub = defaultHub
+	}
+	c := hub.Client()
 	if c == nil {
 		return nil
 	}
@@ -91,18 +102,18 @@ func (s *Span) End() error {
 	p := payload.NewTrace("", s.traceID, s.spanID, s.operation, s.name, s.startTime, endTime)
 	p.ParentSpanID = s.parentSpanID
 	p.Status = s.status
-	payload.ApplyContext(p)
+	hub.context.Apply(p)
 	if attrs != nil {
-		p.SetAttributes(attrs)
+		p.SetAttrs(attrs)
 	}
-	h := getHooks()
-	if h.Trace != nil {
-		p = h.Trace(p)
+	if hook := hub.getHooks().Trace; hook != nil {
+		p = hook(p)
 		if p == nil {
 			return nil
 		}
 	}
 
+	hub.scrub(p)
 	data, err := payload.Marshal(p)
 	if err != nil {
 		return err
@@ -112,21 +123,42 @@ func (s *Span) End() error {
 
 // --- Setters ---
 
-// SetAttribute sets a span attribute.
+// SetAttribute sets a string span attribute.
 func (s *Span) SetAttribute(key, value string) {
+	s.SetAttributeValue(key, value)
+}
+
+// SetIntAttribute sets an integer span attribute, e.g. http.status_code.
+func (s *Span) SetIntAttribute(key string, value int64) {
+	s.SetAttributeValue(key, value)
+}
+
+// SetFloatAttribute sets a floating-point span attribute.
+func (s *Span) SetFloatAttribute(key string, value float64) {
+	s.SetAttributeValue(key, value)
+}
+
+// SetBoolAttribute sets a boolean span attribute.
+func (s *Span) SetBoolAttribute(key string, value bool) {
+	s.SetAttributeValue(key, value)
+}
+
+// SetAttributeValue sets a span attribute of any type, converted as
+// described in payload.AttributeValue. Maps are flattened to dotted keys.
+func (s *Span) SetAttributeValue(key string, value any) {
 	s.mu.Lock()
 	if s.attributes == nil {
-		s.attributes = make(Fields)
+		s.attributes = make(payload.Attributes)
 	}
-	s.attributes[key] = value
+	s.attributes.Set(key, value)
 	s.mu.Unlock()
 }
 
-// SetAttributes sets multiple span attributes.
+// SetAttributes sets multiple string span attributes.
 func (s *Span) SetAttributes(attrs Fields) {
 	s.mu.Lock()
 	if s.attributes == nil {
-		s.attributes = make(Fields, len(attrs))
+		s.attributes = make(payload.Attributes, len(attrs))
 	}
 	for k, v := range attrs {
 		s.attributes[k] = v
@@ -134,6 +166,16 @@ func (s *Span) SetAttributes(attrs Fields) {
 	s.mu.Unlock()
 }
 
+// SetAttrs sets multiple typed span attributes.
+func (s *Span) SetAttrs(attrs Attributes) {
+	s.mu.Lock()
+	if s.attributes == nil {
+		s.attributes = make(payload.Attributes, len(attrs))
+	}
+	s.attributes.Merge(attrs)
+	s.mu.Unlock()
+}
+
 // SetStatus sets the span status ("ok" or "error").
 func (s *Span) SetStatus(status string) {
 	s.mu.Lock()
@@ -149,7 +191,7 @@ func (s *Span) SetError(err error) {
 	s.mu.Lock()
 	s.status = "error"
 	if s.attributes == nil {
-		s.attributes = make(Fields)
+		s.attributes = make(payload.Attributes)
 	}
 	s.attributes["error.message"] = err.Error()
 	s.mu.Unlock()
@@ -92,3 +92,27 @@ func TestSpan_Timing(t *testing.T) {
 type errForTest string
 
 func (e errForTest) Error() string { return string(e) }
+
+func TestSpan_TypedAttributes(t *testing.T) {
+	span := StartSpan("http.server", "GET /users")
+	span.SetIntAttribute("http.status_code", 200)
+	span.SetFloatAttribute("db.rows_per_ms", 1.5)
+	span.SetBoolAttribute("cache.hit", true)
+	span.SetAttributeValue("http.request", map[string]any{"size": uint32(512)})
+	span.SetAttrs(NewAttributes("retries", 2))
+
+	span.mu.Lock()
+	defer span.mu.Unlock()
+	want := Attributes{
+		"http.status_code":  int64(200),
+		"db.rows_per_ms":    1.5,
+		"cache.hit":         true,
+		"http.request.size": int64(512),
+		"retries":           int64(2),
+	}
+	for k, v := range want {
+		if span.attributes[k] != v {
+			t.Errorf("%s = %#v, want %#v", k, span.attributes[k], v)
+		}
+	}
+}
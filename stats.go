@@ -0,0 +1,185 @@
+package logflux
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/client"
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/models"
+)
+
+// SelfTelemetryOptions configures the self-telemetry collector (see
+// Options.SelfTelemetry).
+type SelfTelemetryOptions struct {
+	// Interval between collections (default: 60s).
+	Interval time.Duration
+}
+
+const defaultSelfTelemetryInterval = 60 * time.Second
+
+// StatsHandler returns an http.Handler serving the default hub's SDK health
+// as JSON (see Hub.StatsHandler).
+func StatsHandler() http.Handler { return defaultHub.StatsHandler() }
+
+// StatsHandler returns an http.Handler serving the hub's SDK health as JSON:
+// queue depth, drops by reason and entry type, bytes and compression, send
+// latency, rate-limit pause, blocked quotas, handshake state, last error and
+// endpoint. It answers 503 while the hub is not initialized or its last
+// handshake failed, so it can back a readiness probe:
+//
+//	mux.Handle("/debug/logflux", logflux.StatsHandler())
+func (h *Hub) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		initialized := h.Client() != nil
+		resp := newStatsResponse(initialized, h.Stats())
+		status := http.StatusOK
+		if !initialized || !resp.HandshakeOK {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(resp)
+	})
+}
+
+// statsResponse is the JSON form of client.ClientStats.
+type statsResponse struct {
+	Initialized      bool       `json:"initialized"`
+	HandshakeOK      bool       `json:"handshake_ok"`
+	Endpoint         string     `json:"endpoint,omitempty"`
+	RateLimitedUntil *time.Time `json:"rate_limited_until,omitempty"`
+	QuotaBlocked     []string   `json:"quota_blocked,omitempty"`
+	LastSendError    string     `json:"last_send_error,omitempty"`
+	LastSendTime     *time.Time `json:"last_send_time,omitempty"`
+
+	EntriesSent    int64            `json:"entries_sent"`
+	EntriesDropped int64            `json:"entries_dropped"`
+	EntriesQueued  int64            `json:"entries_queued"`
+	QueueSize      int64            `json:"queue_size"`
+	QueueCapacity  int64            `json:"queue_capacity"`
+	DropReasons    map[string]int64 `json:"drop_reasons"`
+	SentByType     map[string]int64 `json:"sent_by_type"`
+	DroppedByType  map[string]int64 `json:"dropped_by_type"`
+
+	BytesUncompressed int64              `json:"bytes_uncompressed"`
+	BytesSent         int64              `json:"bytes_sent"`
+	CompressionRatio  float64            `json:"compression_ratio"`
+	SendLatencyMs     map[string]float64 `json:"send_latency_ms"`
+
+	SelfTelemetrySent    int64 `json:"self_telemetry_sent"`
+	SelfTelemetryDropped int64 `json:"self_telemetry_dropped"`
+}
+
+func newStatsResponse(initialized bool, s client.ClientStats) statsResponse {
+	resp := statsResponse{
+		Initialized:       initialized,
+		HandshakeOK:       s.HandshakeOK,
+		Endpoint:          s.Endpoint,
+		QuotaBlocked:      s.QuotaBlocked,
+		LastSendError:     s.LastSendError,
+		EntriesSent:       s.EntriesSent,
+		EntriesDropped:    s.EntriesDropped,
+		EntriesQueued:     s.EntriesQueued,
+		QueueSize:         s.QueueSize,
+		QueueCapacity:     s.QueueCapacity,
+		DropReasons:       make(map[string]int64, len(s.DropReasons)),
+		SentByType:        s.SentByType,
+		DroppedByType:     s.DroppedByType,
+		BytesUncompressed: s.BytesUncompressed,
+		BytesSent:         s.BytesSent,
+		CompressionRatio:  s.CompressionRatio,
+		SendLatencyMs: map[string]float64{
+			"p50": milliseconds(s.SendLatencyP50),
+			"p95": milliseconds(s.SendLatencyP95),
+			"p99": milliseconds(s.SendLatencyP99),
+		},
+		SelfTelemetrySent:    s.SelfTelemetrySent,
+		SelfTelemetryDropped: s.SelfTelemetryDropped,
+	}
+	for reason, n := range s.DropReasons {
+		resp.DropReasons[string(reason)] = n
+	}
+	if !s.RateLimitedUntil.IsZero() {
+		resp.RateLimitedUntil = &s.RateLimitedUntil
+	}
+	if !s.LastSendTime.IsZero() {
+		resp.LastSendTime = &s.LastSendTime
+	}
+	return resp
+}
+
+func milliseconds(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+
+// statsSource reports the hub client's statistics as logflux.sdk.* metrics
+// for the self-telemetry bridge:
+//
+//	logflux.sdk.entries.sent, .dropped (type)     counters
+//	logflux.sdk.drops (reason)                    counter
+//	logflux.sdk.bytes.sent, .uncompressed         counters (bytes)
+//	logflux.sdk.queue.size, .capacity             gauges
+//	logflux.sdk.compression.ratio                 gauge
+//	logflux.sdk.send.latency.{p50,p95,p99}        gauges (ms)
+//	logflux.sdk.handshake.ok, .ratelimit.paused   gauges (0 or 1)
+//	logflux.sdk.quota.blocked (category)          gauge (0 or 1)
+func statsSource(h *Hub) MetricSource {
+	return MetricSourceFunc(func() ([]MetricSample, error) {
+		if h.Client() == nil {
+			return nil, nil
+		}
+		s := h.Stats()
+		var out []MetricSample
+		add := func(name, kind, unit string, v float64, labels map[string]string) {
+			out = append(out, MetricSample{Name: name, Kind: kind, Unit: unit, Value: v, Labels: labels})
+		}
+		flag := func(b bool) float64 {
+			if b {
+				return 1
+			}
+			return 0
+		}
+
+		for t, n := range s.SentByType {
+			add("logflux.sdk.entries.sent", "counter", "", float64(n), map[string]string{"type": t})
+		}
+		for t, n := range s.DroppedByType {
+			add("logflux.sdk.entries.dropped", "counter", "", float64(n), map[string]string{"type": t})
+		}
+		for reason, n := range s.DropReasons {
+			add("logflux.sdk.drops", "counter", "", float64(n), map[string]string{"reason": string(reason)})
+		}
+		add("logflux.sdk.bytes.sent", "counter", "bytes", float64(s.BytesSent), nil)
+		add("logflux.sdk.bytes.uncompressed", "counter", "bytes", float64(s.BytesUncompressed), nil)
+		add("logflux.sdk.queue.size", "gauge", "", float64(s.QueueSize), nil)
+		add("logflux.sdk.queue.capacity", "gauge", "", float64(s.QueueCapacity), nil)
+		add("logflux.sdk.compression.ratio", "gauge", "", s.CompressionRatio, nil)
+		add("logflux.sdk.send.latency.p50", "gauge", "ms", milliseconds(s.SendLatencyP50), nil)
+		add("logflux.sdk.send.latency.p95", "gauge", "ms", milliseconds(s.SendLatencyP95), nil)
+		add("logflux.sdk.send.latency.p99", "gauge", "ms", milliseconds(s.SendLatencyP99), nil)
+		add("logflux.sdk.handshake.ok", "gauge", "", flag(s.HandshakeOK), nil)
+		add("logflux.sdk.ratelimit.paused", "gauge", "", flag(!s.RateLimitedUntil.IsZero()), nil)
+		blocked := make(map[string]bool, len(s.QuotaBlocked))
+		for _, c := range s.QuotaBlocked {
+			blocked[c] = true
+		}
+		for _, c := range []string{models.CategoryEvents, models.CategoryTraces, models.CategoryAudit} {
+			add("logflux.sdk.quota.blocked", "gauge", "", flag(blocked[c]), map[string]string{"category": c})
+		}
+		return out, nil
+	})
+}
+
+// startSelfTelemetry starts the bridge sending the hub's statistics.
+func startSelfTelemetry(h *Hub, opts SelfTelemetryOptions) *Bridge {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultSelfTelemetryInterval
+	}
+	b := newBridge(h, statsSource(h), BridgeOptions{Interval: interval})
+	b.selfTelemetry = true
+	go b.run()
+	return b
+}
@@ -0,0 +1,99 @@
+package logflux
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+func TestHub_StatsHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&Hub{}).StatsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("uninitialized status = %d", rec.Code)
+	}
+
+	srv := newTestIngestor(t)
+	hub, err := NewHub(testOptions(srv))
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+	_ = hub.Info("hello")
+	_ = hub.Counter("requests", 1, nil)
+	if err := hub.Flush(time.Second); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	hub.StatsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status = %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var body statsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !body.Initialized || !body.HandshakeOK || body.Endpoint != srv.URL || body.EntriesSent != 2 {
+		t.Errorf("stats = %+v", body)
+	}
+	if body.SentByType["log"] != 1 || body.SentByType["metric"] != 1 || body.BytesSent == 0 {
+		t.Errorf("sent by type = %v, bytes %d", body.SentByType, body.BytesSent)
+	}
+	if _, ok := body.SendLatencyMs["p99"]; !ok {
+		t.Errorf("send latency = %v", body.SendLatencyMs)
+	}
+}
+
+func TestHub_SelfTelemetry(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	got := make(chan *payload.Metric, 64)
+	opts.BeforeSendMetric = func(m *payload.Metric) *payload.Metric {
+		select {
+		case got <- m:
+		default:
+		}
+		return m
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+	_ = hub.Info("hello")
+	_ = hub.Flush(time.Second)
+
+	b := newBridge(hub, statsSource(hub), BridgeOptions{})
+	b.selfTelemetry = true
+	if err := b.Collect(); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	seen := make(map[string]*payload.Metric)
+	for len(got) > 0 {
+		m := <-got
+		seen[m.Name] = m
+	}
+	if m := seen["logflux.sdk.entries.sent"]; m == nil || m.Kind != "counter" || m.Value != 1 || m.Attributes["type"] != "log" {
+		t.Errorf("entries.sent = %+v", m)
+	}
+	if m := seen["logflux.sdk.handshake.ok"]; m == nil || m.Value != 1 {
+		t.Errorf("handshake.ok = %+v", m)
+	}
+	if m := seen["logflux.sdk.send.latency.p99"]; m == nil || m.Unit != "ms" {
+		t.Errorf("send.latency.p99 = %+v", m)
+	}
+
+	// The reports are not counted in the statistics they report.
+	if err := hub.Flush(time.Second); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	s := hub.Stats()
+	if s.EntriesSent != 1 || s.SentByType["metric"] != 0 || s.SelfTelemetrySent == 0 {
+		t.Errorf("sent = %d, by type %v, self telemetry %d", s.EntriesSent, s.SentByType, s.SelfTelemetrySent)
+	}
+}
@@ -0,0 +1,164 @@
+package logflux
+
+import (
+	"sync"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+// maxThrottleBuckets bounds the number of fingerprints tracked at once.
+// Idle buckets are evicted when the limit is reached; if every bucket holds
+// suppressed repeats, the least recently used one is sent and evicted.
+const maxThrottleBuckets = 1024
+
+// errorThrottle is a per-fingerprint token bucket for captured errors. Each
+// fingerprint may send limit errors per window; further repeats are held
+// back and sent as one payload carrying their occurrence count when the
+// window ends.
+type errorThrottle struct {
+	limit  int
+	window time.Duration
+	send   func(*payload.ErrorPayload) // delivers a collapsed payload
+
+	mu      sync.Mutex
+	buckets map[string]*errorBucket
+}
+
+type errorBucket struct {
+	tokens  float64
+	last    time.Time
+	pending *payload.ErrorPayload // first suppressed repeat
+	count   int                   // suppressed repeats in this window
+	timer   *time.Timer
+}
+
+// newErrorThrottle returns nil when limit <= 0 (throttling disabled).
+func newErrorThrottle(limit int, window time.Duration, send func(*payload.ErrorPayload)) *errorThrottle {
+	if limit <= 0 {
+		return nil
+	}
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &errorThrottle{
+		limit:   limit,
+		window:  window,
+		send:    send,
+		buckets: make(map[string]*errorBucket),
+	}
+}
+
+// allow reports whether p should be sent now. If not, p is counted towards
+// the collapsed payload sent at the end of the window.
+func (t *errorThrottle) allow(p *payload.ErrorPayload) bool {
+	if t == nil || p.Fingerprint == "" {
+		return true
+	}
+	now := time.Now()
+	var evicted *payload.ErrorPayload
+	t.mu.Lock()
+	b := t.buckets[p.Fingerprint]
+	if b == nil {
+		if len(t.buckets) >= maxThrottleBuckets {
+			evicted = t.evict()
+		}
+		b = &errorBucket{tokens: float64(t.limit), last: now}
+		t.buckets[p.Fingerprint] = b
+	}
+	b.refill(now, t.limit, t.window)
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	} else {
+		b.count++
+		if b.pending == nil {
+			b.pending = p
+			fp := p.Fingerprint
+			b.timer = time.AfterFunc(t.window, func() { t.flushOne(fp) })
+		}
+	}
+	t.mu.Unlock()
+
+	if evicted != nil {
+		t.send(evicted)
+	}
+	return allowed
+}
+
+// refill adds tokens at limit per window, up to limit.
+func (b *errorBucket) refill(now time.Time, limit int, window time.Duration) {
+	b.tokens += now.Sub(b.last).Seconds() * float64(limit) / window.Seconds()
+	if b.tokens > float64(limit) {
+		b.tokens = float64(limit)
+	}
+	b.last = now
+}
+
+// evict makes room for a new bucket: idle buckets are removed, and if none
+// is idle the least recently used bucket is removed and its collapsed
+// payload returned for sending. t.mu must be held.
+func (t *errorThrottle) evict() *payload.ErrorPayload {
+	for fp, b := range t.buckets {
+		if b.pending == nil {
+			delete(t.buckets, fp)
+		}
+	}
+	if len(t.buckets) < maxThrottleBuckets {
+		return nil
+	}
+	var oldest string
+	var oldestAt time.Time
+	for fp, b := range t.buckets {
+		if oldest == "" || b.last.Before(oldestAt) {
+			oldest, oldestAt = fp, b.last
+		}
+	}
+	p := t.take(oldest)
+	delete(t.buckets, oldest)
+	return p
+}
+
+// take removes and returns the collapsed payload for fp, if any.
+// t.mu must be held.
+func (t *errorThrottle) take(fp string) *payload.ErrorPayload {
+	b := t.buckets[fp]
+	if b == nil || b.pending == nil {
+		return nil
+	}
+	p := b.pending
+	p.Occurrences = b.count
+	b.pending, b.count = nil, 0
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return p
+}
+
+func (t *errorThrottle) flushOne(fp string) {
+	t.mu.Lock()
+	p := t.take(fp)
+	t.mu.Unlock()
+	if p != nil {
+		t.send(p)
+	}
+}
+
+// flush sends every collapsed payload immediately.
+func (t *errorThrottle) flush() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	var ps []*payload.ErrorPayload
+	for fp := range t.buckets {
+		if p := t.take(fp); p != nil {
+			ps = append(ps, p)
+		}
+	}
+	t.mu.Unlock()
+	for _, p := range ps {
+		t.send(p)
+	}
+}
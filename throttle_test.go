@@ -0,0 +1,125 @@
+package logflux
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/logflux-io/logflux-go-sdk/v3/pkg/payload"
+)
+
+func TestHub_DuplicateErrorThrottle(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.DuplicateErrorLimit = 2
+	opts.DuplicateErrorWindow = 50 * time.Millisecond
+	var mu sync.Mutex
+	var sent []*payload.ErrorPayload
+	opts.BeforeSendError = func(p *payload.ErrorPayload) *payload.ErrorPayload {
+		mu.Lock()
+		sent = append(sent, p)
+		mu.Unlock()
+		return p
+	}
+	snapshot := func() []*payload.ErrorPayload {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*payload.ErrorPayload(nil), sent...)
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	for i := 0; i < 10; i++ {
+		_ = hub.CaptureError(errors.New("connection reset"))
+	}
+	_ = hub.CaptureErrorWithFingerprint(errors.New("other"), "custom-group", nil)
+
+	got := snapshot()
+	if len(got) != 3 {
+		t.Fatalf("sent %d errors immediately, want 3", len(got))
+	}
+	if got[0].Fingerprint == "" || got[0].Fingerprint != got[1].Fingerprint || got[0].Occurrences != 0 {
+		t.Errorf("repeats = %q/%q occurrences %d", got[0].Fingerprint, got[1].Fingerprint, got[0].Occurrences)
+	}
+	if got[2].Fingerprint != "custom-group" {
+		t.Errorf("per-call fingerprint = %q", got[2].Fingerprint)
+	}
+
+	// The 8 suppressed repeats arrive as one payload when the window ends.
+	waitFor(t, func() bool { return len(snapshot()) == 4 })
+	if c := snapshot()[3]; c.Occurrences != 8 || c.Fingerprint != got[0].Fingerprint {
+		t.Errorf("collapsed payload: occurrences %d fingerprint %q", c.Occurrences, c.Fingerprint)
+	}
+
+	// Flush sends held-back repeats without waiting for the window.
+	for i := 0; i < 5; i++ {
+		_ = hub.CaptureError(errors.New("connection reset"))
+	}
+	before := len(snapshot())
+	if err := hub.Flush(time.Second); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	after := snapshot()
+	if len(after) != before+1 || after[len(after)-1].Occurrences == 0 {
+		t.Errorf("Flush should send one collapsed payload, sent %d more", len(after)-before)
+	}
+}
+
+func TestHub_ReconfigureFlushesThrottle(t *testing.T) {
+	srv := newTestIngestor(t)
+	opts := testOptions(srv)
+	opts.DuplicateErrorLimit = 1
+	opts.DuplicateErrorWindow = time.Hour
+	var mu sync.Mutex
+	var occurrences []int
+	opts.BeforeSendError = func(p *payload.ErrorPayload) *payload.ErrorPayload {
+		mu.Lock()
+		occurrences = append(occurrences, p.Occurrences)
+		mu.Unlock()
+		return p
+	}
+	hub, err := NewHub(opts)
+	if err != nil {
+		t.Fatalf("NewHub: %v", err)
+	}
+	defer hub.Close()
+
+	for i := 0; i < 4; i++ {
+		_ = hub.CaptureError(errors.New("connection reset"))
+	}
+	if err := hub.Reconfigure(opts); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(occurrences) != 2 || occurrences[1] != 3 {
+		t.Errorf("occurrences = %v, want the first error and a collapsed payload of 3", occurrences)
+	}
+}
+
+func TestErrorThrottle_EvictsOldestWhenFull(t *testing.T) {
+	var sent []*payload.ErrorPayload
+	th := newErrorThrottle(1, time.Hour, func(p *payload.ErrorPayload) { sent = append(sent, p) })
+	defer th.flush()
+
+	// Every bucket holds a suppressed repeat, so none is idle.
+	for i := 0; i < maxThrottleBuckets; i++ {
+		fp := fmt.Sprintf("fp-%d", i)
+		th.allow(&payload.ErrorPayload{Fingerprint: fp})
+		th.allow(&payload.ErrorPayload{Fingerprint: fp})
+	}
+	if !th.allow(&payload.ErrorPayload{Fingerprint: "new"}) {
+		t.Fatal("a new fingerprint should be allowed")
+	}
+	if len(th.buckets) > maxThrottleBuckets {
+		t.Errorf("tracking %d buckets, limit %d", len(th.buckets), maxThrottleBuckets)
+	}
+	if len(sent) != 1 || sent[0].Fingerprint != "fp-0" || sent[0].Occurrences != 1 {
+		t.Fatalf("evicted %d payloads: %+v", len(sent), sent)
+	}
+}
@@ -94,11 +94,18 @@ func FormatTraceHeader(tc *TraceContext) string {
 // ContinueFromRequest creates a child span that continues a trace from an incoming request.
 // If no trace header is present, starts a new root span.
 func ContinueFromRequest(req *http.Request, operation, name string) *Span {
+	return defaultHub.ContinueFromRequest(req, operation, name)
+}
+
+// ContinueFromRequest is like the package-level ContinueFromRequest but the
+// span is sent through this hub.
+func (h *Hub) ContinueFromRequest(req *http.Request, operation, name string) *Span {
 	tc := ExtractTraceContext(req)
 	if tc == nil {
-		return StartSpan(operation, name)
+		return h.StartSpan(operation, name)
 	}
 	return &Span{
+		hub:          h,
 		traceID:      tc.TraceID,
 		spanID:       generateSpanID(),
 		parentSpanID: tc.SpanID,
@@ -111,15 +118,46 @@ func ContinueFromRequest(req *http.Request, operation, name string) *Span {
 
 // TracingMiddleware wraps an HTTP handler with automatic span creation.
 // Creates a span for each request with operation "http.server" and
-// propagates the trace context.
+// propagates the trace context. The span is stored in the request context
+// (see SpanFromContext). A panic in the handler is captured as Recover does
+// and answered with 500, or resumed with RepanicOnRecover.
 func TracingMiddleware(next http.Handler) http.Handler {
+	return defaultHub.TracingMiddleware(next)
+}
+
+// TracingMiddleware is like the package-level TracingMiddleware but spans are
+// sent through this hub.
+func (h *Hub) TracingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		span := ContinueFromRequest(r, "http.server", r.Method+" "+r.URL.Path)
+		span := h.ContinueFromRequest(r, "http.server", r.Method+" "+r.URL.Path)
 		span.SetAttribute("http.method", r.Method)
 		span.SetAttribute("http.url", r.URL.String())
 
 		// Wrap response writer to capture status code
 		sw := &statusWriter{ResponseWriter: w, status: 200}
+		r = r.WithContext(ContextWithSpan(r.Context(), span))
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				// Deliberate abort: not an error, let net/http handle it.
+				span.SetStatus("error")
+				_ = span.End()
+				panic(rec)
+			}
+			repanic := h.handlePanic(r.Context(), rec)
+			span.SetAttribute("http.status_code", "500")
+			_ = span.End()
+			if repanic {
+				panic(rec)
+			}
+			if !sw.wroteHeader {
+				http.Error(sw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
 
 		next.ServeHTTP(sw, r)
 
@@ -133,14 +171,21 @@ func TracingMiddleware(next http.Handler) http.Handler {
 
 type statusWriter struct {
 	http.ResponseWriter
-	status int
+	status      int
+	wroteHeader bool
 }
 
 func (w *statusWriter) WriteHeader(code int) {
 	w.status = code
+	w.wroteHeader = true
 	w.ResponseWriter.WriteHeader(code)
 }
 
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
 // Flush delegates to the underlying ResponseWriter if it implements http.Flusher.
 func (w *statusWriter) Flush() {
 	if f, ok := w.ResponseWriter.(http.Flusher); ok {